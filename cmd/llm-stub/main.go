@@ -0,0 +1,78 @@
+// llm-stub คือ server จำลองที่เข้ากันได้กับ OpenAI Chat Completions สำหรับพัฒนาและทดสอบในเครื่อง
+// ตอบกลับแบบ deterministic จาก facts ที่ได้รับ โดยไม่เรียกบริการภายนอก
+//
+//	go run ./cmd/llm-stub -addr :8090
+//	LLM_PROVIDER=openai LLM_BASE_URL=http://localhost:8090/v1 go run ./cmd/server
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+}
+
+func main() {
+	addr := flag.String("addr", ":8090", "listen address")
+	flag.Parse()
+
+	http.HandleFunc("/v1/chat/completions", handleChatCompletions)
+
+	log.Printf("LLM stub listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, nil); err != nil {
+		log.Fatalf("Failed to start LLM stub: %v", err)
+	}
+}
+
+func handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req chatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var facts map[string]interface{}
+	for _, message := range req.Messages {
+		if message.Role == "user" {
+			if err := json.Unmarshal([]byte(message.Content), &facts); err != nil {
+				http.Error(w, "user message must be a JSON object", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	narration, _ := json.Marshal(map[string]string{
+		"title":   fmt.Sprintf("[stub] %v: %v", facts["kind"], facts["category"]),
+		"content": fmt.Sprintf("[stub] %v", facts),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":     "stub-completion",
+		"object": "chat.completion",
+		"model":  req.Model,
+		"choices": []map[string]interface{}{
+			{
+				"index":         0,
+				"message":       chatMessage{Role: "assistant", Content: string(narration)},
+				"finish_reason": "stop",
+			},
+		},
+	})
+}
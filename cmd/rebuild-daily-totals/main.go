@@ -0,0 +1,52 @@
+// rebuild-daily-totals คำนวณตาราง daily_category_totals ใหม่จากตาราง transactions
+// ใช้หลังแก้ข้อมูลรายการด้วย SQL โดยตรง หรือเมื่อสงสัยว่ายอดสรุปไม่ตรงกับรายการ
+//
+//	go run ./cmd/rebuild-daily-totals                 # ทุกผู้ใช้
+//	go run ./cmd/rebuild-daily-totals -user <user-id> # เฉพาะผู้ใช้คนเดียว
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+
+	"savvy-backend/internal/config"
+	"savvy-backend/internal/infrastructure/database"
+)
+
+func main() {
+	userFlag := flag.String("user", "", "rebuild only this user ID (default: all users)")
+	flag.Parse()
+
+	var userID *uuid.UUID
+	if *userFlag != "" {
+		id, err := uuid.Parse(*userFlag)
+		if err != nil {
+			log.Fatalf("Invalid user ID: %v", err)
+		}
+		userID = &id
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found: %v", err)
+	}
+
+	cfg := config.Load()
+	db, err := database.NewConnection(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Now()
+	rows, err := database.NewAnalyticsRepository(db).RebuildDailyCategoryTotals(context.Background(), userID)
+	if err != nil {
+		log.Fatalf("Failed to rebuild daily category totals: %v", err)
+	}
+
+	log.Printf("Rebuilt %d daily category totals in %s", rows, time.Since(start).Round(time.Millisecond))
+}
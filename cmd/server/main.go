@@ -7,7 +7,10 @@ import (
 
 	"savvy-backend/internal/config"
 	"savvy-backend/internal/delivery/http"
+	"savvy-backend/internal/domain/service"
+	"savvy-backend/internal/infrastructure/cache"
 	"savvy-backend/internal/infrastructure/database"
+	"savvy-backend/internal/infrastructure/llm"
 	"savvy-backend/internal/usecase"
 )
 
@@ -35,19 +38,47 @@ func main() {
 	budgetRepo := database.NewBudgetRepository(db)
 	recurringRepo := database.NewRecurringTransactionRepository(db)
 	insightRepo := database.NewInsightRepository(db)
+	anomalyRepo := database.NewSpendingAnomalyRepository(db)
+	patternRepo := database.NewSpendingPatternRepository(db)
+	feedbackRepo := database.NewInsightFeedbackRepository(db)
+	analyticsRepo := database.NewAnalyticsRepository(db)
+	netWorthRepo := database.NewNetWorthRepository(db)
+
+	// Insight narrator (template by default, LLM provider when configured)
+	narrator := usecase.NewTemplateNarrator()
+	if cfg.LLM.Provider == "openai" {
+		narrator = llm.NewOpenAINarrator(&cfg.LLM, narrator)
+	}
+
+	// Response cache (in-memory LRU by default, Redis-compatible server when configured)
+	var responseCache service.ResponseCache
+	switch cfg.Cache.Provider {
+	case "redis":
+		responseCache = cache.NewRedisCache(&cfg.Cache)
+	case "none":
+		responseCache = cache.NewNoopCache()
+	default:
+		responseCache = cache.NewLRUCache(cfg.Cache.Capacity)
+	}
 
 	// Initialize use cases
 	authUsecase := usecase.NewAuthUsecase(userRepo, cfg.JWT.Secret, cfg.JWT.Expiry)
-	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo, accountRepo, categoryRepo)
-	accountUsecase := usecase.NewAccountUsecase(accountRepo)
-	categoryUsecase := usecase.NewCategoryUsecase(categoryRepo)
-	dashboardUsecase := usecase.NewDashboardUsecase(transactionRepo, categoryRepo, accountRepo)
-	budgetUsecase := usecase.NewBudgetUsecase(budgetRepo, categoryRepo, insightRepo)
-	recurringUsecase := usecase.NewRecurringTransactionUsecase(recurringRepo, transactionRepo, categoryRepo, accountRepo)
-	aiInsightUsecase := usecase.NewAIInsightUsecase(insightRepo, transactionRepo, categoryRepo, budgetRepo)
+	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo, accountRepo, categoryRepo, anomalyRepo, feedbackRepo, responseCache)
+	accountUsecase := usecase.NewAccountUsecase(accountRepo, responseCache)
+	categoryUsecase := usecase.NewCategoryUsecase(categoryRepo, responseCache)
+	dashboardUsecase := usecase.NewDashboardUsecase(transactionRepo, categoryRepo, accountRepo, budgetRepo, analyticsRepo)
+	budgetUsecase := usecase.NewBudgetUsecase(budgetRepo, categoryRepo, insightRepo, recurringRepo, userRepo, narrator, responseCache)
+	recurringUsecase := usecase.NewRecurringTransactionUsecase(recurringRepo, transactionRepo, categoryRepo, accountRepo, userRepo, responseCache)
+	forecastUsecase := usecase.NewForecastUsecase(accountRepo, transactionRepo, recurringRepo, categoryRepo, insightRepo, userRepo)
+	aiInsightUsecase := usecase.NewAIInsightUsecase(insightRepo, transactionRepo, categoryRepo, budgetRepo, recurringRepo, userRepo, patternRepo, feedbackRepo, forecastUsecase, narrator, cfg.Jobs.InsightConcurrency, cfg.Jobs.InsightUserTimeout)
+	insightUsecase := usecase.NewInsightUsecase(insightRepo, feedbackRepo, categoryRepo, userRepo)
+	anomalyUsecase := usecase.NewTransactionAnomalyUsecase(anomalyRepo)
+	userUsecase := usecase.NewUserUsecase(userRepo, responseCache)
+	analyticsUsecase := usecase.NewAnalyticsUsecase(analyticsRepo, userRepo)
+	netWorthUsecase := usecase.NewNetWorthUsecase(accountRepo, netWorthRepo, userRepo, responseCache, cfg.Jobs.SnapshotConcurrency)
 
 	// Setup routes
-	router := http.SetupRoutes(authUsecase, transactionUsecase, accountUsecase, categoryUsecase, dashboardUsecase, budgetUsecase, recurringUsecase, aiInsightUsecase)
+	router := http.SetupRoutes(authUsecase, transactionUsecase, accountUsecase, categoryUsecase, dashboardUsecase, budgetUsecase, recurringUsecase, aiInsightUsecase, forecastUsecase, insightUsecase, anomalyUsecase, userUsecase, analyticsUsecase, netWorthUsecase, responseCache, cfg.Cache.TTL, cfg.System.APIKey)
 
 	// Start server
 	serverAddr := cfg.Server.Host + ":" + cfg.Server.Port
@@ -10,6 +10,10 @@ type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	JWT      JWTConfig
+	Jobs     JobsConfig
+	LLM      LLMConfig
+	Cache    CacheConfig
+	System   SystemConfig
 }
 
 type ServerConfig struct {
@@ -32,6 +36,42 @@ type JWTConfig struct {
 	Expiry time.Duration
 }
 
+// JobsConfig ตั้งค่างาน background ที่ประมวลผลผู้ใช้ทุกคน
+type JobsConfig struct {
+	InsightConcurrency  int
+	InsightUserTimeout  time.Duration
+	SnapshotConcurrency int
+}
+
+// LLMConfig ตั้งค่า provider ที่ใช้สร้างข้อความ insight
+// Provider "template" ใช้ template ในระบบ ส่วน "openai" ใช้ API ที่เข้ากันได้กับ OpenAI Chat Completions
+type LLMConfig struct {
+	Provider string
+	BaseURL  string
+	APIKey   string
+	Model    string
+	Timeout  time.Duration
+}
+
+// CacheConfig ตั้งค่า cache ของ response หน้า dashboard / analytics / budget progress
+// Provider "memory" ใช้ LRU ใน process, "redis" ใช้ server ที่เข้ากันได้กับ Redis, "none" ปิด cache (ETag ยังทำงาน)
+type CacheConfig struct {
+	Provider      string
+	Capacity      int
+	TTL           time.Duration
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+	RedisPoolSize int
+	RedisTimeout  time.Duration
+}
+
+// SystemConfig ตั้งค่าการเข้าถึง /system ที่ scheduler ใช้เรียกงาน background
+// ถ้า APIKey ว่าง route ใต้ /system จะถูกปิดทั้งหมด
+type SystemConfig struct {
+	APIKey string
+}
+
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
@@ -51,6 +91,31 @@ func Load() *Config {
 			Secret: getEnv("JWT_SECRET", "your-secret-key"),
 			Expiry: getEnvDuration("JWT_EXPIRY", 24*time.Hour),
 		},
+		Jobs: JobsConfig{
+			InsightConcurrency:  getEnvInt("INSIGHT_JOB_CONCURRENCY", 4),
+			InsightUserTimeout:  getEnvDuration("INSIGHT_JOB_USER_TIMEOUT", 30*time.Second),
+			SnapshotConcurrency: getEnvInt("SNAPSHOT_JOB_CONCURRENCY", 4),
+		},
+		LLM: LLMConfig{
+			Provider: getEnv("LLM_PROVIDER", "template"),
+			BaseURL:  getEnv("LLM_BASE_URL", "https://api.openai.com/v1"),
+			APIKey:   getEnv("LLM_API_KEY", ""),
+			Model:    getEnv("LLM_MODEL", "gpt-4o-mini"),
+			Timeout:  getEnvDuration("LLM_TIMEOUT", 10*time.Second),
+		},
+		Cache: CacheConfig{
+			Provider:      getEnv("CACHE_PROVIDER", "memory"),
+			Capacity:      getEnvInt("CACHE_CAPACITY", 10000),
+			TTL:           getEnvDuration("CACHE_TTL", 10*time.Minute),
+			RedisAddr:     getEnv("REDIS_ADDR", "localhost:6379"),
+			RedisPassword: getEnv("REDIS_PASSWORD", ""),
+			RedisDB:       getEnvInt("REDIS_DB", 0),
+			RedisPoolSize: getEnvInt("REDIS_POOL_SIZE", 10),
+			RedisTimeout:  getEnvDuration("REDIS_TIMEOUT", 500*time.Millisecond),
+		},
+		System: SystemConfig{
+			APIKey: getEnv("SYSTEM_API_KEY", ""),
+		},
 	}
 }
 
@@ -48,6 +48,8 @@ func (h *AccountHandler) CreateAccount(c *gin.Context) {
 		accountType = entity.AccountTypeCredit
 	case "savings":
 		accountType = entity.AccountTypeSavings
+	case "loan":
+		accountType = entity.AccountTypeLoan
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account type"})
 		return
@@ -2,7 +2,9 @@ package http
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -34,6 +36,11 @@ type InsightResponse struct {
 	RelatedEntityType *string                `json:"related_entity_type,omitempty"`
 	RelatedData       map[string]interface{} `json:"related_data,omitempty"`
 	ValidUntil        *string                `json:"valid_until,omitempty"`
+	DismissedAt       *string                `json:"dismissed_at,omitempty"`
+	SnoozedUntil      *string                `json:"snoozed_until,omitempty"`
+	ResolvedAt        *string                `json:"resolved_at,omitempty"`
+	MessageKey        *string                `json:"message_key,omitempty"`
+	Locale            *string                `json:"locale,omitempty"`
 	CreatedAt         string                 `json:"created_at"`
 	UpdatedAt         string                 `json:"updated_at"`
 }
@@ -79,7 +86,7 @@ func (h *AIInsightHandler) GenerateSpendingAnomalyInsights(c *gin.Context) {
 
 	responses := make([]*InsightResponse, len(insights))
 	for i, insight := range insights {
-		responses[i] = h.insightToResponse(insight)
+		responses[i] = insightToResponse(insight)
 	}
 
 	c.JSON(http.StatusOK, responses)
@@ -106,7 +113,7 @@ func (h *AIInsightHandler) GenerateSpendingPatternInsights(c *gin.Context) {
 
 	responses := make([]*InsightResponse, len(insights))
 	for i, insight := range insights {
-		responses[i] = h.insightToResponse(insight)
+		responses[i] = insightToResponse(insight)
 	}
 
 	c.JSON(http.StatusOK, responses)
@@ -144,7 +151,7 @@ func (h *AIInsightHandler) GenerateCategoryRecommendations(c *gin.Context) {
 
 	responses := make([]*InsightResponse, len(insights))
 	for i, insight := range insights {
-		responses[i] = h.insightToResponse(insight)
+		responses[i] = insightToResponse(insight)
 	}
 
 	c.JSON(http.StatusOK, responses)
@@ -171,7 +178,28 @@ func (h *AIInsightHandler) GenerateSavingsRecommendations(c *gin.Context) {
 
 	responses := make([]*InsightResponse, len(insights))
 	for i, insight := range insights {
-		responses[i] = h.insightToResponse(insight)
+		responses[i] = insightToResponse(insight)
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+func (h *AIInsightHandler) DetectSubscriptions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	insights, err := h.aiInsightUsecase.DetectSubscriptions(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	responses := make([]*InsightResponse, len(insights))
+	for i, insight := range insights {
+		responses[i] = insightToResponse(insight)
 	}
 
 	c.JSON(http.StatusOK, responses)
@@ -201,16 +229,29 @@ func (h *AIInsightHandler) ProcessWeeklyInsights(c *gin.Context) {
 	})
 }
 
+// ProcessAllUsersInsights - งานของ scheduler ตอบเฉพาะจำนวนผู้ใช้ รายละเอียดของแต่ละคนถูก log ฝั่ง server
 func (h *AIInsightHandler) ProcessAllUsersInsights(c *gin.Context) {
-	// This endpoint might be restricted to admin users in production
-	err := h.aiInsightUsecase.ProcessAllUsersInsights(c.Request.Context())
+	report, err := h.aiInsightUsecase.ProcessAllUsersInsights(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":     "Failed to process insights for all users",
+			"total":     report.TotalUsers,
+			"succeeded": len(report.SucceededUsers),
+			"failed":    len(report.FailedUsers),
+		})
 		return
 	}
 
+	message := "All users insights processed successfully"
+	if len(report.FailedUsers) > 0 {
+		message = fmt.Sprintf("Insights processed with %d failed user(s)", len(report.FailedUsers))
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "All users insights processed successfully",
+		"message":   message,
+		"total":     report.TotalUsers,
+		"succeeded": len(report.SucceededUsers),
+		"failed":    len(report.FailedUsers),
 	})
 }
 
@@ -221,18 +262,28 @@ func (h *AIInsightHandler) GetSpendingAnomalies(c *gin.Context) {
 		return
 	}
 
-	_, err := uuid.Parse(userID.(string))
+	months := 6
+	if monthsStr := c.Query("months"); monthsStr != "" {
+		parsed, err := strconv.Atoi(monthsStr)
+		if err != nil || parsed < 3 || parsed > 24 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid months, expected 3-24"})
+			return
+		}
+		months = parsed
+	}
+
+	anomalies, err := h.aiInsightUsecase.GetSpendingAnomalies(c.Request.Context(), userID.(uuid.UUID), months)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Note: This uses the repository method directly since the usecase doesn't expose it
-	// In a real implementation, you might want to add this to the usecase interface
-	c.JSON(http.StatusOK, gin.H{
-		"message":    "Use the insight generation endpoints to get anomaly insights",
-		"suggestion": "Call /ai-insights/spending-anomalies/generate instead",
-	})
+	responses := make([]*SpendingAnomalyResponse, len(anomalies))
+	for i, anomaly := range anomalies {
+		responses[i] = h.anomalyToResponse(anomaly)
+	}
+
+	c.JSON(http.StatusOK, responses)
 }
 
 func (h *AIInsightHandler) GetSpendingPatterns(c *gin.Context) {
@@ -242,31 +293,89 @@ func (h *AIInsightHandler) GetSpendingPatterns(c *gin.Context) {
 		return
 	}
 
-	_, err := uuid.Parse(userID.(string))
+	days := 30
+	if daysStr := c.Query("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed <= 0 || parsed > 365 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days, expected 1-365"})
+			return
+		}
+		days = parsed
+	}
+
+	patterns, err := h.aiInsightUsecase.GetSpendingPatterns(c.Request.Context(), userID.(uuid.UUID), days)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Note: This uses the repository method directly since the usecase doesn't expose it
-	// In a real implementation, you might want to add this to the usecase interface
+	responses := make([]*SpendingPatternResponse, len(patterns))
+	for i, pattern := range patterns {
+		responses[i] = h.patternToResponse(pattern)
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetSpendingPatternTrends - รูปแบบการใช้จ่ายพร้อมแนวโน้มที่งานวิเคราะห์บันทึกไว้ล่าสุด
+func (h *AIInsightHandler) GetSpendingPatternTrends(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	patterns, err := h.aiInsightUsecase.GetSpendingPatternTrends(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if patterns == nil {
+		patterns = []*entity.SpendingPatternTrend{}
+	}
+
+	c.JSON(http.StatusOK, patterns)
+}
+
+// AnalyzeSpendingPatternTrends - วิเคราะห์และบันทึกรูปแบบการใช้จ่ายใหม่ทันที
+func (h *AIInsightHandler) AnalyzeSpendingPatternTrends(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	patterns, err := h.aiInsightUsecase.AnalyzeSpendingPatternTrends(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if patterns == nil {
+		patterns = []*entity.SpendingPatternTrend{}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message":    "Use the insight generation endpoints to get pattern insights",
-		"suggestion": "Call /ai-insights/spending-patterns/generate instead",
+		"message":  "Spending patterns analyzed successfully",
+		"patterns": patterns,
+		"count":    len(patterns),
 	})
 }
 
-func (h *AIInsightHandler) insightToResponse(insight *entity.Insight) *InsightResponse {
+func insightToResponse(insight *entity.Insight) *InsightResponse {
 	response := &InsightResponse{
-		ID:        insight.ID.String(),
-		UserID:    insight.UserID.String(),
-		Type:      string(insight.Type),
-		Priority:  string(insight.Priority),
-		Title:     insight.Title,
-		Content:   insight.Content,
-		IsRead:    insight.IsRead,
-		CreatedAt: insight.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt: insight.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		ID:         insight.ID.String(),
+		UserID:     insight.UserID.String(),
+		Type:       string(insight.Type),
+		Priority:   string(insight.Priority),
+		Title:      insight.Title,
+		Content:    insight.Content,
+		IsRead:     insight.IsRead,
+		MessageKey: insight.MessageKey,
+		Locale:     insight.Locale,
+		CreatedAt:  insight.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:  insight.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 	}
 
 	if insight.ActionText != nil {
@@ -294,6 +403,21 @@ func (h *AIInsightHandler) insightToResponse(insight *entity.Insight) *InsightRe
 		response.ValidUntil = &validUntil
 	}
 
+	if insight.DismissedAt != nil {
+		dismissedAt := insight.DismissedAt.Format("2006-01-02T15:04:05Z")
+		response.DismissedAt = &dismissedAt
+	}
+
+	if insight.SnoozedUntil != nil {
+		snoozedUntil := insight.SnoozedUntil.Format("2006-01-02T15:04:05Z")
+		response.SnoozedUntil = &snoozedUntil
+	}
+
+	if insight.ResolvedAt != nil {
+		resolvedAt := insight.ResolvedAt.Format("2006-01-02T15:04:05Z")
+		response.ResolvedAt = &resolvedAt
+	}
+
 	return response
 }
 
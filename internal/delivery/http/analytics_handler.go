@@ -1,21 +1,28 @@
 package http
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 
 	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
 	"savvy-backend/internal/usecase"
+	"savvy-backend/pkg/utils"
 )
 
 type AnalyticsHandler struct {
 	dashboardUsecase   usecase.DashboardUsecase
 	transactionUsecase usecase.TransactionUsecase
 	categoryUsecase    usecase.CategoryUsecase
+	analyticsUsecase   usecase.AnalyticsUsecase
+	netWorthUsecase    usecase.NetWorthUsecase
 }
 
 type PieChartData struct {
@@ -39,11 +46,15 @@ func NewAnalyticsHandler(
 	dashboardUsecase usecase.DashboardUsecase,
 	transactionUsecase usecase.TransactionUsecase,
 	categoryUsecase usecase.CategoryUsecase,
+	analyticsUsecase usecase.AnalyticsUsecase,
+	netWorthUsecase usecase.NetWorthUsecase,
 ) *AnalyticsHandler {
 	return &AnalyticsHandler{
 		dashboardUsecase:   dashboardUsecase,
 		transactionUsecase: transactionUsecase,
 		categoryUsecase:    categoryUsecase,
+		analyticsUsecase:   analyticsUsecase,
+		netWorthUsecase:    netWorthUsecase,
 	}
 }
 
@@ -72,7 +83,12 @@ func (h *AnalyticsHandler) GetExpensePieChart(c *gin.Context) {
 	}
 
 	// Get spending by category
-	categorySpending, err := h.dashboardUsecase.GetSpendingByCategory(c.Request.Context(), userID.(uuid.UUID), year, month)
+	depth, ok := parseCategoryDepth(c)
+	if !ok {
+		return
+	}
+
+	categorySpending, err := h.dashboardUsecase.GetSpendingByCategory(c.Request.Context(), userID.(uuid.UUID), year, month, depth)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -118,17 +134,15 @@ func (h *AnalyticsHandler) GetIncomeExpenseBarChart(c *gin.Context) {
 		}
 	}
 
-	var barData []BarChartData
-
-	// Get data for each month
-	for month := 1; month <= 12; month++ {
-		summary, err := h.dashboardUsecase.GetMonthlySummary(c.Request.Context(), userID.(uuid.UUID), year, month)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
+	summaries, err := h.dashboardUsecase.GetYearlySummary(c.Request.Context(), userID.(uuid.UUID), year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-		monthName := time.Month(month).String()
+	var barData []BarChartData
+	for _, summary := range summaries {
+		monthName := time.Month(summary.Month).String()
 
 		// Convert decimal to float64
 		income, _ := summary.TotalIncome.Float64()
@@ -177,28 +191,40 @@ func (h *AnalyticsHandler) GetCategoryTrendChart(c *gin.Context) {
 		Amount float64 `json:"amount"`
 	}
 
-	var trendData []TrendData
-
-	// Get data for each month for specific category
-	for month := 1; month <= 12; month++ {
-		categorySpending, err := h.dashboardUsecase.GetSpendingByCategory(c.Request.Context(), userID.(uuid.UUID), year, month)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
+	// รวมยอดรายเดือนของหมวดหมู่นี้ใน query เดียวแทนการดึงยอดทุกหมวดหมู่ทีละเดือน
+	startDate := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	expenseType := entity.TransactionTypeExpense
+
+	series, err := h.analyticsUsecase.GetTimeSeries(c.Request.Context(), repository.TimeSeriesQuery{
+		Filter: repository.TransactionFilter{
+			UserID:     userID.(uuid.UUID),
+			CategoryID: &categoryID,
+			Type:       &expenseType,
+			StartDate:  &startDate,
+			EndDate:    &endDate,
+		},
+		Granularity: entity.GranularityMonth,
+		GroupBy:     entity.GroupByNone,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-		amount := 0.0
-		for _, spending := range categorySpending {
-			if spending.CategoryID == categoryID {
-				amount, _ = spending.Amount.Float64()
-				break
-			}
+	amounts := make(map[time.Month]float64)
+	for _, group := range series.Groups {
+		for _, point := range group.Points {
+			amount, _ := point.Total.Float64()
+			amounts[point.PeriodStart.Month()] += amount
 		}
+	}
 
-		monthName := time.Month(month).String()
+	var trendData []TrendData
+	for month := time.January; month <= time.December; month++ {
 		trendData = append(trendData, TrendData{
-			Month:  monthName,
-			Amount: amount,
+			Month:  month.String(),
+			Amount: amounts[month],
 		})
 	}
 
@@ -271,3 +297,359 @@ func (h *AnalyticsHandler) GetTopCategoriesChart(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// ComparePeriods - เปรียบเทียบยอดรายหมวดหมู่ของช่วงวันที่ที่เลือกกับช่วงฐาน
+// baseline: previous_period (ค่าเริ่มต้น), previous_year, trailing_average (ใช้ periods) หรือ custom (ใช้ baseline_start_date/baseline_end_date)
+func (h *AnalyticsHandler) ComparePeriods(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	// ค่าเริ่มต้นคือเดือนปัจจุบันทั้งเดือน
+	now := time.Now()
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	current, ok := parseDateRange(c, "start_date", "end_date", entity.DateRange{
+		StartDate: startOfMonth,
+		EndDate:   startOfMonth.AddDate(0, 1, -1),
+	})
+	if !ok {
+		return
+	}
+
+	options := usecase.ComparisonOptions{
+		Type:     entity.TransactionTypeExpense,
+		Current:  current,
+		Baseline: entity.ComparisonBaseline(c.DefaultQuery("baseline", string(entity.ComparisonBaselinePreviousPeriod))),
+	}
+
+	switch transactionType := c.Query("type"); transactionType {
+	case "", string(entity.TransactionTypeExpense):
+	case string(entity.TransactionTypeIncome):
+		options.Type = entity.TransactionTypeIncome
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid type, expected income or expense"})
+		return
+	}
+
+	if periodsStr := c.Query("periods"); periodsStr != "" {
+		periods, err := strconv.Atoi(periodsStr)
+		if err != nil || periods <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid periods"})
+			return
+		}
+		options.Periods = periods
+	}
+
+	if topStr := c.Query("top"); topStr != "" {
+		if top, err := strconv.Atoi(topStr); err == nil && top > 0 && top <= 20 {
+			options.Top = top
+		}
+	}
+
+	if options.Baseline == entity.ComparisonBaselineCustom {
+		baselineRange, ok := parseDateRange(c, "baseline_start_date", "baseline_end_date", entity.DateRange{})
+		if !ok {
+			return
+		}
+		if baselineRange.StartDate.IsZero() || baselineRange.EndDate.IsZero() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "baseline_start_date and baseline_end_date are required for custom baseline"})
+			return
+		}
+		options.BaselineRange = &baselineRange
+	}
+
+	comparison, err := h.analyticsUsecase.ComparePeriods(c.Request.Context(), userID.(uuid.UUID), options)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+// GetTimeSeries - ยอดรวมตามช่วงเวลา (day/week/month/quarter/year) แบ่งกลุ่มตาม category/account/type
+// รองรับ filter เดียวกับ GET /transactions ค่าเริ่มต้นคือรายเดือนย้อนหลัง 12 เดือน
+func (h *AnalyticsHandler) GetTimeSeries(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	dateRange, ok := parseDateRange(c, "start_date", "end_date", entity.DateRange{
+		StartDate: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -11, 0),
+		EndDate:   today,
+	})
+	if !ok {
+		return
+	}
+
+	query := repository.TimeSeriesQuery{
+		Filter: repository.TransactionFilter{
+			UserID:    userID.(uuid.UUID),
+			StartDate: &dateRange.StartDate,
+			EndDate:   &dateRange.EndDate,
+		},
+		Granularity: entity.TimeSeriesGranularity(c.DefaultQuery("granularity", string(entity.GranularityMonth))),
+		GroupBy:     entity.TimeSeriesGroupBy(c.DefaultQuery("group_by", string(entity.GroupByNone))),
+	}
+
+	if accountIDStr := c.Query("account_id"); accountIDStr != "" {
+		accountID, err := uuid.Parse(accountIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+			return
+		}
+		query.Filter.AccountID = &accountID
+	}
+
+	if categoryIDStr := c.Query("category_id"); categoryIDStr != "" {
+		categoryID, err := uuid.Parse(categoryIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+			return
+		}
+		query.Filter.CategoryID = &categoryID
+	}
+
+	switch typeStr := c.Query("type"); typeStr {
+	case "":
+	case string(entity.TransactionTypeIncome), string(entity.TransactionTypeExpense):
+		transactionType := entity.TransactionType(typeStr)
+		query.Filter.Type = &transactionType
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid type, expected income or expense"})
+		return
+	}
+
+	if searchQuery := c.Query("search"); searchQuery != "" {
+		query.Filter.SearchQuery = &searchQuery
+	}
+
+	if minAmountStr := c.Query("min_amount"); minAmountStr != "" {
+		minAmount, err := decimal.NewFromString(minAmountStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_amount"})
+			return
+		}
+		query.Filter.MinAmount = &minAmount
+	}
+
+	if maxAmountStr := c.Query("max_amount"); maxAmountStr != "" {
+		maxAmount, err := decimal.NewFromString(maxAmountStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max_amount"})
+			return
+		}
+		query.Filter.MaxAmount = &maxAmount
+	}
+
+	series, err := h.analyticsUsecase.GetTimeSeries(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// GetSpendingCalendar - รายจ่ายรายวันของเดือน (ยอดรวม, จำนวนรายการ, หมวดหมู่ที่ใช้จ่ายมากที่สุด) สำหรับมุมมองปฏิทิน
+func (h *AnalyticsHandler) GetSpendingCalendar(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	year := time.Now().Year()
+	month := int(time.Now().Month())
+
+	if yearStr := c.Query("year"); yearStr != "" {
+		y, err := strconv.Atoi(yearStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+			return
+		}
+		year = y
+	}
+
+	if monthStr := c.Query("month"); monthStr != "" {
+		m, err := strconv.Atoi(monthStr)
+		if err != nil || m < 1 || m > 12 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month"})
+			return
+		}
+		month = m
+	}
+
+	calendar, err := h.analyticsUsecase.GetSpendingCalendar(c.Request.Context(), userID.(uuid.UUID), year, month)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+// GetSpendingHeatmap - ความหนาแน่นของรายจ่ายตามวันในสัปดาห์ × ชั่วโมง ค่าเริ่มต้นคือย้อนหลัง 90 วัน
+func (h *AnalyticsHandler) GetSpendingHeatmap(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	dateRange, ok := parseDateRange(c, "start_date", "end_date", entity.DateRange{
+		StartDate: today.AddDate(0, 0, -89),
+		EndDate:   today,
+	})
+	if !ok {
+		return
+	}
+
+	heatmap, err := h.analyticsUsecase.GetSpendingHeatmap(c.Request.Context(), userID.(uuid.UUID), dateRange)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, heatmap)
+}
+
+// GetSankeyFlow - โหนดและลิงก์ของกราฟ Sankey: หมวดหมู่รายรับ → บัญชี → หมวดหมู่รายจ่าย (และ savings)
+// ค่าเริ่มต้นคือเดือนปัจจุบันทั้งเดือน
+func (h *AnalyticsHandler) GetSankeyFlow(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	now := time.Now()
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	dateRange, ok := parseDateRange(c, "start_date", "end_date", entity.DateRange{
+		StartDate: startOfMonth,
+		EndDate:   startOfMonth.AddDate(0, 1, -1),
+	})
+	if !ok {
+		return
+	}
+
+	flow, err := h.analyticsUsecase.GetSankeyFlow(c.Request.Context(), userID.(uuid.UUID), dateRange)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, flow)
+}
+
+// GetNetWorth - มูลค่าสุทธิปัจจุบันแยกตามประเภทบัญชี พร้อม snapshot ย้อนหลังในช่วง from/to
+// granularity: month (ค่าเริ่มต้น, snapshot สุดท้ายของแต่ละเดือน) หรือ day ค่าเริ่มต้นคือย้อนหลัง 12 เดือน
+func (h *AnalyticsHandler) GetNetWorth(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	dateRange, ok := parseDateRange(c, "from", "to", entity.DateRange{
+		StartDate: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -11, 0),
+		EndDate:   today,
+	})
+	if !ok {
+		return
+	}
+
+	granularity := entity.NetWorthGranularity(c.DefaultQuery("granularity", string(entity.NetWorthGranularityMonth)))
+
+	history, err := h.netWorthUsecase.GetNetWorthHistory(c.Request.Context(), userID.(uuid.UUID), dateRange.StartDate, dateRange.EndDate, granularity)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// RecordNetWorthSnapshots - บันทึก snapshot มูลค่าสุทธิของผู้ใช้ทุกคน (เรียกจาก scheduler ทุกวัน)
+// ระบุ date=YYYY-MM-DD เพื่อบันทึกย้อนหลังได้ (ห้ามเป็นวันในอนาคต) ค่าเริ่มต้นคือวันนี้
+// ตอบเฉพาะจำนวนผู้ใช้ รายละเอียดของแต่ละคนถูก log ฝั่ง server
+func (h *AnalyticsHandler) RecordNetWorthSnapshots(c *gin.Context) {
+	now := time.Now()
+	date := now
+	if dateStr := c.Query("date"); dateStr != "" {
+		parsed, err := utils.ParseDate(dateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+			return
+		}
+		if parsed.After(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, parsed.Location())) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date cannot be in the future"})
+			return
+		}
+		date = parsed
+	}
+
+	// งานไม่ควรหยุดกลางคันเมื่อ scheduler ตัดการเชื่อมต่อ จึงไม่ผูกกับการยกเลิกของ request
+	report, err := h.netWorthUsecase.RecordAllSnapshots(context.WithoutCancel(c.Request.Context()), date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":     "Failed to record net worth snapshots",
+			"total":     report.TotalUsers,
+			"succeeded": report.SucceededUsers,
+			"failed":    len(report.FailedUsers),
+		})
+		return
+	}
+
+	message := "Net worth snapshots recorded successfully"
+	if len(report.FailedUsers) > 0 {
+		message = fmt.Sprintf("Net worth snapshots recorded with %d failed user(s)", len(report.FailedUsers))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   message,
+		"total":     report.TotalUsers,
+		"succeeded": report.SucceededUsers,
+		"failed":    len(report.FailedUsers),
+	})
+}
+
+// parseDateRange อ่านช่วงวันที่จาก query string โดยใช้ค่า fallback สำหรับค่าที่ไม่ได้ระบุ
+func parseDateRange(c *gin.Context, startKey, endKey string, fallback entity.DateRange) (entity.DateRange, bool) {
+	dateRange := fallback
+
+	if startStr := c.Query(startKey); startStr != "" {
+		startDate, err := utils.ParseDate(startStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + startKey + ", expected YYYY-MM-DD"})
+			return dateRange, false
+		}
+		dateRange.StartDate = startDate
+	}
+
+	if endStr := c.Query(endKey); endStr != "" {
+		endDate, err := utils.ParseDate(endStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + endKey + ", expected YYYY-MM-DD"})
+			return dateRange, false
+		}
+		dateRange.EndDate = endDate
+	}
+
+	if !dateRange.StartDate.IsZero() && !dateRange.EndDate.IsZero() && dateRange.EndDate.Before(dateRange.StartDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": endKey + " must not be before " + startKey})
+		return dateRange, false
+	}
+
+	return dateRange, true
+}
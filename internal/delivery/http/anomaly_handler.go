@@ -0,0 +1,138 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+	"savvy-backend/internal/usecase"
+)
+
+type AnomalyHandler struct {
+	anomalyUsecase usecase.TransactionAnomalyUsecase
+}
+
+func NewAnomalyHandler(anomalyUsecase usecase.TransactionAnomalyUsecase) *AnomalyHandler {
+	return &AnomalyHandler{
+		anomalyUsecase: anomalyUsecase,
+	}
+}
+
+type ReviewAnomalyRequest struct {
+	Status entity.AnomalyReviewStatus `json:"status" binding:"required"`
+}
+
+// GetAnomalies - รายการที่ยอดผิดปกติ (ค่าเริ่มต้นแสดงเฉพาะที่ยังไม่ได้ตรวจสอบ)
+func (h *AnomalyHandler) GetAnomalies(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	filter := repository.SpendingAnomalyFilter{
+		UserID: userID.(uuid.UUID),
+		Limit:  20,
+	}
+
+	status := entity.AnomalyReviewStatus(c.DefaultQuery("status", string(entity.AnomalyReviewPending)))
+	if status != "all" {
+		if status != entity.AnomalyReviewPending && status != entity.AnomalyReviewConfirmed && status != entity.AnomalyReviewDismissed {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, expected pending, confirmed, dismissed or all"})
+			return
+		}
+		filter.ReviewStatus = &status
+	}
+
+	if severity := c.Query("severity"); severity != "" {
+		if severity != "low" && severity != "medium" && severity != "high" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid severity, expected low, medium or high"})
+			return
+		}
+		filter.Severity = &severity
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			filter.Limit = l
+		}
+	}
+
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			filter.Offset = o
+		}
+	}
+
+	anomalies, total, err := h.anomalyUsecase.ListAnomalies(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if anomalies == nil {
+		anomalies = []*entity.TransactionAnomaly{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"anomalies": anomalies,
+		"total":     total,
+		"limit":     filter.Limit,
+		"offset":    filter.Offset,
+	})
+}
+
+func (h *AnomalyHandler) GetAnomaly(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	anomalyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid anomaly ID"})
+		return
+	}
+
+	anomaly, err := h.anomalyUsecase.GetAnomaly(c.Request.Context(), userID.(uuid.UUID), anomalyID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, anomaly)
+}
+
+// ReviewAnomaly - ยืนยันว่าผิดปกติจริง (confirmed) หรือเป็นรายการที่คาดไว้แล้ว (dismissed)
+func (h *AnomalyHandler) ReviewAnomaly(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	anomalyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid anomaly ID"})
+		return
+	}
+
+	var req ReviewAnomalyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	anomaly, err := h.anomalyUsecase.ReviewAnomaly(c.Request.Context(), userID.(uuid.UUID), anomalyID, req.Status)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, anomaly)
+}
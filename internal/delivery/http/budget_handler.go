@@ -61,6 +61,9 @@ type BudgetProgressResponse struct {
 	DaysRemaining   int     `json:"days_remaining"`
 	AverageDaily    string  `json:"average_daily_spent"`
 	ProjectedTotal  string  `json:"projected_total"`
+	// ยอดรายจ่ายประจำที่ยังไม่ถึงกำหนดในเดือนนี้ (รวมอยู่ใน projected_total แล้ว)
+	UpcomingRecurring     string `json:"upcoming_recurring"`
+	IsProjectedOverBudget bool   `json:"is_projected_over_budget"`
 }
 
 func (h *BudgetHandler) CreateBudget(c *gin.Context) {
@@ -329,14 +332,16 @@ func (h *BudgetHandler) GetBudgetProgress(c *gin.Context) {
 	responses := make([]*BudgetProgressResponse, len(progressList))
 	for i, progress := range progressList {
 		responses[i] = &BudgetProgressResponse{
-			BudgetID:        progress.BudgetID.String(),
-			SpentAmount:     progress.SpentAmount.String(),
-			RemainingAmount: progress.RemainingAmount.String(),
-			PercentageUsed:  progress.ProgressPercentage,
-			IsOverBudget:    progress.IsOverBudget,
-			DaysRemaining:   0,   // Will be calculated based on period
-			AverageDaily:    "0", // Will be calculated based on period
-			ProjectedTotal:  progress.BudgetAmount.String(),
+			BudgetID:              progress.BudgetID.String(),
+			SpentAmount:           progress.SpentAmount.String(),
+			RemainingAmount:       progress.RemainingAmount.String(),
+			PercentageUsed:        progress.ProgressPercentage,
+			IsOverBudget:          progress.IsOverBudget,
+			DaysRemaining:         0,   // Will be calculated based on period
+			AverageDaily:          "0", // Will be calculated based on period
+			ProjectedTotal:        progress.ProjectedAmount.String(),
+			UpcomingRecurring:     progress.UpcomingRecurringAmount.String(),
+			IsProjectedOverBudget: progress.IsProjectedOverBudget,
 		}
 	}
 
@@ -365,14 +370,16 @@ func (h *BudgetHandler) GetCurrentMonthProgress(c *gin.Context) {
 	responses := make([]*BudgetProgressResponse, len(progressList))
 	for i, progress := range progressList {
 		responses[i] = &BudgetProgressResponse{
-			BudgetID:        progress.BudgetID.String(),
-			SpentAmount:     progress.SpentAmount.String(),
-			RemainingAmount: progress.RemainingAmount.String(),
-			PercentageUsed:  progress.ProgressPercentage,
-			IsOverBudget:    progress.IsOverBudget,
-			DaysRemaining:   0,   // Will be calculated based on period
-			AverageDaily:    "0", // Will be calculated based on period
-			ProjectedTotal:  progress.BudgetAmount.String(),
+			BudgetID:              progress.BudgetID.String(),
+			SpentAmount:           progress.SpentAmount.String(),
+			RemainingAmount:       progress.RemainingAmount.String(),
+			PercentageUsed:        progress.ProgressPercentage,
+			IsOverBudget:          progress.IsOverBudget,
+			DaysRemaining:         0,   // Will be calculated based on period
+			AverageDaily:          "0", // Will be calculated based on period
+			ProjectedTotal:        progress.ProjectedAmount.String(),
+			UpcomingRecurring:     progress.UpcomingRecurringAmount.String(),
+			IsProjectedOverBudget: progress.IsProjectedOverBudget,
 		}
 	}
 
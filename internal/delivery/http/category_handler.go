@@ -1,12 +1,14 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
 	"savvy-backend/internal/usecase"
 )
 
@@ -19,6 +21,23 @@ type CreateCategoryRequest struct {
 	Type     string  `json:"type" binding:"required"`
 	IconName *string `json:"icon_name,omitempty"`
 	ColorHex *string `json:"color_hex,omitempty"`
+	ParentID *string `json:"parent_id,omitempty"`
+}
+
+// UpdateCategoryRequest แทนที่ข้อมูลหมวดหมู่ทั้งหมด (ประเภทเปลี่ยนไม่ได้) ไม่ส่ง parent_id = หมวดหมู่หลัก
+type UpdateCategoryRequest struct {
+	Name     string  `json:"name" binding:"required"`
+	IconName *string `json:"icon_name,omitempty"`
+	ColorHex *string `json:"color_hex,omitempty"`
+	ParentID *string `json:"parent_id,omitempty"`
+}
+
+// CategoryOverrideRequest ปรับหมวดหมู่ระบบเฉพาะผู้ใช้ ฟิลด์ที่ไม่ส่งใช้ค่าของหมวดหมู่ระบบ
+type CategoryOverrideRequest struct {
+	Name     *string `json:"name,omitempty"`
+	IconName *string `json:"icon_name,omitempty"`
+	ColorHex *string `json:"color_hex,omitempty"`
+	IsHidden bool    `json:"is_hidden"`
 }
 
 func NewCategoryHandler(categoryUsecase usecase.CategoryUsecase) *CategoryHandler {
@@ -51,6 +70,16 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 		return
 	}
 
+	var parentID *uuid.UUID
+	if req.ParentID != nil && *req.ParentID != "" {
+		parsed, err := uuid.Parse(*req.ParentID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parent category ID"})
+			return
+		}
+		parentID = &parsed
+	}
+
 	category, err := h.categoryUsecase.CreateCategory(
 		c.Request.Context(),
 		userID.(uuid.UUID),
@@ -58,6 +87,7 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 		categoryType,
 		req.IconName,
 		req.ColorHex,
+		parentID,
 	)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -115,6 +145,109 @@ func (h *CategoryHandler) GetUserCategories(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"categories": categories})
 }
 
+// GetCategoryTree - หมวดหมู่ของระบบและของผู้ใช้ จัดเป็นต้นไม้ (หมวดหมู่หลัก → หมวดหมู่ย่อย)
+func (h *CategoryHandler) GetCategoryTree(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	tree, err := h.categoryUsecase.GetCategoryTree(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": tree})
+}
+
+// GetCategory - หมวดหมู่ตาม id รวมหมวดหมู่ที่ archive แล้ว
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	category, err := h.categoryUsecase.GetCategory(c.Request.Context(), userID.(uuid.UUID), categoryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// GetCategoryUsage - จำนวนรายการ รายการประจำ และงบที่อ้างถึงแต่ละหมวดหมู่
+func (h *CategoryHandler) GetCategoryUsage(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	usage, err := h.categoryUsecase.GetCategoryUsage(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": usage})
+}
+
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	var req UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.categoryUsecase.GetCategory(c.Request.Context(), userID.(uuid.UUID), categoryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	category.Name = req.Name
+	category.IconName = req.IconName
+	category.ColorHex = req.ColorHex
+	category.ParentID = nil
+	if req.ParentID != nil && *req.ParentID != "" {
+		parsed, err := uuid.Parse(*req.ParentID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parent category ID"})
+			return
+		}
+		category.ParentID = &parsed
+	}
+
+	if err := h.categoryUsecase.UpdateCategory(c.Request.Context(), userID.(uuid.UUID), category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
 func (h *CategoryHandler) ArchiveCategory(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -137,6 +270,181 @@ func (h *CategoryHandler) ArchiveCategory(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Category archived successfully"})
 }
 
+func (h *CategoryHandler) UnarchiveCategory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	err = h.categoryUsecase.UnarchiveCategory(c.Request.Context(), userID.(uuid.UUID), categoryID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category unarchived successfully"})
+}
+
+// DeleteCategory - ลบหมวดหมู่ ถ้ายังมีข้อมูลอ้างถึงจะตอบ 409 เว้นแต่ระบุ ?reassign_to= ให้ย้ายข้อมูลไปก่อน
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	var reassignTo *uuid.UUID
+	if raw := c.Query("reassign_to"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reassign_to category ID"})
+			return
+		}
+		reassignTo = &parsed
+	}
+
+	result, err := h.categoryUsecase.DeleteCategory(c.Request.Context(), userID.(uuid.UUID), categoryID, reassignTo)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, usecase.ErrCategoryInUse) || errors.Is(err, repository.ErrBudgetPeriodMismatch) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "Category deleted successfully"}
+	if result != nil {
+		response["reassigned"] = result
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// MergeCategory - ย้ายรายการ รายการประจำ และงบของหมวดหมู่ไปหมวดหมู่ปลายทาง แล้ว archive หมวดหมู่เดิม
+func (h *CategoryHandler) MergeCategory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	sourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	targetID, err := uuid.Parse(c.Param("target"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target category ID"})
+		return
+	}
+
+	result, err := h.categoryUsecase.MergeCategory(c.Request.Context(), userID.(uuid.UUID), sourceID, targetID)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, repository.ErrBudgetPeriodMismatch) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Category merged successfully",
+		"result":  result,
+	})
+}
+
+// GetCategoryOverrides - override หมวดหมู่ระบบของผู้ใช้ รวมหมวดหมู่ที่ซ่อนไว้
+func (h *CategoryHandler) GetCategoryOverrides(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	overrides, err := h.categoryUsecase.GetCategoryOverrides(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"overrides": overrides})
+}
+
+// SetCategoryOverride - เปลี่ยนชื่อ ไอคอน สี หรือซ่อนหมวดหมู่ระบบเฉพาะผู้ใช้ แทนที่ override เดิมทั้งหมด
+func (h *CategoryHandler) SetCategoryOverride(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	var req CategoryOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	override, err := h.categoryUsecase.SetCategoryOverride(
+		c.Request.Context(),
+		userID.(uuid.UUID),
+		categoryID,
+		req.Name,
+		req.IconName,
+		req.ColorHex,
+		req.IsHidden,
+	)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, override)
+}
+
+// ResetCategoryOverride - ลบ override ให้หมวดหมู่ระบบกลับเป็นค่าเดิม
+func (h *CategoryHandler) ResetCategoryOverride(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	if err := h.categoryUsecase.ResetCategoryOverride(c.Request.Context(), userID.(uuid.UUID), categoryID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category override removed successfully"})
+}
+
 func (h *CategoryHandler) InitializeDefaultCategories(c *gin.Context) {
 	err := h.categoryUsecase.InitializeDefaultCategories(c.Request.Context())
 	if err != nil {
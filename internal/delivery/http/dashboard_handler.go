@@ -135,7 +135,12 @@ func (h *DashboardHandler) GetSpendingByCategory(c *gin.Context) {
 		}
 	}
 
-	spending, err := h.dashboardUsecase.GetSpendingByCategory(c.Request.Context(), userID.(uuid.UUID), year, month)
+	depth, ok := parseCategoryDepth(c)
+	if !ok {
+		return
+	}
+
+	spending, err := h.dashboardUsecase.GetSpendingByCategory(c.Request.Context(), userID.(uuid.UUID), year, month, depth)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -167,7 +172,7 @@ func (h *DashboardHandler) GetDashboard(c *gin.Context) {
 
 	// Get spending by category for current month
 	now := time.Now()
-	spendingByCategory, err := h.dashboardUsecase.GetSpendingByCategory(c.Request.Context(), userID.(uuid.UUID), now.Year(), int(now.Month()))
+	spendingByCategory, err := h.dashboardUsecase.GetSpendingByCategory(c.Request.Context(), userID.(uuid.UUID), now.Year(), int(now.Month()), 0)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get spending by category"})
 		return
@@ -181,3 +186,36 @@ func (h *DashboardHandler) GetDashboard(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// GetFinancialHealth - ตัวชี้วัดสุขภาพการเงินของเดือนปัจจุบันพร้อมประวัติ 12 เดือน
+func (h *DashboardHandler) GetFinancialHealth(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	report, err := h.dashboardUsecase.GetFinancialHealth(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// parseCategoryDepth อ่าน depth สำหรับรวมหมวดหมู่ย่อย (0 = ไม่รวม, 1 = หมวดหมู่หลัก, ...)
+func parseCategoryDepth(c *gin.Context) (int, bool) {
+	depthStr := c.Query("depth")
+	if depthStr == "" {
+		return 0, true
+	}
+
+	depth, err := strconv.Atoi(depthStr)
+	if err != nil || depth < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid depth"})
+		return 0, false
+	}
+
+	return depth, true
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"savvy-backend/internal/usecase"
+)
+
+type ForecastHandler struct {
+	forecastUsecase usecase.ForecastUsecase
+}
+
+func NewForecastHandler(forecastUsecase usecase.ForecastUsecase) *ForecastHandler {
+	return &ForecastHandler{
+		forecastUsecase: forecastUsecase,
+	}
+}
+
+// GetCashFlowForecast - คาดการณ์ยอดคงเหลือรายวันของแต่ละบัญชี (?days=90)
+func (h *ForecastHandler) GetCashFlowForecast(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	days, ok := parseForecastDays(c)
+	if !ok {
+		return
+	}
+
+	forecast, err := h.forecastUsecase.GetCashFlowForecast(c.Request.Context(), userID.(uuid.UUID), days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
+
+// CheckCashFlowWarnings - สร้าง insight แจ้งเตือนบัญชีที่คาดว่ายอดจะติดลบ
+func (h *ForecastHandler) CheckCashFlowWarnings(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	days, ok := parseForecastDays(c)
+	if !ok {
+		return
+	}
+
+	insights, err := h.forecastUsecase.CheckCashFlowWarnings(c.Request.Context(), userID.(uuid.UUID), days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Cash-flow warnings checked successfully",
+		"warnings": len(insights),
+		"insights": insights,
+	})
+}
+
+func parseForecastDays(c *gin.Context) (int, bool) {
+	days := usecase.DefaultForecastDays
+	if daysStr := c.Query("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed <= 0 || parsed > usecase.MaxForecastDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days, expected 1-" + strconv.Itoa(usecase.MaxForecastDays)})
+			return 0, false
+		}
+		days = parsed
+	}
+	return days, true
+}
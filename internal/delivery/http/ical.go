@@ -0,0 +1,87 @@
+package http
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+)
+
+// renderICalendar สร้างเนื้อหา iCalendar (RFC 5545) จากรายการที่คาดว่าจะเกิดขึ้น
+// แต่ละรายการเป็น all-day event เพื่อให้แอปปฏิทินแสดงเป็นวันครบกำหนดของบิล/รายรับ
+func renderICalendar(calendarName string, occurrences []*entity.RecurringOccurrence, generatedAt time.Time) string {
+	var b strings.Builder
+
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//Savvy//Upcoming Bills//EN")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	writeICalLine(&b, "X-WR-CALNAME:"+escapeICalText(calendarName))
+
+	stamp := generatedAt.UTC().Format("20060102T150405Z")
+	for _, occurrence := range occurrences {
+		day := occurrence.Date.Format("20060102")
+		nextDay := occurrence.Date.AddDate(0, 0, 1).Format("20060102")
+
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, fmt.Sprintf("UID:%s-%s@savvy", occurrence.RecurringTransactionID, day))
+		writeICalLine(&b, "DTSTAMP:"+stamp)
+		writeICalLine(&b, "DTSTART;VALUE=DATE:"+day)
+		writeICalLine(&b, "DTEND;VALUE=DATE:"+nextDay)
+		writeICalLine(&b, "SUMMARY:"+escapeICalText(occurrenceSummary(occurrence)))
+		if occurrence.Note != nil && *occurrence.Note != "" {
+			writeICalLine(&b, "DESCRIPTION:"+escapeICalText(*occurrence.Note))
+		}
+		writeICalLine(&b, "CATEGORIES:"+escapeICalText(string(occurrence.Type)))
+		writeICalLine(&b, "TRANSP:TRANSPARENT")
+		writeICalLine(&b, "END:VEVENT")
+	}
+
+	writeICalLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+func occurrenceSummary(occurrence *entity.RecurringOccurrence) string {
+	label := occurrence.CategoryName
+	if occurrence.Note != nil && *occurrence.Note != "" {
+		label = *occurrence.Note
+	}
+
+	sign := "-"
+	if occurrence.Type == entity.TransactionTypeIncome {
+		sign = "+"
+	}
+
+	return fmt.Sprintf("%s %s%s", label, sign, occurrence.Amount.StringFixed(2))
+}
+
+func escapeICalText(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return replacer.Replace(value)
+}
+
+// writeICalLine folds lines longer than 75 octets as required by RFC 5545,
+// without splitting multi-byte UTF-8 characters (Thai category names are common).
+func writeICalLine(b *strings.Builder, line string) {
+	const maxOctets = 75
+
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > maxOctets {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"savvy-backend/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// unfoldICal รวมบรรทัดที่ถูก fold กลับเป็นบรรทัดเดิม (RFC 5545 section 3.1)
+func unfoldICal(content string) []string {
+	content = strings.TrimSuffix(content, "\r\n")
+	return strings.Split(strings.ReplaceAll(content, "\r\n ", ""), "\r\n")
+}
+
+func TestRenderICalendar(t *testing.T) {
+	ruleID := uuid.New()
+	note := "Netflix, Premium; family"
+	generatedAt := time.Date(2026, time.October, 18, 9, 30, 0, 0, time.FixedZone("ICT", 7*60*60))
+
+	occurrences := []*entity.RecurringOccurrence{
+		{
+			RecurringTransactionID: ruleID,
+			CategoryName:           "บันเทิง",
+			Date:                   time.Date(2026, time.October, 31, 0, 0, 0, 0, time.UTC),
+			Amount:                 decimal.NewFromInt(419),
+			Type:                   entity.TransactionTypeExpense,
+			Note:                   &note,
+		},
+		{
+			RecurringTransactionID: ruleID,
+			CategoryName:           "เงินเดือน",
+			Date:                   time.Date(2026, time.December, 31, 0, 0, 0, 0, time.UTC),
+			Amount:                 decimal.RequireFromString("50000.5"),
+			Type:                   entity.TransactionTypeIncome,
+		},
+	}
+
+	content := renderICalendar("Savvy: Upcoming bills", occurrences, generatedAt)
+	if !strings.HasSuffix(content, "\r\n") || strings.Contains(strings.ReplaceAll(content, "\r\n", ""), "\n") {
+		t.Fatal("renderICalendar() must terminate every line with CRLF")
+	}
+
+	lines := unfoldICal(content)
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "calendar begins", line: "BEGIN:VCALENDAR"},
+		{name: "calendar name", line: "X-WR-CALNAME:Savvy: Upcoming bills"},
+		{name: "stamp in utc", line: "DTSTAMP:20261018T023000Z"},
+		{name: "first uid", line: "UID:" + ruleID.String() + "-20261031@savvy"},
+		{name: "all-day start", line: "DTSTART;VALUE=DATE:20261031"},
+		{name: "end is next day", line: "DTEND;VALUE=DATE:20261101"},
+		{name: "summary uses escaped note", line: `SUMMARY:Netflix\, Premium\; family -419.00`},
+		{name: "description", line: `DESCRIPTION:Netflix\, Premium\; family`},
+		{name: "end crosses year", line: "DTEND;VALUE=DATE:20270101"},
+		{name: "income summary uses category", line: "SUMMARY:เงินเดือน +50000.50"},
+		{name: "calendar ends", line: "END:VCALENDAR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, line := range lines {
+				if line == tt.line {
+					return
+				}
+			}
+			t.Fatalf("missing line %q in:\n%s", tt.line, strings.Join(lines, "\n"))
+		})
+	}
+
+	if got := strings.Count(content, "BEGIN:VEVENT"); got != len(occurrences) {
+		t.Errorf("VEVENT count = %d, want %d", got, len(occurrences))
+	}
+	if got := strings.Count(content, "DESCRIPTION:"); got != 1 {
+		t.Errorf("DESCRIPTION count = %d, want 1 (only occurrences with a note)", got)
+	}
+}
+
+func TestRenderICalendarEmpty(t *testing.T) {
+	lines := unfoldICal(renderICalendar("Empty", nil, time.Now()))
+	if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-1] != "END:VCALENDAR" {
+		t.Fatalf("renderICalendar(nil) = %q", lines)
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(line, "BEGIN:VEVENT") {
+			t.Fatal("renderICalendar(nil) contains an event")
+		}
+	}
+}
+
+func TestEscapeICalText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "plain", want: "plain"},
+		{input: "a,b;c", want: `a\,b\;c`},
+		{input: `back\slash`, want: `back\\slash`},
+		{input: "line1\nline2", want: `line1\nline2`},
+		{input: "line1\r\nline2", want: `line1\nline2`},
+		{input: "ค่าไฟ, ค่าน้ำ", want: `ค่าไฟ\, ค่าน้ำ`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeICalText(tt.input); got != tt.want {
+			t.Errorf("escapeICalText(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestWriteICalLineFolding(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "short", line: "SUMMARY:Rent"},
+		{name: "exactly 75 octets", line: "SUMMARY:" + strings.Repeat("x", 67)},
+		{name: "long ascii", line: "DESCRIPTION:" + strings.Repeat("abcdefghij", 20)},
+		{name: "long thai", line: "SUMMARY:" + strings.Repeat("ค่าอินเทอร์เน็ต", 10)},
+		{name: "mixed emoji", line: "SUMMARY:" + strings.Repeat("🏠 ค่าเช่า ", 12)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			writeICalLine(&b, tt.line)
+			folded := b.String()
+
+			physical := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+			for i, line := range physical {
+				if len(line) > 75 {
+					t.Errorf("line %d is %d octets, want at most 75", i, len(line))
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a UTF-8 character: %q", i, line)
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space", i)
+				}
+			}
+
+			if unfolded := unfoldICal(folded); len(unfolded) != 1 || unfolded[0] != tt.line {
+				t.Fatalf("unfolded = %q, want %q", unfolded, tt.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,379 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+	"savvy-backend/internal/usecase"
+)
+
+type InsightHandler struct {
+	insightUsecase usecase.InsightUsecase
+}
+
+func NewInsightHandler(insightUsecase usecase.InsightUsecase) *InsightHandler {
+	return &InsightHandler{
+		insightUsecase: insightUsecase,
+	}
+}
+
+// SnoozeInsightRequest - ระบุ until (RFC3339 หรือ YYYY-MM-DD) หรือ hours อย่างใดอย่างหนึ่ง
+type SnoozeInsightRequest struct {
+	Until *string `json:"until,omitempty"`
+	Hours *int    `json:"hours,omitempty"`
+}
+
+type RateInsightRequest struct {
+	Rating  entity.InsightRating `json:"rating" binding:"required"`
+	Comment *string              `json:"comment,omitempty"`
+}
+
+// MuteRequest - ระบุ category_id หรือ insight_type อย่างใดอย่างหนึ่ง
+type MuteRequest struct {
+	CategoryID  *string `json:"category_id,omitempty"`
+	InsightType *string `json:"insight_type,omitempty"`
+}
+
+// GetInsights - inbox ของ insight พร้อม filter และ pagination
+func (h *InsightHandler) GetInsights(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	filter := repository.InsightFilter{
+		UserID:           userID.(uuid.UUID),
+		ValidOnly:        c.Query("include_expired") != "true",
+		IncludeDismissed: c.Query("include_dismissed") == "true",
+		IncludeSnoozed:   c.Query("include_snoozed") == "true",
+		IncludeResolved:  c.Query("include_resolved") == "true",
+		Limit:            20,
+	}
+
+	if insightType := c.Query("type"); insightType != "" {
+		t := entity.InsightType(insightType)
+		filter.Type = &t
+	}
+
+	if priority := c.Query("priority"); priority != "" {
+		p := entity.InsightPriority(priority)
+		if p != entity.InsightPriorityLow && p != entity.InsightPriorityMedium && p != entity.InsightPriorityHigh {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid priority, expected low, medium or high"})
+			return
+		}
+		filter.Priority = &p
+	}
+
+	if isReadStr := c.Query("is_read"); isReadStr != "" {
+		isRead, err := strconv.ParseBool(isReadStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid is_read, expected true or false"})
+			return
+		}
+		filter.IsRead = &isRead
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			filter.Limit = l
+		}
+	}
+
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			filter.Offset = o
+		}
+	}
+
+	insights, total, err := h.insightUsecase.ListInsights(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.insightUsecase.LocalizeInsights(c.Request.Context(), filter.UserID, requestLocale(c), insights)
+
+	responses := make([]*InsightResponse, len(insights))
+	for i, insight := range insights {
+		responses[i] = insightToResponse(insight)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"insights": responses,
+		"total":    total,
+		"limit":    filter.Limit,
+		"offset":   filter.Offset,
+	})
+}
+
+func (h *InsightHandler) GetInsight(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	insightID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid insight ID"})
+		return
+	}
+
+	insight, err := h.insightUsecase.GetInsightByID(c.Request.Context(), userID.(uuid.UUID), insightID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	h.insightUsecase.LocalizeInsights(c.Request.Context(), userID.(uuid.UUID), requestLocale(c), []*entity.Insight{insight})
+
+	c.JSON(http.StatusOK, insightToResponse(insight))
+}
+
+func (h *InsightHandler) GetUnreadCount(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	count, err := h.insightUsecase.GetUnreadCount(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, count)
+}
+
+func (h *InsightHandler) MarkAsRead(c *gin.Context) {
+	h.updateInsight(c, "Insight marked as read", h.insightUsecase.MarkAsRead)
+}
+
+func (h *InsightHandler) MarkAsUnread(c *gin.Context) {
+	h.updateInsight(c, "Insight marked as unread", h.insightUsecase.MarkAsUnread)
+}
+
+func (h *InsightHandler) DismissInsight(c *gin.Context) {
+	h.updateInsight(c, "Insight dismissed", h.insightUsecase.DismissInsight)
+}
+
+func (h *InsightHandler) MarkAllAsRead(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	err := h.insightUsecase.MarkAllAsRead(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All insights marked as read"})
+}
+
+func (h *InsightHandler) SnoozeInsight(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	insightID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid insight ID"})
+		return
+	}
+
+	var req SnoozeInsightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var until time.Time
+	switch {
+	case req.Until != nil:
+		parsed, err := time.Parse(time.RFC3339, *req.Until)
+		if err != nil {
+			parsed, err = time.ParseInLocation("2006-01-02", *req.Until, time.Local)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid until format, expected RFC3339 or YYYY-MM-DD"})
+				return
+			}
+		}
+		until = parsed
+	case req.Hours != nil:
+		until = time.Now().Add(time.Duration(*req.Hours) * time.Hour)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Either until or hours is required"})
+		return
+	}
+
+	err = h.insightUsecase.SnoozeInsight(c.Request.Context(), userID.(uuid.UUID), insightID, until)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Insight snoozed",
+		"snoozed_until": until,
+	})
+}
+
+func (h *InsightHandler) RateInsight(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	insightID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid insight ID"})
+		return
+	}
+
+	var req RateInsightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	feedback, err := h.insightUsecase.RateInsight(c.Request.Context(), userID.(uuid.UUID), insightID, req.Rating, req.Comment)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, feedback)
+}
+
+func (h *InsightHandler) GetMutes(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	mutes, err := h.insightUsecase.GetMutes(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if mutes == nil {
+		mutes = []*entity.InsightMute{}
+	}
+
+	c.JSON(http.StatusOK, mutes)
+}
+
+// CreateMute - หยุดสร้าง insight ของหมวดหมู่หรือประเภทที่ระบุ
+func (h *InsightHandler) CreateMute(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req MuteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var mute *entity.InsightMute
+	var err error
+	switch {
+	case req.CategoryID != nil && req.InsightType != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Specify either category_id or insight_type, not both"})
+		return
+	case req.CategoryID != nil:
+		categoryID, parseErr := uuid.Parse(*req.CategoryID)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+			return
+		}
+		mute, err = h.insightUsecase.MuteCategory(c.Request.Context(), userID.(uuid.UUID), categoryID)
+	case req.InsightType != nil:
+		mute, err = h.insightUsecase.MuteInsightType(c.Request.Context(), userID.(uuid.UUID), entity.InsightType(*req.InsightType))
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Either category_id or insight_type is required"})
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, mute)
+}
+
+func (h *InsightHandler) DeleteMute(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	muteID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mute ID"})
+		return
+	}
+
+	err = h.insightUsecase.Unmute(c.Request.Context(), userID.(uuid.UUID), muteID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Mute removed"})
+}
+
+// PurgeExpiredInsights - ลบ insight ที่หมดอายุแล้ว (สำหรับ scheduled job)
+func (h *InsightHandler) PurgeExpiredInsights(c *gin.Context) {
+	deleted, err := h.insightUsecase.PurgeExpiredInsights(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Expired insights purged successfully",
+		"deleted": deleted,
+	})
+}
+
+func (h *InsightHandler) updateInsight(c *gin.Context, message string, update func(ctx context.Context, userID, insightID uuid.UUID) error) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	insightID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid insight ID"})
+		return
+	}
+
+	err = update(c.Request.Context(), userID.(uuid.UUID), insightID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"crypto/subtle"
 	"net/http"
 	"strings"
 
@@ -40,6 +41,27 @@ func AuthMiddleware(authUsecase usecase.AuthUsecase) gin.HandlerFunc {
 	}
 }
 
+// SystemAuthMiddleware ให้เฉพาะ scheduler ที่ส่ง X-System-Key ตรงกับ apiKey เรียก route ของระบบได้
+// ถ้าไม่ได้ตั้ง apiKey จะปฏิเสธทุก request
+func SystemAuthMiddleware(apiKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if apiKey == "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "System routes are disabled"})
+			c.Abort()
+			return
+		}
+
+		key := c.GetHeader("X-System-Key")
+		if subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid system key"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
@@ -1,6 +1,8 @@
 package http
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
@@ -34,6 +36,10 @@ type CreateRecurringTransactionRequest struct {
 	EndDate             *string `json:"end_date,omitempty"`
 	AutoExecute         bool    `json:"auto_execute"`
 	RemainingExecutions *int    `json:"remaining_executions,omitempty"`
+	AmountMode          string  `json:"amount_mode,omitempty" binding:"omitempty,oneof=fixed average range"`
+	EstimateWindow      *int    `json:"estimate_window,omitempty"`
+	MinAmount           *string `json:"min_amount,omitempty"`
+	MaxAmount           *string `json:"max_amount,omitempty"`
 }
 
 type UpdateRecurringTransactionRequest struct {
@@ -44,6 +50,15 @@ type UpdateRecurringTransactionRequest struct {
 	AutoExecute         *bool   `json:"auto_execute,omitempty"`
 	RemainingExecutions *int    `json:"remaining_executions,omitempty"`
 	IsActive            *bool   `json:"is_active,omitempty"`
+	AmountMode          *string `json:"amount_mode,omitempty" binding:"omitempty,oneof=fixed average range"`
+	EstimateWindow      *int    `json:"estimate_window,omitempty"`
+	MinAmount           *string `json:"min_amount,omitempty"`
+	MaxAmount           *string `json:"max_amount,omitempty"`
+}
+
+// ExecuteRecurringTransactionRequest - ยอดจริงที่ผู้ใช้ยืนยันตอนอนุมัติ (จำเป็นสำหรับกฎที่ยอดไม่คงที่)
+type ExecuteRecurringTransactionRequest struct {
+	Amount *string `json:"amount,omitempty"`
 }
 
 type RecurringTransactionResponse struct {
@@ -62,6 +77,10 @@ type RecurringTransactionResponse struct {
 	IsActive            bool       `json:"is_active"`
 	AutoExecute         bool       `json:"auto_execute"`
 	RemainingExecutions *int       `json:"remaining_executions,omitempty"`
+	AmountMode          string     `json:"amount_mode"`
+	EstimateWindow      *int       `json:"estimate_window,omitempty"`
+	MinAmount           *string    `json:"min_amount,omitempty"`
+	MaxAmount           *string    `json:"max_amount,omitempty"`
 	CreatedAt           time.Time  `json:"created_at"`
 	UpdatedAt           time.Time  `json:"updated_at"`
 }
@@ -120,6 +139,23 @@ func (h *RecurringTransactionHandler) CreateRecurringTransaction(c *gin.Context)
 		endDate = &parsed
 	}
 
+	minAmount, err := parseOptionalDecimal(req.MinAmount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_amount format"})
+		return
+	}
+
+	maxAmount, err := parseOptionalDecimal(req.MaxAmount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max_amount format"})
+		return
+	}
+
+	amountMode := entity.RecurringAmountModeFixed
+	if req.AmountMode != "" {
+		amountMode = entity.RecurringAmountMode(req.AmountMode)
+	}
+
 	// Create recurring transaction entity
 	recurringTx := &entity.RecurringTransaction{
 		ID:                  uuid.New(),
@@ -134,6 +170,10 @@ func (h *RecurringTransactionHandler) CreateRecurringTransaction(c *gin.Context)
 		EndDate:             endDate,
 		AutoExecute:         req.AutoExecute,
 		RemainingExecutions: req.RemainingExecutions,
+		AmountMode:          amountMode,
+		EstimateWindow:      req.EstimateWindow,
+		MinAmount:           minAmount,
+		MaxAmount:           maxAmount,
 		IsActive:            true,
 		CreatedAt:           time.Now(),
 		UpdatedAt:           time.Now(),
@@ -296,6 +336,28 @@ func (h *RecurringTransactionHandler) UpdateRecurringTransaction(c *gin.Context)
 	if req.IsActive != nil {
 		recurringTransaction.IsActive = *req.IsActive
 	}
+	if req.AmountMode != nil {
+		recurringTransaction.AmountMode = entity.RecurringAmountMode(*req.AmountMode)
+	}
+	if req.EstimateWindow != nil {
+		recurringTransaction.EstimateWindow = req.EstimateWindow
+	}
+	if req.MinAmount != nil {
+		minAmount, err := parseOptionalDecimal(req.MinAmount)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_amount format"})
+			return
+		}
+		recurringTransaction.MinAmount = minAmount
+	}
+	if req.MaxAmount != nil {
+		maxAmount, err := parseOptionalDecimal(req.MaxAmount)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max_amount format"})
+			return
+		}
+		recurringTransaction.MaxAmount = maxAmount
+	}
 
 	err = h.recurringUsecase.UpdateRecurringTransaction(c.Request.Context(), userUUID, recurringTransaction)
 	if err != nil {
@@ -358,7 +420,22 @@ func (h *RecurringTransactionHandler) ExecuteRecurringTransaction(c *gin.Context
 		return
 	}
 
-	transaction, err := h.recurringUsecase.ExecuteRecurringTransaction(c.Request.Context(), userUUID, txUUID)
+	// Body is optional for fixed-amount rules
+	var req ExecuteRecurringTransactionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	confirmedAmount, err := parseOptionalDecimal(req.Amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid amount format"})
+		return
+	}
+
+	transaction, err := h.recurringUsecase.ExecuteRecurringTransaction(c.Request.Context(), userUUID, txUUID, confirmedAmount)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -370,6 +447,29 @@ func (h *RecurringTransactionHandler) ExecuteRecurringTransaction(c *gin.Context
 	})
 }
 
+// GetAmountEstimate - ยอดที่คาดการณ์ของครั้งถัดไป ใช้แสดงเป็นค่าเริ่มต้นตอนยืนยันยอดจริง
+func (h *RecurringTransactionHandler) GetAmountEstimate(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	txUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction ID"})
+		return
+	}
+
+	estimate, err := h.recurringUsecase.EstimateAmount(c.Request.Context(), userID.(uuid.UUID), txUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, estimate)
+}
+
 func (h *RecurringTransactionHandler) GetDueTransactions(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -425,6 +525,145 @@ func (h *RecurringTransactionHandler) ProcessAllDueTransactions(c *gin.Context)
 	})
 }
 
+// GetUpcomingCalendar - ขยายกฎ recurring ที่ active เป็นรายการในอนาคตพร้อมยอดเงิน
+func (h *RecurringTransactionHandler) GetUpcomingCalendar(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	from, to, ok := parseCalendarRange(c)
+	if !ok {
+		return
+	}
+
+	occurrences, err := h.recurringUsecase.GetUpcomingOccurrences(c.Request.Context(), userID.(uuid.UUID), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	totalIncome := decimal.Zero
+	totalExpense := decimal.Zero
+	for _, occurrence := range occurrences {
+		if occurrence.Type == entity.TransactionTypeIncome {
+			totalIncome = totalIncome.Add(occurrence.Amount)
+		} else {
+			totalExpense = totalExpense.Add(occurrence.Amount)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":          from.Format("2006-01-02"),
+		"to":            to.Format("2006-01-02"),
+		"occurrences":   occurrences,
+		"total_income":  totalIncome,
+		"total_expense": totalExpense,
+	})
+}
+
+// RotateCalendarFeedToken - สร้าง (หรือเปลี่ยน) token ลับสำหรับ subscribe ปฏิทินบิล
+func (h *RecurringTransactionHandler) RotateCalendarFeedToken(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	token, err := h.recurringUsecase.RotateCalendarFeedToken(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":    token,
+		"feed_url": scheme + "://" + c.Request.Host + "/api/v1/calendar/" + token + "/bills.ics",
+	})
+}
+
+// RevokeCalendarFeedToken - ยกเลิก token ปฏิทินบิล URL ที่เคย subscribe ไว้จะใช้ไม่ได้อีก
+func (h *RecurringTransactionHandler) RevokeCalendarFeedToken(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.recurringUsecase.RevokeCalendarFeedToken(c.Request.Context(), userID.(uuid.UUID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetCalendarFeed - iCalendar feed สาธารณะ (ยืนยันตัวตนด้วย token ใน URL แทน JWT)
+func (h *RecurringTransactionHandler) GetCalendarFeed(c *gin.Context) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	to := from.AddDate(1, 0, 0)
+
+	occurrences, err := h.recurringUsecase.GetCalendarFeedOccurrences(c.Request.Context(), c.Param("token"), from, to)
+	if err != nil {
+		if errors.Is(err, usecase.ErrCalendarFeedNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+			return
+		}
+		// feed เป็น endpoint สาธารณะ จึงไม่ส่งรายละเอียด error กลับไป
+		log.Printf("calendar feed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load calendar feed"})
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(renderICalendar("Savvy - Upcoming bills", occurrences, now)))
+}
+
+// parseCalendarRange อ่าน from/to (YYYY-MM-DD) ค่าเริ่มต้นคือวันนี้ถึงอีก 30 วัน จำกัดไม่เกิน 1 ปี
+func parseCalendarRange(c *gin.Context) (time.Time, time.Time, bool) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from format, expected YYYY-MM-DD"})
+			return time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+
+	to := from.AddDate(0, 0, 30)
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to format, expected YYYY-MM-DD"})
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed
+	}
+	// Include the whole last day
+	to = to.Add(24*time.Hour - time.Nanosecond)
+
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return time.Time{}, time.Time{}, false
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Date range must not exceed one year"})
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to, true
+}
+
 func (h *RecurringTransactionHandler) recurringToResponse(tx *entity.RecurringTransaction) *RecurringTransactionResponse {
 	response := &RecurringTransactionResponse{
 		ID:                  tx.ID.String(),
@@ -441,6 +680,8 @@ func (h *RecurringTransactionHandler) recurringToResponse(tx *entity.RecurringTr
 		IsActive:            tx.IsActive,
 		AutoExecute:         tx.AutoExecute,
 		RemainingExecutions: tx.RemainingExecutions,
+		AmountMode:          string(tx.AmountMode),
+		EstimateWindow:      tx.EstimateWindow,
 		CreatedAt:           tx.CreatedAt,
 		UpdatedAt:           tx.UpdatedAt,
 	}
@@ -449,6 +690,25 @@ func (h *RecurringTransactionHandler) recurringToResponse(tx *entity.RecurringTr
 		endDate := tx.EndDate.Format("2006-01-02")
 		response.EndDate = &endDate
 	}
+	if tx.MinAmount != nil {
+		minAmount := tx.MinAmount.String()
+		response.MinAmount = &minAmount
+	}
+	if tx.MaxAmount != nil {
+		maxAmount := tx.MaxAmount.String()
+		response.MaxAmount = &maxAmount
+	}
 
 	return response
 }
+
+func parseOptionalDecimal(value *string) (*decimal.Decimal, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	parsed, err := decimal.NewFromString(*value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/usecase"
+)
+
+// feedUsecase แทนที่เฉพาะ GetCalendarFeedOccurrences เมธอดอื่นไม่ถูกเรียกในการทดสอบนี้
+type feedUsecase struct {
+	usecase.RecurringTransactionUsecase
+	err error
+}
+
+func (f *feedUsecase) GetCalendarFeedOccurrences(ctx context.Context, token string, from, to time.Time) ([]*entity.RecurringOccurrence, error) {
+	return nil, f.err
+}
+
+func TestGetCalendarFeedStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "feed found", wantStatus: http.StatusOK},
+		{name: "unknown token", err: usecase.ErrCalendarFeedNotFound, wantStatus: http.StatusNotFound},
+		{name: "wrapped not found", err: fmt.Errorf("lookup: %w", usecase.ErrCalendarFeedNotFound), wantStatus: http.StatusNotFound},
+		{name: "database failure", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/calendar/:token/bills.ics", NewRecurringTransactionHandler(&feedUsecase{err: tt.err}).GetCalendarFeed)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/calendar/secret/bills.ics", nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.err != nil && strings.Contains(recorder.Body.String(), tt.err.Error()) {
+				t.Errorf("response leaks the internal error: %s", recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"savvy-backend/internal/domain/service"
+)
+
+// bufferedResponseWriter เก็บ body ไว้ก่อนเพื่อให้คำนวณ ETag และตอบ 304 ได้หลัง handler ทำงานเสร็จ
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedResponseWriter) WriteString(data string) (int, error) {
+	return w.body.WriteString(data)
+}
+
+// ResponseCacheMiddleware cache response ของ GET ที่สำเร็จแยกตามผู้ใช้ พารามิเตอร์ของ query (ช่วงเวลา) และวันที่ปัจจุบัน
+// วันที่อยู่ใน key ด้วยเพราะ endpoint ที่ไม่ระบุช่วงเวลาจะใช้เดือนปัจจุบัน ทุก response มี ETag และตอบ 304 เมื่อ If-None-Match ตรงกัน
+// ถ้า cache ใช้งานไม่ได้จะคำนวณ response ตามปกติ
+func ResponseCacheMiddleware(responseCache service.ResponseCache, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("user_id")
+		if c.Request.Method != http.MethodGet || !exists {
+			c.Next()
+			return
+		}
+		userID := value.(uuid.UUID)
+		ctx := c.Request.Context()
+
+		// อ่าน version ก่อนคำนวณ ถ้ามีการแก้ไขข้อมูลระหว่างนี้ response จะถูกเก็บใต้ version เก่าซึ่งไม่มีใครอ่านอีก
+		version, err := responseCache.UserVersion(ctx, userID)
+		cacheable := err == nil
+		if err != nil {
+			log.Printf("response cache unavailable: %v", err)
+		}
+
+		key := fmt.Sprintf("response:%s:v%d:%s:%s?%s",
+			userID, version, time.Now().Format("2006-01-02"), c.Request.URL.Path, c.Request.URL.Query().Encode())
+
+		if cacheable {
+			body, ok, err := responseCache.Get(ctx, key)
+			if err != nil {
+				log.Printf("response cache get failed: %v", err)
+			}
+			if ok {
+				c.Header("X-Cache", "HIT")
+				writeWithETag(c, body)
+				c.Abort()
+				return
+			}
+		}
+
+		writer := &bufferedResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		body := writer.body.Bytes()
+		if c.Writer.Status() != http.StatusOK {
+			c.Writer.Write(body)
+			return
+		}
+
+		if cacheable {
+			if err := responseCache.Set(ctx, key, body, ttl); err != nil {
+				log.Printf("response cache set failed: %v", err)
+			}
+		}
+
+		c.Header("X-Cache", "MISS")
+		writeWithETag(c, body)
+	}
+}
+
+func writeWithETag(c *gin.Context, body []byte) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	// ให้ client ตรวจกับ server ทุกครั้ง เพราะข้อมูลเปลี่ยนได้ทันทีที่มีรายการใหม่
+	c.Header("Cache-Control", "private, no-cache")
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
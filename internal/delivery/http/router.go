@@ -1,6 +1,9 @@
 package http
 
 import (
+	"time"
+
+	"savvy-backend/internal/domain/service"
 	"savvy-backend/internal/usecase"
 
 	"github.com/gin-gonic/gin"
@@ -15,6 +18,15 @@ func SetupRoutes(
 	budgetUsecase usecase.BudgetUsecase,
 	recurringUsecase usecase.RecurringTransactionUsecase,
 	aiInsightUsecase usecase.AIInsightUsecase,
+	forecastUsecase usecase.ForecastUsecase,
+	insightUsecase usecase.InsightUsecase,
+	anomalyUsecase usecase.TransactionAnomalyUsecase,
+	userUsecase usecase.UserUsecase,
+	analyticsUsecase usecase.AnalyticsUsecase,
+	netWorthUsecase usecase.NetWorthUsecase,
+	responseCache service.ResponseCache,
+	responseCacheTTL time.Duration,
+	systemAPIKey string,
 ) *gin.Engine {
 	r := gin.Default()
 
@@ -38,10 +50,25 @@ func SetupRoutes(
 		auth.POST("/refresh", authHandler.RefreshToken)
 	}
 
+	// Public iCalendar feed (authenticated by the secret token in the URL)
+	calendarHandler := NewRecurringTransactionHandler(recurringUsecase)
+	api.GET("/calendar/:token/bills.ics", calendarHandler.GetCalendarFeed)
+
 	// Protected routes
 	protected := api.Group("/")
 	protected.Use(AuthMiddleware(authUsecase))
 	{
+		// Cached read-heavy routes (invalidated per user on writes)
+		cached := ResponseCacheMiddleware(responseCache, responseCacheTTL)
+
+		// User preference routes
+		userHandler := NewUserHandler(userUsecase)
+		users := protected.Group("/users")
+		{
+			users.GET("/me/preferences", userHandler.GetPreferences)
+			users.PUT("/me/preferences", userHandler.UpdatePreferences)
+		}
+
 		// Account routes
 		accountHandler := NewAccountHandler(accountUsecase)
 		accounts := protected.Group("/accounts")
@@ -58,6 +85,7 @@ func SetupRoutes(
 		{
 			transactions.POST("/", transactionHandler.CreateTransaction)
 			transactions.GET("/", transactionHandler.GetTransactions)
+			transactions.POST("/recategorize", transactionHandler.RecategorizeTransactions)
 			transactions.GET("/:id", transactionHandler.GetTransaction)
 			transactions.PUT("/:id", transactionHandler.UpdateTransaction)
 			transactions.DELETE("/:id", transactionHandler.DeleteTransaction)
@@ -70,28 +98,45 @@ func SetupRoutes(
 			categories.POST("/", categoryHandler.CreateCategory)
 			categories.GET("/", categoryHandler.GetCategories)
 			categories.GET("/user", categoryHandler.GetUserCategories)
+			categories.GET("/tree", categoryHandler.GetCategoryTree)
+			categories.GET("/usage", categoryHandler.GetCategoryUsage)
+			categories.GET("/overrides", categoryHandler.GetCategoryOverrides)
+			categories.GET("/:id", categoryHandler.GetCategory)
+			categories.PUT("/:id", categoryHandler.UpdateCategory)
+			categories.DELETE("/:id", categoryHandler.DeleteCategory)
 			categories.PUT("/:id/archive", categoryHandler.ArchiveCategory)
+			categories.PUT("/:id/unarchive", categoryHandler.UnarchiveCategory)
+			categories.PUT("/:id/override", categoryHandler.SetCategoryOverride)
+			categories.DELETE("/:id/override", categoryHandler.ResetCategoryOverride)
+			categories.POST("/:id/merge-into/:target", categoryHandler.MergeCategory)
 		}
 
 		// Dashboard routes
 		dashboardHandler := NewDashboardHandler(dashboardUsecase)
-		dashboard := protected.Group("/dashboard")
+		dashboard := protected.Group("/dashboard", cached)
 		{
 			dashboard.GET("/", dashboardHandler.GetDashboard)
 			dashboard.GET("/summary", dashboardHandler.GetCurrentMonthlySummary)
 			dashboard.GET("/summary/monthly", dashboardHandler.GetMonthlySummary)
 			dashboard.GET("/transactions/recent", dashboardHandler.GetRecentTransactions)
 			dashboard.GET("/spending/category", dashboardHandler.GetSpendingByCategory)
+			dashboard.GET("/health", dashboardHandler.GetFinancialHealth)
 		}
 
 		// Analytics routes for Data Visualization
-		analyticsHandler := NewAnalyticsHandler(dashboardUsecase, transactionUsecase, categoryUsecase)
-		analytics := protected.Group("/analytics")
+		analyticsHandler := NewAnalyticsHandler(dashboardUsecase, transactionUsecase, categoryUsecase, analyticsUsecase, netWorthUsecase)
+		analytics := protected.Group("/analytics", cached)
 		{
 			analytics.GET("/pie/expenses", analyticsHandler.GetExpensePieChart)
 			analytics.GET("/bar/income-expense", analyticsHandler.GetIncomeExpenseBarChart)
 			analytics.GET("/trend/category/:category_id", analyticsHandler.GetCategoryTrendChart)
 			analytics.GET("/top/categories", analyticsHandler.GetTopCategoriesChart)
+			analytics.GET("/compare", analyticsHandler.ComparePeriods)
+			analytics.GET("/timeseries", analyticsHandler.GetTimeSeries)
+			analytics.GET("/calendar", analyticsHandler.GetSpendingCalendar)
+			analytics.GET("/heatmap", analyticsHandler.GetSpendingHeatmap)
+			analytics.GET("/sankey", analyticsHandler.GetSankeyFlow)
+			analytics.GET("/net-worth", analyticsHandler.GetNetWorth)
 		}
 
 		// Budget routes
@@ -103,8 +148,8 @@ func SetupRoutes(
 			budgets.GET("/:id", budgetHandler.GetBudget)
 			budgets.PUT("/:id", budgetHandler.UpdateBudget)
 			budgets.DELETE("/:id", budgetHandler.DeleteBudget)
-			budgets.GET("/progress", budgetHandler.GetBudgetProgress)
-			budgets.GET("/progress/current", budgetHandler.GetCurrentMonthProgress)
+			budgets.GET("/progress", cached, budgetHandler.GetBudgetProgress)
+			budgets.GET("/progress/current", cached, budgetHandler.GetCurrentMonthProgress)
 			budgets.POST("/alerts/check", budgetHandler.CheckBudgetAlerts)
 		}
 
@@ -118,7 +163,46 @@ func SetupRoutes(
 			recurring.PUT("/:id", recurringHandler.UpdateRecurringTransaction)
 			recurring.DELETE("/:id", recurringHandler.DeleteRecurringTransaction)
 			recurring.POST("/:id/execute", recurringHandler.ExecuteRecurringTransaction)
+			recurring.GET("/:id/estimate", recurringHandler.GetAmountEstimate)
 			recurring.GET("/due", recurringHandler.GetDueTransactions)
+			recurring.GET("/calendar", recurringHandler.GetUpcomingCalendar)
+			recurring.POST("/calendar/feed-token", recurringHandler.RotateCalendarFeedToken)
+			recurring.DELETE("/calendar/feed-token", recurringHandler.RevokeCalendarFeedToken)
+		}
+
+		// Forecast routes
+		forecastHandler := NewForecastHandler(forecastUsecase)
+		forecast := protected.Group("/forecast")
+		{
+			forecast.GET("/cashflow", forecastHandler.GetCashFlowForecast)
+			forecast.POST("/cashflow/alerts/check", forecastHandler.CheckCashFlowWarnings)
+		}
+
+		// Insight inbox routes
+		insightHandler := NewInsightHandler(insightUsecase)
+		insights := protected.Group("/insights")
+		{
+			insights.GET("/", insightHandler.GetInsights)
+			insights.GET("/unread-count", insightHandler.GetUnreadCount)
+			insights.PUT("/read-all", insightHandler.MarkAllAsRead)
+			insights.GET("/mutes", insightHandler.GetMutes)
+			insights.POST("/mutes", insightHandler.CreateMute)
+			insights.DELETE("/mutes/:id", insightHandler.DeleteMute)
+			insights.GET("/:id", insightHandler.GetInsight)
+			insights.PUT("/:id/read", insightHandler.MarkAsRead)
+			insights.PUT("/:id/unread", insightHandler.MarkAsUnread)
+			insights.PUT("/:id/dismiss", insightHandler.DismissInsight)
+			insights.PUT("/:id/snooze", insightHandler.SnoozeInsight)
+			insights.POST("/:id/feedback", insightHandler.RateInsight)
+		}
+
+		// Per-transaction anomaly review routes
+		anomalyHandler := NewAnomalyHandler(anomalyUsecase)
+		anomalies := protected.Group("/anomalies")
+		{
+			anomalies.GET("/", anomalyHandler.GetAnomalies)
+			anomalies.GET("/:id", anomalyHandler.GetAnomaly)
+			anomalies.PUT("/:id/review", anomalyHandler.ReviewAnomaly)
 		}
 
 		// AI Insights routes
@@ -130,13 +214,16 @@ func SetupRoutes(
 			aiInsights.POST("/spending-patterns/generate", aiInsightHandler.GenerateSpendingPatternInsights)
 			aiInsights.POST("/category-recommendations/generate", aiInsightHandler.GenerateCategoryRecommendations)
 			aiInsights.POST("/savings-recommendations/generate", aiInsightHandler.GenerateSavingsRecommendations)
+			aiInsights.POST("/subscriptions/detect", aiInsightHandler.DetectSubscriptions)
+			aiInsights.POST("/spending-patterns/analyze", aiInsightHandler.AnalyzeSpendingPatternTrends)
 
 			// Process insights
 			aiInsights.POST("/weekly/process", aiInsightHandler.ProcessWeeklyInsights)
 
-			// Get processed insights (placeholder endpoints)
+			// Raw analysis results
 			aiInsights.GET("/spending-anomalies", aiInsightHandler.GetSpendingAnomalies)
 			aiInsights.GET("/spending-patterns", aiInsightHandler.GetSpendingPatterns)
+			aiInsights.GET("/spending-patterns/trends", aiInsightHandler.GetSpendingPatternTrends)
 		}
 	}
 
@@ -147,15 +234,21 @@ func SetupRoutes(
 		setup.POST("/categories/default", categoryHandler.InitializeDefaultCategories)
 	}
 
-	// System routes (for background processing)
+	// System routes (for background processing, called by a scheduler with X-System-Key)
 	system := api.Group("/system")
+	system.Use(SystemAuthMiddleware(systemAPIKey))
 	{
-		// These should be protected with admin authentication in production
 		recurringHandler := NewRecurringTransactionHandler(recurringUsecase)
 		system.POST("/recurring-transactions/process-all", recurringHandler.ProcessAllDueTransactions)
 
 		aiInsightHandler := NewAIInsightHandler(aiInsightUsecase)
 		system.POST("/ai-insights/process-all", aiInsightHandler.ProcessAllUsersInsights)
+
+		insightHandler := NewInsightHandler(insightUsecase)
+		system.POST("/insights/purge-expired", insightHandler.PurgeExpiredInsights)
+
+		analyticsHandler := NewAnalyticsHandler(dashboardUsecase, transactionUsecase, categoryUsecase, analyticsUsecase, netWorthUsecase)
+		system.POST("/net-worth/snapshot", analyticsHandler.RecordNetWorthSnapshots)
 	}
 
 	return r
@@ -12,6 +12,7 @@ import (
 	"savvy-backend/internal/domain/entity"
 	"savvy-backend/internal/domain/repository"
 	"savvy-backend/internal/usecase"
+	"savvy-backend/pkg/utils"
 )
 
 type TransactionHandler struct {
@@ -25,6 +26,24 @@ type CreateTransactionRequest struct {
 	Type            string  `json:"type" binding:"required"`
 	Note            *string `json:"note,omitempty"`
 	TransactionDate string  `json:"transaction_date" binding:"required"`
+	TransactionTime *string `json:"transaction_time,omitempty"` // HH:MM
+}
+
+// RecategorizeTransactionsRequest ย้ายทุกรายการที่ตรงกับ filter (เงื่อนไขเดียวกับ GET /transactions) ไป category_id
+type RecategorizeTransactionsRequest struct {
+	CategoryID string                    `json:"category_id" binding:"required"`
+	Filter     RecategorizeFilterRequest `json:"filter"`
+}
+
+type RecategorizeFilterRequest struct {
+	AccountID  *string `json:"account_id,omitempty"`
+	CategoryID *string `json:"category_id,omitempty"`
+	Type       *string `json:"type,omitempty"`
+	StartDate  *string `json:"start_date,omitempty"`
+	EndDate    *string `json:"end_date,omitempty"`
+	Search     *string `json:"search,omitempty"`
+	MinAmount  *string `json:"min_amount,omitempty"`
+	MaxAmount  *string `json:"max_amount,omitempty"`
 }
 
 func NewTransactionHandler(transactionUsecase usecase.TransactionUsecase) *TransactionHandler {
@@ -84,6 +103,7 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 		transactionType,
 		req.Note,
 		req.TransactionDate,
+		req.TransactionTime,
 	)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -286,6 +306,16 @@ func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
 		return
 	}
 
+	var transactionTime *string
+	if req.TransactionTime != nil {
+		clock, err := utils.ParseClockTime(*req.TransactionTime)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction time format, expected HH:MM"})
+			return
+		}
+		transactionTime = &clock
+	}
+
 	// Update transaction fields
 	transaction.CategoryID = categoryID
 	transaction.AccountID = accountID
@@ -293,6 +323,7 @@ func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
 	transaction.Type = transactionType
 	transaction.Note = req.Note
 	transaction.TransactionDate = parsedDate
+	transaction.TransactionTime = transactionTime
 
 	err = h.transactionUsecase.UpdateTransaction(c.Request.Context(), userID.(uuid.UUID), transaction)
 	if err != nil {
@@ -324,3 +355,109 @@ func (h *TransactionHandler) DeleteTransaction(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Transaction deleted successfully"})
 }
+
+// RecategorizeTransactions - ย้ายหลายรายการไปหมวดหมู่ใหม่ในครั้งเดียว ต่างจาก GET /transactions ตรงที่ค่า filter ที่ผิดรูปแบบจะถูกปฏิเสธ
+func (h *TransactionHandler) RecategorizeTransactions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req RecategorizeTransactionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	categoryID, err := uuid.Parse(req.CategoryID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	filter := repository.TransactionFilter{UserID: userID.(uuid.UUID)}
+
+	if req.Filter.AccountID != nil {
+		accountID, err := uuid.Parse(*req.Filter.AccountID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter account_id"})
+			return
+		}
+		filter.AccountID = &accountID
+	}
+
+	if req.Filter.CategoryID != nil {
+		fromCategoryID, err := uuid.Parse(*req.Filter.CategoryID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter category_id"})
+			return
+		}
+		filter.CategoryID = &fromCategoryID
+	}
+
+	if req.Filter.Type != nil {
+		switch *req.Filter.Type {
+		case "income":
+			transactionType := entity.TransactionTypeIncome
+			filter.Type = &transactionType
+		case "expense":
+			transactionType := entity.TransactionTypeExpense
+			filter.Type = &transactionType
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter type, expected income or expense"})
+			return
+		}
+	}
+
+	if req.Filter.StartDate != nil {
+		startDate, err := utils.ParseDate(*req.Filter.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter start_date, expected YYYY-MM-DD"})
+			return
+		}
+		filter.StartDate = &startDate
+	}
+
+	if req.Filter.EndDate != nil {
+		endDate, err := utils.ParseDate(*req.Filter.EndDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter end_date, expected YYYY-MM-DD"})
+			return
+		}
+		filter.EndDate = &endDate
+	}
+
+	if req.Filter.Search != nil && *req.Filter.Search != "" {
+		filter.SearchQuery = req.Filter.Search
+	}
+
+	if req.Filter.MinAmount != nil {
+		minAmount, err := decimal.NewFromString(*req.Filter.MinAmount)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter min_amount"})
+			return
+		}
+		filter.MinAmount = &minAmount
+	}
+
+	if req.Filter.MaxAmount != nil {
+		maxAmount, err := decimal.NewFromString(*req.Filter.MaxAmount)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter max_amount"})
+			return
+		}
+		filter.MaxAmount = &maxAmount
+	}
+
+	updated, err := h.transactionUsecase.RecategorizeTransactions(c.Request.Context(), filter, categoryID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Transactions re-categorized successfully",
+		"updated": updated,
+	})
+}
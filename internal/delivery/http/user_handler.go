@@ -0,0 +1,93 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/i18n"
+	"savvy-backend/internal/usecase"
+)
+
+type UserHandler struct {
+	userUsecase usecase.UserUsecase
+}
+
+func NewUserHandler(userUsecase usecase.UserUsecase) *UserHandler {
+	return &UserHandler{
+		userUsecase: userUsecase,
+	}
+}
+
+type UpdatePreferencesRequest struct {
+	Locale   *string `json:"locale,omitempty"`
+	Currency *string `json:"currency,omitempty"`
+}
+
+type PreferencesResponse struct {
+	Locale           string   `json:"locale"`
+	Currency         string   `json:"currency"`
+	SupportedLocales []string `json:"supported_locales"`
+}
+
+func (h *UserHandler) GetPreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	user, err := h.userUsecase.GetPreferences(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferencesToResponse(user))
+}
+
+func (h *UserHandler) UpdatePreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Locale == nil && req.Currency == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide locale or currency"})
+		return
+	}
+
+	user, err := h.userUsecase.UpdatePreferences(c.Request.Context(), userID.(uuid.UUID), req.Locale, req.Currency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferencesToResponse(user))
+}
+
+func preferencesToResponse(user *entity.User) *PreferencesResponse {
+	return &PreferencesResponse{
+		Locale:           user.Locale,
+		Currency:         user.CurrencyPreference,
+		SupportedLocales: i18n.SupportedLocales,
+	}
+}
+
+// requestLocale เลือกภาษาจาก ?locale= ก่อน แล้วจึงใช้ Accept-Language
+// คืนค่าว่างเมื่อไม่ได้ระบุ เพื่อให้ usecase ใช้ภาษาที่ผู้ใช้ตั้งไว้
+func requestLocale(c *gin.Context) string {
+	if locale := i18n.NormalizeLocale(c.Query("locale")); locale != "" {
+		return locale
+	}
+	return i18n.LocaleFromAcceptLanguage(c.GetHeader("Accept-Language"))
+}
@@ -14,8 +14,14 @@ const (
 	AccountTypeBank    AccountType = "bank"
 	AccountTypeCredit  AccountType = "credit"
 	AccountTypeSavings AccountType = "savings"
+	AccountTypeLoan    AccountType = "loan"
 )
 
+// IsLiability บอกว่าบัญชีประเภทนี้เป็นหนี้สิน ยอดคงเหลือของบัญชีหนี้สินปกติจะติดลบ (ยอดที่ค้างชำระ)
+func (t AccountType) IsLiability() bool {
+	return t == AccountTypeCredit || t == AccountTypeLoan
+}
+
 type Account struct {
 	ID             uuid.UUID       `json:"id" db:"id"`
 	UserID         uuid.UUID       `json:"user_id" db:"user_id"`
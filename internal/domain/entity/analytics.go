@@ -0,0 +1,266 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// DateRange คือช่วงวันที่แบบรวมวันสุดท้าย (inclusive)
+type DateRange struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+// Days คือจำนวนวันในช่วง
+func (r DateRange) Days() int {
+	return int(r.EndDate.Sub(r.StartDate).Hours()/24) + 1
+}
+
+// IsWholeMonths คือช่วงที่เริ่มวันที่ 1 และจบวันสุดท้ายของเดือน ใช้เลื่อนช่วงตามเดือนแทนจำนวนวัน
+func (r DateRange) IsWholeMonths() bool {
+	return r.StartDate.Day() == 1 && r.EndDate.AddDate(0, 0, 1).Day() == 1
+}
+
+// Months คือจำนวนเดือนในช่วงที่เป็นเดือนเต็ม
+func (r DateRange) Months() int {
+	return (r.EndDate.Year()-r.StartDate.Year())*12 + int(r.EndDate.Month()-r.StartDate.Month()) + 1
+}
+
+// CategoryTotal คือยอดรวมของหมวดหมู่หนึ่งในช่วงเวลา
+type CategoryTotal struct {
+	CategoryID   uuid.UUID       `json:"category_id"`
+	CategoryName string          `json:"category_name"`
+	IconName     *string         `json:"icon_name,omitempty"`
+	ColorHex     *string         `json:"color_hex,omitempty"`
+	Total        decimal.Decimal `json:"total"`
+	Count        int             `json:"count"`
+}
+
+// MonthlyTotal คือยอดรายรับ/รายจ่ายรวมของหนึ่งเดือน (Month คือวันแรกของเดือน)
+type MonthlyTotal struct {
+	Month   time.Time       `json:"month"`
+	Income  decimal.Decimal `json:"income"`
+	Expense decimal.Decimal `json:"expense"`
+}
+
+type ComparisonBaseline string
+
+const (
+	ComparisonBaselinePreviousPeriod  ComparisonBaseline = "previous_period"
+	ComparisonBaselinePreviousYear    ComparisonBaseline = "previous_year"
+	ComparisonBaselineTrailingAverage ComparisonBaseline = "trailing_average"
+	ComparisonBaselineCustom          ComparisonBaseline = "custom"
+)
+
+// CategoryDelta คือการเปลี่ยนแปลงของหมวดหมู่หนึ่งเทียบกับช่วงฐาน
+// ChangePercentage เป็น nil เมื่อช่วงฐานเป็น 0 (หมวดหมู่ใหม่)
+type CategoryDelta struct {
+	CategoryID       uuid.UUID       `json:"category_id"`
+	CategoryName     string          `json:"category_name"`
+	Current          decimal.Decimal `json:"current"`
+	Baseline         decimal.Decimal `json:"baseline"`
+	Change           decimal.Decimal `json:"change"`
+	ChangePercentage *float64        `json:"change_percentage"`
+}
+
+// PeriodComparison คือผลการเปรียบเทียบช่วงเวลาปัจจุบันกับช่วงฐาน
+// สำหรับ trailing_average ยอดของช่วงฐานคือค่าเฉลี่ยต่อช่วง และ BaselineRange ครอบคลุมทุกช่วงที่นำมาเฉลี่ย
+type PeriodComparison struct {
+	Type             TransactionType    `json:"type"`
+	Baseline         ComparisonBaseline `json:"baseline"`
+	BaselinePeriods  int                `json:"baseline_periods"`
+	CurrentRange     DateRange          `json:"current_range"`
+	BaselineRange    DateRange          `json:"baseline_range"`
+	CurrentTotal     decimal.Decimal    `json:"current_total"`
+	BaselineTotal    decimal.Decimal    `json:"baseline_total"`
+	Change           decimal.Decimal    `json:"change"`
+	ChangePercentage *float64           `json:"change_percentage"`
+	Categories       []*CategoryDelta   `json:"categories"`
+	TopIncreases     []*CategoryDelta   `json:"top_increases"`
+	TopDecreases     []*CategoryDelta   `json:"top_decreases"`
+}
+
+type TimeSeriesGranularity string
+
+const (
+	GranularityDay     TimeSeriesGranularity = "day"
+	GranularityWeek    TimeSeriesGranularity = "week"
+	GranularityMonth   TimeSeriesGranularity = "month"
+	GranularityQuarter TimeSeriesGranularity = "quarter"
+	GranularityYear    TimeSeriesGranularity = "year"
+)
+
+func (g TimeSeriesGranularity) IsValid() bool {
+	switch g {
+	case GranularityDay, GranularityWeek, GranularityMonth, GranularityQuarter, GranularityYear:
+		return true
+	}
+	return false
+}
+
+// Truncate ปัดวันที่ลงเป็นวันแรกของ bucket ให้ตรงกับ DATE_TRUNC ของ PostgreSQL (สัปดาห์เริ่มวันจันทร์)
+func (g TimeSeriesGranularity) Truncate(t time.Time) time.Time {
+	t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch g {
+	case GranularityWeek:
+		return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+	case GranularityMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case GranularityQuarter:
+		return time.Date(t.Year(), (t.Month()-1)/3*3+1, 1, 0, 0, 0, 0, time.UTC)
+	case GranularityYear:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return t
+	}
+}
+
+// Next คือวันแรกของ bucket ถัดไป
+func (g TimeSeriesGranularity) Next(t time.Time) time.Time {
+	switch g {
+	case GranularityWeek:
+		return t.AddDate(0, 0, 7)
+	case GranularityMonth:
+		return t.AddDate(0, 1, 0)
+	case GranularityQuarter:
+		return t.AddDate(0, 3, 0)
+	case GranularityYear:
+		return t.AddDate(1, 0, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+type TimeSeriesGroupBy string
+
+const (
+	GroupByNone     TimeSeriesGroupBy = "none"
+	GroupByCategory TimeSeriesGroupBy = "category"
+	GroupByAccount  TimeSeriesGroupBy = "account"
+	GroupByType     TimeSeriesGroupBy = "type"
+)
+
+// TimeSeriesBucket คือยอดรวมของหนึ่ง bucket ของหนึ่งกลุ่มที่ได้จาก SQL
+type TimeSeriesBucket struct {
+	PeriodStart time.Time       `json:"period_start"`
+	GroupKey    string          `json:"group_key"`
+	GroupLabel  string          `json:"group_label"`
+	Total       decimal.Decimal `json:"total"`
+	Count       int             `json:"count"`
+}
+
+type TimeSeriesPoint struct {
+	PeriodStart time.Time       `json:"period_start"`
+	Total       decimal.Decimal `json:"total"`
+	Count       int             `json:"count"`
+}
+
+// TimeSeriesGroup คือเส้นหนึ่งเส้นในกราฟ bucket ที่ไม่มีรายการจะมียอดเป็น 0
+type TimeSeriesGroup struct {
+	Key    string             `json:"key"`
+	Label  string             `json:"label"`
+	Total  decimal.Decimal    `json:"total"`
+	Points []*TimeSeriesPoint `json:"points"`
+}
+
+type TimeSeries struct {
+	Granularity TimeSeriesGranularity `json:"granularity"`
+	GroupBy     TimeSeriesGroupBy     `json:"group_by"`
+	Type        *TransactionType      `json:"type,omitempty"`
+	StartDate   time.Time             `json:"start_date"`
+	EndDate     time.Time             `json:"end_date"`
+	Buckets     []time.Time           `json:"buckets"`
+	Groups      []*TimeSeriesGroup    `json:"groups"`
+}
+
+// CalendarDay คือยอดรายจ่ายของหนึ่งวันในปฏิทิน TopCategory เป็น nil เมื่อวันนั้นไม่มีรายจ่าย
+type CalendarDay struct {
+	Date        time.Time       `json:"date"`
+	Total       decimal.Decimal `json:"total"`
+	Count       int             `json:"count"`
+	TopCategory *CategoryTotal  `json:"top_category"`
+}
+
+// SpendingCalendar คือรายจ่ายรายวันของหนึ่งเดือน มีครบทุกวันของเดือน (วันที่ไม่มีรายการยอดเป็น 0)
+type SpendingCalendar struct {
+	Year     int             `json:"year"`
+	Month    int             `json:"month"`
+	Total    decimal.Decimal `json:"total"`
+	Count    int             `json:"count"`
+	MaxDaily decimal.Decimal `json:"max_daily"`
+	Days     []*CalendarDay  `json:"days"`
+}
+
+// HeatmapCell คือรายจ่ายของหนึ่งช่อง วันในสัปดาห์ × ชั่วโมง
+// Weekday เป็นแบบ ISO (1 = จันทร์ ... 7 = อาทิตย์) ตรงกับสัปดาห์ที่เริ่มวันจันทร์ของ time series
+// Intensity คือ Total เทียบกับช่องที่มียอดสูงสุด (0-1)
+type HeatmapCell struct {
+	Weekday   int             `json:"weekday"`
+	Hour      int             `json:"hour"`
+	Total     decimal.Decimal `json:"total"`
+	Count     int             `json:"count"`
+	Intensity float64         `json:"intensity"`
+}
+
+// SpendingHeatmap คือรายจ่ายแยกตามวันในสัปดาห์และชั่วโมงในช่วงวันที่ มีครบ 7 × 24 ช่อง
+// รายการที่ไม่ได้ระบุเวลาไม่อยู่ในช่องใด แต่นับรวมไว้ใน UntimedTotal/UntimedCount
+type SpendingHeatmap struct {
+	StartDate    time.Time       `json:"start_date"`
+	EndDate      time.Time       `json:"end_date"`
+	Total        decimal.Decimal `json:"total"`
+	Count        int             `json:"count"`
+	MaxCell      decimal.Decimal `json:"max_cell"`
+	UntimedTotal decimal.Decimal `json:"untimed_total"`
+	UntimedCount int             `json:"untimed_count"`
+	Cells        []*HeatmapCell  `json:"cells"`
+}
+
+// AccountCategoryTotal คือยอดรวมของหมวดหมู่หนึ่งในบัญชีหนึ่ง ใช้สร้างกราฟ Sankey
+type AccountCategoryTotal struct {
+	AccountID    uuid.UUID       `json:"account_id"`
+	AccountName  string          `json:"account_name"`
+	CategoryID   uuid.UUID       `json:"category_id"`
+	CategoryName string          `json:"category_name"`
+	ColorHex     *string         `json:"color_hex,omitempty"`
+	Type         TransactionType `json:"type"`
+	Total        decimal.Decimal `json:"total"`
+}
+
+type SankeyNodeKind string
+
+const (
+	SankeyNodeIncomeCategory  SankeyNodeKind = "income_category"
+	SankeyNodeAccount         SankeyNodeKind = "account"
+	SankeyNodeExpenseCategory SankeyNodeKind = "expense_category"
+	SankeyNodeSavings         SankeyNodeKind = "savings"
+	SankeyNodeBalance         SankeyNodeKind = "balance"
+)
+
+// SankeyNode คือโหนดหนึ่งในกราฟ Value คือยอดที่ไหลผ่านโหนด (ค่ามากกว่าระหว่างขาเข้าและขาออก)
+type SankeyNode struct {
+	ID       string          `json:"id"`
+	Label    string          `json:"label"`
+	Kind     SankeyNodeKind  `json:"kind"`
+	ColorHex *string         `json:"color_hex,omitempty"`
+	Value    decimal.Decimal `json:"value"`
+}
+
+type SankeyLink struct {
+	Source string          `json:"source"`
+	Target string          `json:"target"`
+	Value  decimal.Decimal `json:"value"`
+}
+
+// SankeyFlow คือกระแสเงิน หมวดหมู่รายรับ → บัญชี → หมวดหมู่รายจ่าย ในช่วงวันที่
+// รายรับที่เหลือของแต่ละบัญชีไหลไปที่โหนด savings ส่วนรายจ่ายที่เกินรายรับของบัญชีมาจากโหนด balance (ยอดเงินเดิม)
+type SankeyFlow struct {
+	StartDate    time.Time       `json:"start_date"`
+	EndDate      time.Time       `json:"end_date"`
+	TotalIncome  decimal.Decimal `json:"total_income"`
+	TotalExpense decimal.Decimal `json:"total_expense"`
+	NetSavings   decimal.Decimal `json:"net_savings"`
+	Nodes        []*SankeyNode   `json:"nodes"`
+	Links        []*SankeyLink   `json:"links"`
+}
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGranularityTruncate(t *testing.T) {
+	ict := time.FixedZone("ICT", 7*60*60)
+
+	tests := []struct {
+		name        string
+		granularity TimeSeriesGranularity
+		input       time.Time
+		want        time.Time
+	}{
+		{name: "day drops time", granularity: GranularityDay, input: time.Date(2026, time.October, 18, 15, 4, 5, 0, time.UTC), want: testDate(2026, time.October, 18)},
+		{name: "day keeps local calendar date", granularity: GranularityDay, input: time.Date(2026, time.October, 18, 23, 30, 0, 0, ict), want: testDate(2026, time.October, 18)},
+		{name: "week from sunday", granularity: GranularityWeek, input: testDate(2026, time.October, 18), want: testDate(2026, time.October, 12)},
+		{name: "week from monday", granularity: GranularityWeek, input: testDate(2026, time.October, 12), want: testDate(2026, time.October, 12)},
+		{name: "week from saturday", granularity: GranularityWeek, input: testDate(2026, time.October, 17), want: testDate(2026, time.October, 12)},
+		{name: "week across year", granularity: GranularityWeek, input: testDate(2025, time.January, 1), want: testDate(2024, time.December, 30)},
+		{name: "month", granularity: GranularityMonth, input: time.Date(2026, time.October, 31, 12, 0, 0, 0, time.UTC), want: testDate(2026, time.October, 1)},
+		{name: "first quarter", granularity: GranularityQuarter, input: testDate(2026, time.February, 14), want: testDate(2026, time.January, 1)},
+		{name: "end of second quarter", granularity: GranularityQuarter, input: testDate(2026, time.June, 30), want: testDate(2026, time.April, 1)},
+		{name: "start of third quarter", granularity: GranularityQuarter, input: testDate(2026, time.July, 1), want: testDate(2026, time.July, 1)},
+		{name: "fourth quarter", granularity: GranularityQuarter, input: testDate(2026, time.December, 31), want: testDate(2026, time.October, 1)},
+		{name: "year", granularity: GranularityYear, input: testDate(2026, time.October, 18), want: testDate(2026, time.January, 1)},
+		{name: "unknown granularity behaves like day", granularity: TimeSeriesGranularity("hour"), input: time.Date(2026, time.October, 18, 9, 0, 0, 0, time.UTC), want: testDate(2026, time.October, 18)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.granularity.Truncate(tt.input)
+			if !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Fatalf("Truncate(%s) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGranularityNextStaysAligned(t *testing.T) {
+	start := testDate(2026, time.October, 18)
+
+	for _, granularity := range []TimeSeriesGranularity{GranularityDay, GranularityWeek, GranularityMonth, GranularityQuarter, GranularityYear} {
+		t.Run(string(granularity), func(t *testing.T) {
+			bucket := granularity.Truncate(start)
+			for i := 0; i < 8; i++ {
+				next := granularity.Next(bucket)
+				if !next.After(bucket) {
+					t.Fatalf("Next(%s) = %s, want a later bucket", bucket, next)
+				}
+				if truncated := granularity.Truncate(next); !truncated.Equal(next) {
+					t.Fatalf("Next(%s) = %s is not a bucket start (Truncate = %s)", bucket, next, truncated)
+				}
+				if truncated := granularity.Truncate(next.AddDate(0, 0, -1)); !truncated.Equal(bucket) {
+					t.Fatalf("day before %s truncates to %s, want %s", next, truncated, bucket)
+				}
+				bucket = next
+			}
+		})
+	}
+}
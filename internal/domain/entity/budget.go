@@ -30,13 +30,18 @@ type Budget struct {
 type BudgetProgress struct {
 	BudgetID           uuid.UUID       `json:"budget_id"`
 	Budget             *Budget         `json:"budget"`
+	CategoryID         uuid.UUID       `json:"category_id"`
 	CategoryName       string          `json:"category_name"`
 	BudgetAmount       decimal.Decimal `json:"budget_amount"`
 	SpentAmount        decimal.Decimal `json:"spent_amount"`
 	RemainingAmount    decimal.Decimal `json:"remaining_amount"`
 	ProgressPercentage float64         `json:"progress_percentage"`
 	IsOverBudget       bool            `json:"is_over_budget"`
-	Period             string          `json:"period"` // e.g., "2024-08" for August 2024
+	// ยอดรายจ่ายประจำที่ยังไม่ถึงกำหนดในเดือนนี้ (ใช้ยอดประมาณสำหรับรายการที่ยอดไม่คงที่)
+	UpcomingRecurringAmount decimal.Decimal `json:"upcoming_recurring_amount"`
+	ProjectedAmount         decimal.Decimal `json:"projected_amount"` // spent + upcoming recurring
+	IsProjectedOverBudget   bool            `json:"is_projected_over_budget"`
+	Period                  string          `json:"period"` // e.g., "2024-08" for August 2024
 }
 
 func NewBudget(userID, categoryID uuid.UUID, amount decimal.Decimal, period BudgetPeriod, startDate time.Time) *Budget {
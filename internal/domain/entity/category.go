@@ -15,14 +15,18 @@ const (
 
 type Category struct {
 	ID         uuid.UUID    `json:"id" db:"id"`
-	UserID     *uuid.UUID   `json:"user_id,omitempty" db:"user_id"` // nullable for system categories
+	UserID     *uuid.UUID   `json:"user_id,omitempty" db:"user_id"`     // nullable for system categories
+	ParentID   *uuid.UUID   `json:"parent_id,omitempty" db:"parent_id"` // nil for top-level categories
 	Name       string       `json:"name" db:"name"`
 	Type       CategoryType `json:"type" db:"type"`
 	IconName   *string      `json:"icon_name,omitempty" db:"icon_name"`
 	ColorHex   *string      `json:"color_hex,omitempty" db:"color_hex"`
 	IsArchived bool         `json:"is_archived" db:"is_archived"`
-	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time    `json:"updated_at" db:"updated_at"`
+	// IsHidden และ IsCustomized มาจาก CategoryOverride ของผู้ใช้ ไม่ได้เก็บในตาราง categories
+	IsHidden     bool      `json:"is_hidden,omitempty" db:"-"`
+	IsCustomized bool      `json:"is_customized,omitempty" db:"-"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
 
 func NewCategory(userID *uuid.UUID, name string, categoryType CategoryType) *Category {
@@ -36,3 +40,100 @@ func NewCategory(userID *uuid.UUID, name string, categoryType CategoryType) *Cat
 		UpdatedAt:  time.Now(),
 	}
 }
+
+// CategoryNode คือหมวดหมู่พร้อมหมวดหมู่ย่อย สำหรับแสดงเป็นต้นไม้
+type CategoryNode struct {
+	*Category
+	Children []*CategoryNode `json:"children"`
+}
+
+// BuildCategoryTree จัดหมวดหมู่เป็นต้นไม้ตาม ParentID หมวดหมู่ที่ไม่พบ parent ในรายการจะอยู่ที่ระดับบนสุด
+func BuildCategoryTree(categories []*Category) []*CategoryNode {
+	nodes := make(map[uuid.UUID]*CategoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &CategoryNode{Category: category, Children: make([]*CategoryNode, 0)}
+	}
+
+	roots := make([]*CategoryNode, 0)
+	for _, category := range categories {
+		node := nodes[category.ID]
+		if category.ParentID != nil {
+			if parent, ok := nodes[*category.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	return roots
+}
+
+// CategoryMergeResult สรุปสิ่งที่ถูกย้ายจากหมวดหมู่ต้นทางไปหมวดหมู่ปลายทาง
+// BudgetsMerged คืองบของต้นทางที่ถูกรวมยอดเข้ากับงบที่ปลายทางมีอยู่แล้ว
+type CategoryMergeResult struct {
+	SourceID           uuid.UUID `json:"source_id"`
+	TargetID           uuid.UUID `json:"target_id"`
+	TransactionsMoved  int64     `json:"transactions_moved"`
+	RecurringMoved     int64     `json:"recurring_moved"`
+	BudgetsMoved       int64     `json:"budgets_moved"`
+	BudgetsMerged      int64     `json:"budgets_merged"`
+	SubcategoriesMoved int64     `json:"subcategories_moved"`
+}
+
+// CategoryUsage คือจำนวนข้อมูลที่อ้างถึงหมวดหมู่ ใช้แสดงก่อนลบหรือรวมหมวดหมู่
+type CategoryUsage struct {
+	CategoryID       uuid.UUID    `json:"category_id"`
+	CategoryName     string       `json:"category_name"`
+	Type             CategoryType `json:"type"`
+	IsArchived       bool         `json:"is_archived"`
+	TransactionCount int64        `json:"transaction_count"`
+	RecurringCount   int64        `json:"recurring_count"`
+	BudgetCount      int64        `json:"budget_count"`
+	LastUsedAt       *time.Time   `json:"last_used_at,omitempty"`
+}
+
+// InUse บอกว่ายังมีรายการ รายการประจำ หรืองบที่อ้างถึงหมวดหมู่นี้อยู่
+func (u *CategoryUsage) InUse() bool {
+	return u.TransactionCount > 0 || u.RecurringCount > 0 || u.BudgetCount > 0
+}
+
+// CategoryOverride คือการปรับหมวดหมู่ของระบบเฉพาะผู้ใช้คนเดียว ฟิลด์ที่เป็น nil ใช้ค่าของหมวดหมู่ระบบ
+type CategoryOverride struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	CategoryID uuid.UUID `json:"category_id" db:"category_id"`
+	Name       *string   `json:"name,omitempty" db:"name"`
+	IconName   *string   `json:"icon_name,omitempty" db:"icon_name"`
+	ColorHex   *string   `json:"color_hex,omitempty" db:"color_hex"`
+	IsHidden   bool      `json:"is_hidden" db:"is_hidden"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func NewCategoryOverride(userID, categoryID uuid.UUID) *CategoryOverride {
+	return &CategoryOverride{
+		ID:         uuid.New(),
+		UserID:     userID,
+		CategoryID: categoryID,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+}
+
+// Apply คืนสำเนาของ category ที่ใส่ค่าจาก override แล้ว category ต้นฉบับไม่ถูกแก้
+func (o *CategoryOverride) Apply(category *Category) *Category {
+	applied := *category
+	if o.Name != nil {
+		applied.Name = *o.Name
+	}
+	if o.IconName != nil {
+		applied.IconName = o.IconName
+	}
+	if o.ColorHex != nil {
+		applied.ColorHex = o.ColorHex
+	}
+	applied.IsHidden = o.IsHidden
+	applied.IsCustomized = true
+	return &applied
+}
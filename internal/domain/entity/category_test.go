@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// treeShape แสดงต้นไม้เป็นข้อความ เช่น "Food(Coffee,Groceries),Travel" เพื่อเทียบใน test ได้ง่าย
+func treeShape(nodes []*CategoryNode) string {
+	parts := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		part := node.Name
+		if len(node.Children) > 0 {
+			part += "(" + treeShape(node.Children) + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestBuildCategoryTree(t *testing.T) {
+	category := func(name string, parent *Category) *Category {
+		c := &Category{ID: uuid.New(), Name: name, Type: CategoryTypeExpense}
+		if parent != nil {
+			c.ParentID = &parent.ID
+		}
+		return c
+	}
+
+	food := category("Food", nil)
+	coffee := category("Coffee", food)
+	groceries := category("Groceries", food)
+	espresso := category("Espresso", coffee)
+	travel := category("Travel", nil)
+	missingParent := &Category{ID: uuid.New(), Name: "Orphan"}
+	missingParentID := uuid.New()
+	missingParent.ParentID = &missingParentID
+
+	tests := []struct {
+		name       string
+		categories []*Category
+		want       string
+	}{
+		{name: "empty", categories: nil, want: ""},
+		{name: "flat", categories: []*Category{food, travel}, want: "Food,Travel"},
+		{name: "nested keeps input order", categories: []*Category{food, coffee, groceries, travel}, want: "Food(Coffee,Groceries),Travel"},
+		{name: "child listed before parent", categories: []*Category{coffee, travel, food}, want: "Travel,Food(Coffee)"},
+		{name: "three levels", categories: []*Category{food, coffee, espresso}, want: "Food(Coffee(Espresso))"},
+		{name: "parent not in list becomes root", categories: []*Category{coffee, espresso, missingParent}, want: "Coffee(Espresso),Orphan"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roots := BuildCategoryTree(tt.categories)
+			if roots == nil {
+				t.Fatal("BuildCategoryTree() = nil, want an empty slice for JSON")
+			}
+			if got := treeShape(roots); got != tt.want {
+				t.Fatalf("BuildCategoryTree() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCategoryTreeLeavesHaveEmptyChildren(t *testing.T) {
+	roots := BuildCategoryTree([]*Category{{ID: uuid.New(), Name: "Leaf"}})
+	if len(roots) != 1 || roots[0].Children == nil {
+		t.Fatalf("leaf Children = %v, want an empty slice", roots[0].Children)
+	}
+}
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MonthlyCashFlow คือยอดรายรับ/รายจ่ายรวมของหนึ่งเดือน
+// RecurringExpense คือส่วนของรายจ่ายที่สร้างจากรายการประจำ (ถือเป็นค่าใช้จ่ายคงที่)
+type MonthlyCashFlow struct {
+	Month            time.Time       `json:"month"`
+	Income           decimal.Decimal `json:"income"`
+	Expense          decimal.Decimal `json:"expense"`
+	RecurringExpense decimal.Decimal `json:"recurring_expense"`
+}
+
+// FinancialHealthMonth คือตัวชี้วัดสุขภาพการเงินของหนึ่งเดือน
+// ตัวชี้วัดที่คำนวณไม่ได้ (เช่น ไม่มีรายรับ ไม่มีงบประมาณ) จะเป็น nil และไม่นำไปคิดคะแนน
+type FinancialHealthMonth struct {
+	Year                int             `json:"year"`
+	Month               int             `json:"month"`
+	Income              decimal.Decimal `json:"income"`
+	Expense             decimal.Decimal `json:"expense"`
+	SavingsRate         *float64        `json:"savings_rate"`
+	SavingsBalance      decimal.Decimal `json:"savings_balance"`
+	EmergencyFundMonths *float64        `json:"emergency_fund_months"`
+	FixedExpense        decimal.Decimal `json:"fixed_expense"`
+	VariableExpense     decimal.Decimal `json:"variable_expense"`
+	FixedExpenseRatio   *float64        `json:"fixed_expense_ratio"`
+	BudgetsTracked      int             `json:"budgets_tracked"`
+	BudgetsWithinLimit  int             `json:"budgets_within_limit"`
+	BudgetAdherence     *float64        `json:"budget_adherence"`
+	Score               *int            `json:"score"`
+}
+
+// FinancialHealthReport คือรายงานเดือนปัจจุบันพร้อมประวัติย้อนหลัง (เรียงจากเก่าไปใหม่ เดือนสุดท้ายคือ Current)
+type FinancialHealthReport struct {
+	Current     *FinancialHealthMonth   `json:"current"`
+	History     []*FinancialHealthMonth `json:"history"`
+	GeneratedAt time.Time               `json:"generated_at"`
+}
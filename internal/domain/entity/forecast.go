@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// DiscretionarySpend คือยอดรายจ่ายที่ไม่ได้มาจากรายการประจำ แยกตามบัญชีและหมวดหมู่
+type DiscretionarySpend struct {
+	AccountID  uuid.UUID       `json:"account_id"`
+	CategoryID uuid.UUID       `json:"category_id"`
+	Total      decimal.Decimal `json:"total"`
+}
+
+// DiscretionaryRate คืออัตราการใช้จ่ายต่อวันของหมวดหมู่ ใช้ประมาณรายจ่ายที่ไม่ได้วางแผนไว้
+type DiscretionaryRate struct {
+	AccountID    uuid.UUID       `json:"account_id"`
+	CategoryID   uuid.UUID       `json:"category_id"`
+	CategoryName string          `json:"category_name"`
+	DailyAmount  decimal.Decimal `json:"daily_amount"`
+}
+
+type ForecastDay struct {
+	Date               time.Time       `json:"date"`
+	ScheduledIncome    decimal.Decimal `json:"scheduled_income"`
+	ScheduledExpense   decimal.Decimal `json:"scheduled_expense"`
+	DiscretionarySpend decimal.Decimal `json:"discretionary_spend"`
+	Balance            decimal.Decimal `json:"balance"`
+	IsNegative         bool            `json:"is_negative"`
+}
+
+type AccountForecast struct {
+	AccountID         uuid.UUID       `json:"account_id"`
+	AccountName       string          `json:"account_name"`
+	AccountType       AccountType     `json:"account_type"`
+	StartingBalance   decimal.Decimal `json:"starting_balance"`
+	EndingBalance     decimal.Decimal `json:"ending_balance"`
+	LowestBalance     decimal.Decimal `json:"lowest_balance"`
+	FirstNegativeDate *time.Time      `json:"first_negative_date,omitempty"`
+	Days              []*ForecastDay  `json:"days"`
+}
+
+type CashFlowForecast struct {
+	StartDate          time.Time            `json:"start_date"`
+	EndDate            time.Time            `json:"end_date"`
+	Days               int                  `json:"days"`
+	LookbackDays       int                  `json:"lookback_days"`
+	Accounts           []*AccountForecast   `json:"accounts"`
+	Overall            []*ForecastDay       `json:"overall"`
+	NegativeDates      []time.Time          `json:"negative_dates"` // วันที่ยอดรวมหรือบัญชีใดบัญชีหนึ่งติดลบ
+	DiscretionaryRates []*DiscretionaryRate `json:"discretionary_rates"`
+}
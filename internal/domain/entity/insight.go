@@ -1,7 +1,10 @@
 package entity
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,18 +14,33 @@ import (
 type InsightType string
 
 const (
-	InsightTypeSpending               InsightType = "spending"
-	InsightTypeBudget                 InsightType = "budget"
-	InsightTypeGoal                   InsightType = "goal"
-	InsightTypeTrend                  InsightType = "trend"
-	InsightTypeRecommend              InsightType = "recommendation"
-	InsightTypeAnomalyDetection       InsightType = "anomaly_detection"
-	InsightTypeSpendingPattern        InsightType = "spending_pattern"
-	InsightTypeBudgetAlert            InsightType = "budget_alert"
-	InsightTypeSavingsRecommendation  InsightType = "savings_recommendation"
-	InsightTypeCategoryRecommendation InsightType = "category_recommendation"
+	InsightTypeSpending                  InsightType = "spending"
+	InsightTypeBudget                    InsightType = "budget"
+	InsightTypeGoal                      InsightType = "goal"
+	InsightTypeTrend                     InsightType = "trend"
+	InsightTypeRecommend                 InsightType = "recommendation"
+	InsightTypeAnomalyDetection          InsightType = "anomaly_detection"
+	InsightTypeSpendingPattern           InsightType = "spending_pattern"
+	InsightTypeBudgetAlert               InsightType = "budget_alert"
+	InsightTypeSavingsRecommendation     InsightType = "savings_recommendation"
+	InsightTypeCategoryRecommendation    InsightType = "category_recommendation"
+	InsightTypeSubscriptionDetected      InsightType = "subscription_detected"
+	InsightTypeSubscriptionPriceIncrease InsightType = "subscription_price_increase"
+	InsightTypeCashflowWarning           InsightType = "cashflow_warning"
 )
 
+// IsValid บอกว่าเป็นประเภท insight ที่ระบบรู้จัก
+func (t InsightType) IsValid() bool {
+	switch t {
+	case InsightTypeSpending, InsightTypeBudget, InsightTypeGoal, InsightTypeTrend, InsightTypeRecommend,
+		InsightTypeAnomalyDetection, InsightTypeSpendingPattern, InsightTypeBudgetAlert,
+		InsightTypeSavingsRecommendation, InsightTypeCategoryRecommendation,
+		InsightTypeSubscriptionDetected, InsightTypeSubscriptionPriceIncrease, InsightTypeCashflowWarning:
+		return true
+	}
+	return false
+}
+
 type InsightPriority string
 
 const (
@@ -44,6 +62,13 @@ type Insight struct {
 	RelatedEntityType *string         `json:"related_entity_type,omitempty" db:"related_entity_type"`
 	RelatedData       json.RawMessage `json:"related_data,omitempty" db:"related_data"`
 	ValidUntil        *time.Time      `json:"valid_until,omitempty" db:"valid_until"`
+	DismissedAt       *time.Time      `json:"dismissed_at,omitempty" db:"dismissed_at"`
+	SnoozedUntil      *time.Time      `json:"snoozed_until,omitempty" db:"snoozed_until"`
+	Fingerprint       *string         `json:"fingerprint,omitempty" db:"fingerprint"`
+	ResolvedAt        *time.Time      `json:"resolved_at,omitempty" db:"resolved_at"`
+	MessageKey        *string         `json:"message_key,omitempty" db:"message_key"`
+	MessageParams     json.RawMessage `json:"message_params,omitempty" db:"message_params"`
+	Locale            *string         `json:"locale,omitempty" db:"locale"`
 	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt         time.Time       `json:"updated_at" db:"updated_at"`
 }
@@ -62,12 +87,82 @@ type SpendingPattern struct {
 	CategoryID     uuid.UUID       `json:"category_id"`
 	CategoryName   string          `json:"category_name"`
 	DayOfWeek      string          `json:"day_of_week"`
-	TimeOfDay      string          `json:"time_of_day"`
+	TimeOfDay      string          `json:"time_of_day"` // ว่างเมื่อรายการไม่มี transaction_time
 	FrequencyCount int             `json:"frequency_count"`
 	AverageAmount  decimal.Decimal `json:"average_amount"`
 	Period         string          `json:"period"`
 }
 
+type PatternTrend string
+
+const (
+	PatternTrendIncreasing PatternTrend = "increasing"
+	PatternTrendDecreasing PatternTrend = "decreasing"
+	PatternTrendStable     PatternTrend = "stable"
+)
+
+// PatternTypeWeekdayTimeSlot คือรูปแบบ "หมวดหมู่ + วันในสัปดาห์ + ช่วงเวลาของวัน"
+const PatternTypeWeekdayTimeSlot = "weekday_time_slot"
+
+// SpendingPatternTrend คือรูปแบบการใช้จ่ายที่งานวิเคราะห์บันทึกไว้ในตาราง spending_patterns
+type SpendingPatternTrend struct {
+	ID               uuid.UUID       `json:"id"`
+	UserID           uuid.UUID       `json:"user_id"`
+	CategoryID       *uuid.UUID      `json:"category_id,omitempty"`
+	CategoryName     string          `json:"category_name"`
+	PatternType      string          `json:"pattern_type"`
+	Frequency        string          `json:"frequency"`
+	DayOfWeek        string          `json:"day_of_week"`
+	TimeOfDay        string          `json:"time_of_day"`
+	FrequencyCount   int             `json:"frequency_count"`
+	AverageAmount    decimal.Decimal `json:"average_amount"`
+	Trend            PatternTrend    `json:"trend"`
+	ChangePercentage float64         `json:"change_percentage"`
+	ConfidenceScore  float64         `json:"confidence_score"` // 0-1
+	StartDate        time.Time       `json:"start_date"`
+	EndDate          time.Time       `json:"end_date"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+// TimeOfDaySlot แบ่งชั่วโมงเป็นช่วงเวลาเดียวกับที่ query รูปแบบการใช้จ่ายใช้
+func TimeOfDaySlot(hour int) string {
+	switch {
+	case hour >= 6 && hour <= 11:
+		return "Morning"
+	case hour >= 12 && hour <= 17:
+		return "Afternoon"
+	case hour >= 18 && hour <= 22:
+		return "Evening"
+	default:
+		return "Night"
+	}
+}
+
+// SubscriptionCandidate คือรายจ่ายที่เกิดซ้ำสม่ำเสมอแต่ยังไม่มี RecurringTransaction รองรับ
+type SubscriptionCandidate struct {
+	Note             string             `json:"note"`
+	CategoryID       uuid.UUID          `json:"category_id"`
+	AccountID        uuid.UUID          `json:"account_id"`
+	Frequency        RecurringFrequency `json:"frequency"`
+	Amount           decimal.Decimal    `json:"amount"`
+	LatestAmount     decimal.Decimal    `json:"latest_amount"`
+	Occurrences      int                `json:"occurrences"`
+	LastChargedAt    time.Time          `json:"last_charged_at"`
+	NextExpectedDate time.Time          `json:"next_expected_date"`
+}
+
+// SubscriptionPriceIncrease คือการเรียกเก็บล่าสุดของ subscription ที่แพงขึ้นจากราคาเดิม
+type SubscriptionPriceIncrease struct {
+	RecurringTransactionID *uuid.UUID      `json:"recurring_transaction_id,omitempty"`
+	Note                   string          `json:"note"`
+	CategoryID             uuid.UUID       `json:"category_id"`
+	PreviousAmount         decimal.Decimal `json:"previous_amount"`
+	NewAmount              decimal.Decimal `json:"new_amount"`
+	PercentageIncrease     float64         `json:"percentage_increase"`
+	ChargedAt              time.Time       `json:"charged_at"`
+}
+
 func NewInsight(userID uuid.UUID, insightType InsightType, content string, relatedData json.RawMessage) *Insight {
 	return &Insight{
 		ID:          uuid.New(),
@@ -96,3 +191,39 @@ func NewAdvancedInsight(userID uuid.UUID, insightType InsightType, priority Insi
 		UpdatedAt: time.Now(),
 	}
 }
+
+// SetFingerprint กำหนด fingerprint จาก type + related entity + period
+// insight ที่ fingerprint ตรงกันถือเป็นเรื่องเดียวกัน การสร้างซ้ำจะอัปเดตแถวเดิมแทนการเพิ่มแถวใหม่
+func (i *Insight) SetFingerprint(period string) {
+	var entityType, entityID string
+	if i.RelatedEntityType != nil {
+		entityType = *i.RelatedEntityType
+	}
+	if i.RelatedEntityID != nil {
+		entityID = i.RelatedEntityID.String()
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{string(i.Type), entityType, entityID, period}, "|")))
+	fingerprint := hex.EncodeToString(sum[:])
+	i.Fingerprint = &fingerprint
+}
+
+// InsightProcessingReport สรุปผลการประมวลผล insight ของผู้ใช้ทุกคน
+type InsightProcessingReport struct {
+	StartedAt      time.Time                   `json:"started_at"`
+	FinishedAt     time.Time                   `json:"finished_at"`
+	TotalUsers     int                         `json:"total_users"`
+	SucceededUsers []uuid.UUID                 `json:"succeeded_users"`
+	FailedUsers    []*InsightProcessingFailure `json:"failed_users"`
+}
+
+// InsightUnreadCount จำนวน insight ที่ยังไม่อ่าน (ไม่นับที่ถูก dismiss/snooze หรือหมดอายุ)
+type InsightUnreadCount struct {
+	Total  int                 `json:"total"`
+	ByType map[InsightType]int `json:"by_type"`
+}
+
+type InsightProcessingFailure struct {
+	UserID uuid.UUID `json:"user_id"`
+	Error  string    `json:"error"`
+}
@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InsightRating string
+
+const (
+	InsightRatingHelpful    InsightRating = "helpful"
+	InsightRatingNotHelpful InsightRating = "not_helpful"
+)
+
+type InsightFeedback struct {
+	ID          uuid.UUID     `json:"id"`
+	UserID      uuid.UUID     `json:"user_id"`
+	InsightID   *uuid.UUID    `json:"insight_id,omitempty"`
+	InsightType InsightType   `json:"insight_type"`
+	Rating      InsightRating `json:"rating"`
+	Comment     *string       `json:"comment,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// InsightTypeFeedback คือผลรวมคะแนนของ insight ประเภทหนึ่ง
+type InsightTypeFeedback struct {
+	Helpful    int `json:"helpful"`
+	NotHelpful int `json:"not_helpful"`
+}
+
+type InsightMuteScope string
+
+const (
+	InsightMuteScopeCategory    InsightMuteScope = "category"
+	InsightMuteScopeInsightType InsightMuteScope = "insight_type"
+)
+
+// InsightMute คือหมวดหมู่หรือประเภท insight ที่ผู้ใช้ไม่ต้องการรับ insight อีก
+type InsightMute struct {
+	ID          uuid.UUID        `json:"id"`
+	UserID      uuid.UUID        `json:"user_id"`
+	Scope       InsightMuteScope `json:"scope"`
+	CategoryID  *uuid.UUID       `json:"category_id,omitempty"`
+	InsightType *InsightType     `json:"insight_type,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+func NewInsightFeedback(insight *Insight, rating InsightRating, comment *string) *InsightFeedback {
+	insightID := insight.ID
+	return &InsightFeedback{
+		ID:          uuid.New(),
+		UserID:      insight.UserID,
+		InsightID:   &insightID,
+		InsightType: insight.Type,
+		Rating:      rating,
+		Comment:     comment,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
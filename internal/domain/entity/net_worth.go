@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// NetWorthTypeBalance คือยอดรวมของบัญชีประเภทเดียวกัน Balance ของหนี้สินเป็นค่าติดลบเมื่อมียอดค้าง
+type NetWorthTypeBalance struct {
+	Type         AccountType     `json:"type"`
+	IsLiability  bool            `json:"is_liability"`
+	Balance      decimal.Decimal `json:"balance"`
+	AccountCount int             `json:"account_count"`
+}
+
+// NetWorthSnapshot คือมูลค่าสุทธิ ณ สิ้นวัน SnapshotDate
+// TotalLiabilities เป็นค่าบวกของยอดค้างในบัญชีหนี้สิน และ NetWorth = TotalAssets - TotalLiabilities
+type NetWorthSnapshot struct {
+	ID               uuid.UUID              `json:"id"`
+	UserID           uuid.UUID              `json:"user_id"`
+	SnapshotDate     time.Time              `json:"snapshot_date"`
+	TotalAssets      decimal.Decimal        `json:"total_assets"`
+	TotalLiabilities decimal.Decimal        `json:"total_liabilities"`
+	NetWorth         decimal.Decimal        `json:"net_worth"`
+	Breakdown        []*NetWorthTypeBalance `json:"breakdown"`
+	CreatedAt        time.Time              `json:"created_at"`
+}
+
+type NetWorthGranularity string
+
+const (
+	NetWorthGranularityDay   NetWorthGranularity = "day"
+	NetWorthGranularityMonth NetWorthGranularity = "month"
+)
+
+// NetWorthHistory คือ snapshot ในช่วง [From, To] พร้อมมูลค่าสุทธิปัจจุบันที่คำนวณสด
+// ถ้า Granularity เป็น month จะเหลือ snapshot สุดท้ายของแต่ละเดือน
+type NetWorthHistory struct {
+	From        time.Time           `json:"from"`
+	To          time.Time           `json:"to"`
+	Granularity NetWorthGranularity `json:"granularity"`
+	Current     *NetWorthSnapshot   `json:"current"`
+	Snapshots   []*NetWorthSnapshot `json:"snapshots"`
+	// Change คือมูลค่าสุทธิปัจจุบันเทียบกับ snapshot แรกในช่วง (nil ถ้าไม่มี snapshot)
+	Change *decimal.Decimal `json:"change,omitempty"`
+}
+
+// NetWorthSnapshotReport สรุปผลการบันทึก snapshot ของผู้ใช้ทุกคน
+type NetWorthSnapshotReport struct {
+	SnapshotDate   time.Time                  `json:"snapshot_date"`
+	StartedAt      time.Time                  `json:"started_at"`
+	FinishedAt     time.Time                  `json:"finished_at"`
+	TotalUsers     int                        `json:"total_users"`
+	SucceededUsers int                        `json:"succeeded_users"`
+	FailedUsers    []*NetWorthSnapshotFailure `json:"failed_users"`
+}
+
+type NetWorthSnapshotFailure struct {
+	UserID uuid.UUID `json:"user_id"`
+	Error  string    `json:"error"`
+}
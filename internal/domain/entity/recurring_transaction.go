@@ -16,24 +16,73 @@ const (
 	RecurringFrequencyYearly  RecurringFrequency = "yearly"
 )
 
+type RecurringAmountMode string
+
+const (
+	RecurringAmountModeFixed   RecurringAmountMode = "fixed"
+	RecurringAmountModeAverage RecurringAmountMode = "average" // ค่าเฉลี่ยของ N ครั้งล่าสุด
+	RecurringAmountModeRange   RecurringAmountMode = "range"   // ช่วง MinAmount - MaxAmount
+)
+
+// DefaultEstimateWindow คือจำนวนครั้งล่าสุดที่ใช้หาค่าเฉลี่ยเมื่อไม่ได้กำหนด EstimateWindow
+const DefaultEstimateWindow = 3
+
 type RecurringTransaction struct {
-	ID                  uuid.UUID          `json:"id"`
-	UserID              uuid.UUID          `json:"user_id"`
-	CategoryID          uuid.UUID          `json:"category_id"`
-	AccountID           uuid.UUID          `json:"account_id"`
-	Amount              decimal.Decimal    `json:"amount"`
-	Type                TransactionType    `json:"type"`
-	Note                *string            `json:"note,omitempty"`
-	Frequency           RecurringFrequency `json:"frequency"`
-	StartDate           time.Time          `json:"start_date"`
-	EndDate             *time.Time         `json:"end_date,omitempty"`
-	NextExecutionDate   time.Time          `json:"next_execution_date"`
-	LastExecutionDate   *time.Time         `json:"last_execution_date,omitempty"`
-	IsActive            bool               `json:"is_active"`
-	AutoExecute         bool               `json:"auto_execute"`
-	RemainingExecutions *int               `json:"remaining_executions,omitempty"` // null = unlimited
-	CreatedAt           time.Time          `json:"created_at"`
-	UpdatedAt           time.Time          `json:"updated_at"`
+	ID                  uuid.UUID           `json:"id"`
+	UserID              uuid.UUID           `json:"user_id"`
+	CategoryID          uuid.UUID           `json:"category_id"`
+	AccountID           uuid.UUID           `json:"account_id"`
+	Amount              decimal.Decimal     `json:"amount"`
+	Type                TransactionType     `json:"type"`
+	Note                *string             `json:"note,omitempty"`
+	Frequency           RecurringFrequency  `json:"frequency"`
+	StartDate           time.Time           `json:"start_date"`
+	EndDate             *time.Time          `json:"end_date,omitempty"`
+	NextExecutionDate   time.Time           `json:"next_execution_date"`
+	LastExecutionDate   *time.Time          `json:"last_execution_date,omitempty"`
+	IsActive            bool                `json:"is_active"`
+	AutoExecute         bool                `json:"auto_execute"`
+	RemainingExecutions *int                `json:"remaining_executions,omitempty"` // null = unlimited
+	AmountMode          RecurringAmountMode `json:"amount_mode"`
+	EstimateWindow      *int                `json:"estimate_window,omitempty"` // ใช้กับ amount_mode = average
+	MinAmount           *decimal.Decimal    `json:"min_amount,omitempty"`      // ใช้กับ amount_mode = range
+	MaxAmount           *decimal.Decimal    `json:"max_amount,omitempty"`
+	CreatedAt           time.Time           `json:"created_at"`
+	UpdatedAt           time.Time           `json:"updated_at"`
+}
+
+// RecurringExecution บันทึกการรันแต่ละครั้ง เพื่อใช้ประมาณยอดของรายการที่ยอดไม่คงที่
+type RecurringExecution struct {
+	ID                     uuid.UUID       `json:"id"`
+	RecurringTransactionID uuid.UUID       `json:"recurring_transaction_id"`
+	TransactionID          uuid.UUID       `json:"transaction_id"`
+	EstimatedAmount        decimal.Decimal `json:"estimated_amount"`
+	ActualAmount           decimal.Decimal `json:"actual_amount"`
+	ExecutedAt             time.Time       `json:"executed_at"`
+}
+
+// RecurringAmountEstimate คือยอดที่คาดการณ์ของรายการครั้งถัดไป
+type RecurringAmountEstimate struct {
+	Amount     decimal.Decimal `json:"amount"`
+	MinAmount  decimal.Decimal `json:"min_amount"`
+	MaxAmount  decimal.Decimal `json:"max_amount"`
+	IsEstimate bool            `json:"is_estimate"`
+}
+
+// RecurringOccurrence คือรายการที่คาดว่าจะเกิดขึ้นจากกฎ recurring ในวันที่หนึ่ง (ใช้กับปฏิทินบิล)
+type RecurringOccurrence struct {
+	RecurringTransactionID uuid.UUID          `json:"recurring_transaction_id"`
+	CategoryID             uuid.UUID          `json:"category_id"`
+	CategoryName           string             `json:"category_name"`
+	AccountID              uuid.UUID          `json:"account_id"`
+	Date                   time.Time          `json:"date"`
+	Amount                 decimal.Decimal    `json:"amount"`
+	Type                   TransactionType    `json:"type"`
+	Frequency              RecurringFrequency `json:"frequency"`
+	Note                   *string            `json:"note,omitempty"`
+	IsEstimate             bool               `json:"is_estimate"`
+	MinAmount              *decimal.Decimal   `json:"min_amount,omitempty"`
+	MaxAmount              *decimal.Decimal   `json:"max_amount,omitempty"`
 }
 
 func NewRecurringTransaction(
@@ -57,6 +106,7 @@ func NewRecurringTransaction(
 		NextExecutionDate: startDate,
 		IsActive:          true,
 		AutoExecute:       false, // Default to manual approval
+		AmountMode:        RecurringAmountModeFixed,
 		CreatedAt:         time.Now(),
 		UpdatedAt:         time.Now(),
 	}
@@ -69,16 +119,103 @@ func (rt *RecurringTransaction) CalculateNextExecutionDate() time.Time {
 		baseDate = *rt.LastExecutionDate
 	}
 
-	switch rt.Frequency {
+	return advanceByFrequency(baseDate, rt.Frequency)
+}
+
+// IsVariableAmount บอกว่ายอดจริงต้องยืนยันตอนอนุมัติ (ไม่ใช่ยอดคงที่)
+func (rt *RecurringTransaction) IsVariableAmount() bool {
+	return rt.AmountMode == RecurringAmountModeAverage || rt.AmountMode == RecurringAmountModeRange
+}
+
+// EstimateAmount คำนวณยอดที่คาดการณ์จากยอดจริงของการรันล่าสุด (เรียงจากใหม่ไปเก่า)
+// ถ้ายังไม่มีประวัติจะใช้ Amount เป็นค่าประมาณตั้งต้น
+func (rt *RecurringTransaction) EstimateAmount(recentAmounts []decimal.Decimal) RecurringAmountEstimate {
+	estimate := RecurringAmountEstimate{
+		Amount:     rt.Amount,
+		MinAmount:  rt.Amount,
+		MaxAmount:  rt.Amount,
+		IsEstimate: rt.IsVariableAmount(),
+	}
+
+	switch rt.AmountMode {
+	case RecurringAmountModeAverage:
+		window := DefaultEstimateWindow
+		if rt.EstimateWindow != nil && *rt.EstimateWindow > 0 {
+			window = *rt.EstimateWindow
+		}
+		if len(recentAmounts) > window {
+			recentAmounts = recentAmounts[:window]
+		}
+		if len(recentAmounts) == 0 {
+			return estimate
+		}
+
+		total := decimal.Zero
+		estimate.MinAmount = recentAmounts[0]
+		estimate.MaxAmount = recentAmounts[0]
+		for _, amount := range recentAmounts {
+			total = total.Add(amount)
+			if amount.LessThan(estimate.MinAmount) {
+				estimate.MinAmount = amount
+			}
+			if amount.GreaterThan(estimate.MaxAmount) {
+				estimate.MaxAmount = amount
+			}
+		}
+		estimate.Amount = total.Div(decimal.NewFromInt(int64(len(recentAmounts)))).Round(2)
+	case RecurringAmountModeRange:
+		if rt.MinAmount != nil && rt.MaxAmount != nil {
+			estimate.MinAmount = *rt.MinAmount
+			estimate.MaxAmount = *rt.MaxAmount
+			estimate.Amount = rt.MinAmount.Add(*rt.MaxAmount).Div(decimal.NewFromInt(2)).Round(2)
+		}
+	}
+
+	return estimate
+}
+
+// OccurrencesBetween คืนวันที่ที่กฎนี้จะรันในช่วง [from, to] โดยเริ่มนับจาก NextExecutionDate
+// และเคารพ EndDate กับ RemainingExecutions
+func (rt *RecurringTransaction) OccurrencesBetween(from, to time.Time) []time.Time {
+	if !rt.IsActive {
+		return nil
+	}
+
+	remaining := -1 // -1 = unlimited
+	if rt.RemainingExecutions != nil {
+		remaining = *rt.RemainingExecutions
+	}
+
+	var dates []time.Time
+	for date := rt.NextExecutionDate; !date.After(to); date = advanceByFrequency(date, rt.Frequency) {
+		if remaining == 0 {
+			break
+		}
+		if rt.EndDate != nil && date.After(*rt.EndDate) {
+			break
+		}
+		if !date.Before(from) {
+			dates = append(dates, date)
+		}
+		if remaining > 0 {
+			remaining--
+		}
+	}
+
+	return dates
+}
+
+func advanceByFrequency(date time.Time, frequency RecurringFrequency) time.Time {
+	switch frequency {
 	case RecurringFrequencyDaily:
-		return baseDate.AddDate(0, 0, 1)
+		return date.AddDate(0, 0, 1)
 	case RecurringFrequencyWeekly:
-		return baseDate.AddDate(0, 0, 7)
+		return date.AddDate(0, 0, 7)
 	case RecurringFrequencyMonthly:
-		return baseDate.AddDate(0, 1, 0)
+		return date.AddDate(0, 1, 0)
 	case RecurringFrequencyYearly:
-		return baseDate.AddDate(1, 0, 0)
+		return date.AddDate(1, 0, 0)
 	default:
-		return baseDate.AddDate(0, 1, 0) // Default to monthly
+		return date.AddDate(0, 1, 0) // Default to monthly
 	}
 }
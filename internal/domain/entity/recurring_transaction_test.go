@@ -0,0 +1,195 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func testDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestRecurringTransactionOccurrencesBetween(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	timePtr := func(v time.Time) *time.Time { return &v }
+
+	tests := []struct {
+		name string
+		rule RecurringTransaction
+		from time.Time
+		to   time.Time
+		want []time.Time
+	}{
+		{
+			name: "monthly within range",
+			rule: RecurringTransaction{Frequency: RecurringFrequencyMonthly, NextExecutionDate: testDate(2026, time.January, 15), IsActive: true},
+			from: testDate(2026, time.January, 1),
+			to:   testDate(2026, time.March, 31),
+			want: []time.Time{testDate(2026, time.January, 15), testDate(2026, time.February, 15), testDate(2026, time.March, 15)},
+		},
+		{
+			name: "range bounds are inclusive",
+			rule: RecurringTransaction{Frequency: RecurringFrequencyWeekly, NextExecutionDate: testDate(2026, time.March, 2), IsActive: true},
+			from: testDate(2026, time.March, 2),
+			to:   testDate(2026, time.March, 16),
+			want: []time.Time{testDate(2026, time.March, 2), testDate(2026, time.March, 9), testDate(2026, time.March, 16)},
+		},
+		{
+			name: "occurrences before from are skipped",
+			rule: RecurringTransaction{Frequency: RecurringFrequencyDaily, NextExecutionDate: testDate(2026, time.May, 1), IsActive: true},
+			from: testDate(2026, time.May, 4),
+			to:   testDate(2026, time.May, 5),
+			want: []time.Time{testDate(2026, time.May, 4), testDate(2026, time.May, 5)},
+		},
+		{
+			name: "yearly",
+			rule: RecurringTransaction{Frequency: RecurringFrequencyYearly, NextExecutionDate: testDate(2026, time.June, 1), IsActive: true},
+			from: testDate(2026, time.January, 1),
+			to:   testDate(2028, time.December, 31),
+			want: []time.Time{testDate(2026, time.June, 1), testDate(2027, time.June, 1), testDate(2028, time.June, 1)},
+		},
+		{
+			name: "stops at end date",
+			rule: RecurringTransaction{
+				Frequency: RecurringFrequencyMonthly, NextExecutionDate: testDate(2026, time.January, 10), IsActive: true,
+				EndDate: timePtr(testDate(2026, time.February, 10)),
+			},
+			from: testDate(2026, time.January, 1),
+			to:   testDate(2026, time.June, 30),
+			want: []time.Time{testDate(2026, time.January, 10), testDate(2026, time.February, 10)},
+		},
+		{
+			name: "remaining executions count from next execution",
+			rule: RecurringTransaction{
+				Frequency: RecurringFrequencyMonthly, NextExecutionDate: testDate(2026, time.January, 10), IsActive: true,
+				RemainingExecutions: intPtr(3),
+			},
+			from: testDate(2026, time.February, 1),
+			to:   testDate(2026, time.December, 31),
+			want: []time.Time{testDate(2026, time.February, 10), testDate(2026, time.March, 10)},
+		},
+		{
+			name: "no remaining executions",
+			rule: RecurringTransaction{
+				Frequency: RecurringFrequencyMonthly, NextExecutionDate: testDate(2026, time.January, 10), IsActive: true,
+				RemainingExecutions: intPtr(0),
+			},
+			from: testDate(2026, time.January, 1),
+			to:   testDate(2026, time.December, 31),
+		},
+		{
+			name: "inactive rule",
+			rule: RecurringTransaction{Frequency: RecurringFrequencyMonthly, NextExecutionDate: testDate(2026, time.January, 10)},
+			from: testDate(2026, time.January, 1),
+			to:   testDate(2026, time.December, 31),
+		},
+		{
+			name: "next execution after range",
+			rule: RecurringTransaction{Frequency: RecurringFrequencyMonthly, NextExecutionDate: testDate(2027, time.January, 10), IsActive: true},
+			from: testDate(2026, time.January, 1),
+			to:   testDate(2026, time.December, 31),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.rule.OccurrencesBetween(tt.from, tt.to)
+			if len(got) != len(tt.want) {
+				t.Fatalf("OccurrencesBetween() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Fatalf("OccurrencesBetween() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRecurringTransactionEstimateAmount(t *testing.T) {
+	d := decimal.RequireFromString
+	amounts := func(values ...string) []decimal.Decimal {
+		result := make([]decimal.Decimal, len(values))
+		for i, value := range values {
+			result[i] = d(value)
+		}
+		return result
+	}
+	window := func(v int) *int { return &v }
+	decimalPtr := func(v string) *decimal.Decimal {
+		value := d(v)
+		return &value
+	}
+
+	tests := []struct {
+		name   string
+		rule   RecurringTransaction
+		recent []decimal.Decimal
+		want   RecurringAmountEstimate
+	}{
+		{
+			name:   "fixed ignores history",
+			rule:   RecurringTransaction{Amount: d("500"), AmountMode: RecurringAmountModeFixed},
+			recent: amounts("900", "100"),
+			want:   RecurringAmountEstimate{Amount: d("500"), MinAmount: d("500"), MaxAmount: d("500")},
+		},
+		{
+			name: "average without history uses amount",
+			rule: RecurringTransaction{Amount: d("1200"), AmountMode: RecurringAmountModeAverage},
+			want: RecurringAmountEstimate{Amount: d("1200"), MinAmount: d("1200"), MaxAmount: d("1200"), IsEstimate: true},
+		},
+		{
+			name:   "average uses default window of latest executions",
+			rule:   RecurringTransaction{Amount: d("1000"), AmountMode: RecurringAmountModeAverage},
+			recent: amounts("1100", "900", "1300", "5000"),
+			want:   RecurringAmountEstimate{Amount: d("1100"), MinAmount: d("900"), MaxAmount: d("1300"), IsEstimate: true},
+		},
+		{
+			name:   "average with custom window",
+			rule:   RecurringTransaction{Amount: d("1000"), AmountMode: RecurringAmountModeAverage, EstimateWindow: window(2)},
+			recent: amounts("1100", "900", "1300"),
+			want:   RecurringAmountEstimate{Amount: d("1000"), MinAmount: d("900"), MaxAmount: d("1100"), IsEstimate: true},
+		},
+		{
+			name:   "average with fewer executions than window",
+			rule:   RecurringTransaction{Amount: d("1000"), AmountMode: RecurringAmountModeAverage, EstimateWindow: window(6)},
+			recent: amounts("850.50"),
+			want:   RecurringAmountEstimate{Amount: d("850.50"), MinAmount: d("850.50"), MaxAmount: d("850.50"), IsEstimate: true},
+		},
+		{
+			name:   "average rounds to two decimals",
+			rule:   RecurringTransaction{Amount: d("100"), AmountMode: RecurringAmountModeAverage},
+			recent: amounts("100", "100", "100.01"),
+			want:   RecurringAmountEstimate{Amount: d("100"), MinAmount: d("100"), MaxAmount: d("100.01"), IsEstimate: true},
+		},
+		{
+			name:   "non-positive window falls back to default",
+			rule:   RecurringTransaction{Amount: d("100"), AmountMode: RecurringAmountModeAverage, EstimateWindow: window(0)},
+			recent: amounts("10", "20", "30", "40"),
+			want:   RecurringAmountEstimate{Amount: d("20"), MinAmount: d("10"), MaxAmount: d("30"), IsEstimate: true},
+		},
+		{
+			name:   "range uses midpoint",
+			rule:   RecurringTransaction{Amount: d("700"), AmountMode: RecurringAmountModeRange, MinAmount: decimalPtr("600"), MaxAmount: decimalPtr("901")},
+			recent: amounts("2000"),
+			want:   RecurringAmountEstimate{Amount: d("750.5"), MinAmount: d("600"), MaxAmount: d("901"), IsEstimate: true},
+		},
+		{
+			name: "range without bounds uses amount",
+			rule: RecurringTransaction{Amount: d("700"), AmountMode: RecurringAmountModeRange, MinAmount: decimalPtr("600")},
+			want: RecurringAmountEstimate{Amount: d("700"), MinAmount: d("700"), MaxAmount: d("700"), IsEstimate: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.rule.EstimateAmount(tt.recent)
+			if !got.Amount.Equal(tt.want.Amount) || !got.MinAmount.Equal(tt.want.MinAmount) ||
+				!got.MaxAmount.Equal(tt.want.MaxAmount) || got.IsEstimate != tt.want.IsEstimate {
+				t.Fatalf("EstimateAmount() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ type Transaction struct {
 	Type            TransactionType `json:"type" db:"type"`
 	Note            *string         `json:"note,omitempty" db:"note"`
 	TransactionDate time.Time       `json:"transaction_date" db:"transaction_date"`
+	TransactionTime *string         `json:"transaction_time,omitempty" db:"transaction_time"` // "HH:MM", nil if unknown
 	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
 }
@@ -42,3 +43,10 @@ func NewTransaction(userID, categoryID, accountID uuid.UUID, amount decimal.Deci
 		UpdatedAt:       time.Now(),
 	}
 }
+
+// TransactionDetail คือรายการพร้อมชื่อหมวดหมู่และบัญชีที่ join มาจากฐานข้อมูลใน query เดียว
+type TransactionDetail struct {
+	Transaction  *Transaction
+	CategoryName string
+	AccountName  string
+}
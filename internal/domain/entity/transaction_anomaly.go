@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// TransactionAnomalyType บอกว่ารายการถูกเทียบกับกลุ่มไหน
+type TransactionAnomalyType string
+
+const (
+	TransactionAnomalyCategoryAmount TransactionAnomalyType = "unusual_amount_category"
+	TransactionAnomalyMerchantAmount TransactionAnomalyType = "unusual_amount_merchant"
+)
+
+type AnomalyReviewStatus string
+
+const (
+	AnomalyReviewPending   AnomalyReviewStatus = "pending"
+	AnomalyReviewConfirmed AnomalyReviewStatus = "confirmed"
+	AnomalyReviewDismissed AnomalyReviewStatus = "dismissed"
+)
+
+// TransactionAnomaly คือรายการใช้จ่ายหนึ่งรายการที่ยอดผิดปกติเมื่อเทียบกับประวัติ (แถวใน spending_anomalies)
+// ต่างจาก SpendingAnomaly ที่เทียบยอดรวมรายเดือนของหมวดหมู่
+type TransactionAnomaly struct {
+	ID                  uuid.UUID              `json:"id"`
+	UserID              uuid.UUID              `json:"user_id"`
+	TransactionID       uuid.UUID              `json:"transaction_id"`
+	CategoryID          *uuid.UUID             `json:"category_id,omitempty"`
+	AnomalyType         TransactionAnomalyType `json:"anomaly_type"`
+	Severity            string                 `json:"severity"` // "low", "medium", "high"
+	ExpectedRangeMin    decimal.Decimal        `json:"expected_range_min"`
+	ExpectedRangeMax    decimal.Decimal        `json:"expected_range_max"`
+	ActualAmount        decimal.Decimal        `json:"actual_amount"`
+	DeviationPercentage float64                `json:"deviation_percentage"`
+	RobustZScore        float64                `json:"robust_z_score"`
+	SampleSize          int                    `json:"sample_size"`
+	DetectionDate       time.Time              `json:"detection_date"`
+	Description         *string                `json:"description,omitempty"`
+	ReviewStatus        AnomalyReviewStatus    `json:"review_status"`
+	ReviewedAt          *time.Time             `json:"reviewed_at,omitempty"`
+
+	// ข้อมูลประกอบจากรายการและหมวดหมู่ (อ่านอย่างเดียว)
+	TransactionNote *string    `json:"transaction_note,omitempty"`
+	TransactionDate *time.Time `json:"transaction_date,omitempty"`
+	CategoryName    *string    `json:"category_name,omitempty"`
+}
+
+func NewTransactionAnomaly(transaction *Transaction, anomalyType TransactionAnomalyType) *TransactionAnomaly {
+	categoryID := transaction.CategoryID
+	return &TransactionAnomaly{
+		ID:            uuid.New(),
+		UserID:        transaction.UserID,
+		TransactionID: transaction.ID,
+		CategoryID:    &categoryID,
+		AnomalyType:   anomalyType,
+		ActualAmount:  transaction.Amount,
+		DetectionDate: time.Now(),
+		ReviewStatus:  AnomalyReviewPending,
+	}
+}
@@ -12,6 +12,7 @@ type User struct {
 	PasswordHash       string     `json:"-" db:"password_hash"` // Don't include in JSON
 	DisplayName        *string    `json:"display_name,omitempty" db:"display_name"`
 	CurrencyPreference string     `json:"currency_preference" db:"currency_preference"`
+	Locale             string     `json:"locale" db:"locale"`
 	IsActive           bool       `json:"is_active" db:"is_active"`
 	LastLoginAt        *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
 	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
@@ -25,6 +26,7 @@ func NewUser(email, passwordHash string, displayName *string) *User {
 		PasswordHash:       passwordHash,
 		DisplayName:        displayName,
 		CurrencyPreference: "THB",
+		Locale:             "th",
 		IsActive:           true,
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"savvy-backend/internal/domain/entity"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type AccountRepository interface {
@@ -14,4 +16,11 @@ type AccountRepository interface {
 	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Account, error)
 	Update(ctx context.Context, account *entity.Account) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	// GetCurrentBalances คืนยอดคงเหลือปัจจุบัน (initial_balance + รายรับ - รายจ่าย) ของทุกบัญชีของผู้ใช้
+	GetCurrentBalances(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]decimal.Decimal, error)
+	// GetBalancesAsOf คืนยอดคงเหลือของทุกบัญชี ณ สิ้นวันที่ asOf
+	GetBalancesAsOf(ctx context.Context, userID uuid.UUID, asOf time.Time) (map[uuid.UUID]decimal.Decimal, error)
+	// GetMonthEndBalances คืนยอดคงเหลือของทุกบัญชี ณ สิ้นเดือนของทุกเดือนตั้งแต่เดือนของ from ถึงเดือนของ to
+	// โดย key เป็นเดือนในรูปแบบ "2006-01"
+	GetMonthEndBalances(ctx context.Context, userID uuid.UUID, from, to time.Time) (map[string]map[uuid.UUID]decimal.Decimal, error)
 }
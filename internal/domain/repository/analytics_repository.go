@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// TimeSeriesQuery คือเงื่อนไขของ time series ใช้ filter ชุดเดียวกับการค้นหารายการ (ไม่สนใจ Limit/Offset)
+type TimeSeriesQuery struct {
+	Filter      TransactionFilter
+	Granularity entity.TimeSeriesGranularity
+	GroupBy     entity.TimeSeriesGroupBy
+}
+
+// AnalyticsRepository รวมยอดใน SQL สำหรับหน้าวิเคราะห์ข้อมูล โดยไม่ต้องโหลดรายการทั้งหมดเข้ามาใน Go
+// ยอดรวมอ่านจากตาราง daily_category_totals ซึ่ง TransactionRepository ปรับให้ตรงกับรายการใน transaction เดียวกัน
+type AnalyticsRepository interface {
+	// GetCategoryTotals รวมยอดตามหมวดหมู่ในช่วง [startDate, endDate] (รวมวันสุดท้าย)
+	GetCategoryTotals(ctx context.Context, userID uuid.UUID, transactionType entity.TransactionType, startDate, endDate time.Time) ([]*entity.CategoryTotal, error)
+	// GetCategoryTotalsAtDepth เหมือน GetCategoryTotals แต่รวมยอดของหมวดหมู่ย่อยขึ้นไปที่บรรพบุรุษระดับ depth
+	// (1 = หมวดหมู่หลัก) หมวดหมู่ที่อยู่ตื้นกว่า depth ใช้ตัวเอง
+	GetCategoryTotalsAtDepth(ctx context.Context, userID uuid.UUID, transactionType entity.TransactionType, startDate, endDate time.Time, depth int) ([]*entity.CategoryTotal, error)
+	// GetMonthlyTotals รวมรายรับ/รายจ่ายรายเดือนในช่วง [startDate, endDate) เดือนที่ไม่มีรายการจะไม่อยู่ในผลลัพธ์
+	GetMonthlyTotals(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*entity.MonthlyTotal, error)
+	// GetTimeSeries รวมยอดตาม bucket เวลาและกลุ่ม bucket ที่ไม่มีรายการจะไม่อยู่ในผลลัพธ์
+	// ถ้ามีเงื่อนไขค้นหาข้อความหรือช่วงจำนวนเงินจะรวมจากตาราง transactions โดยตรง
+	GetTimeSeries(ctx context.Context, query TimeSeriesQuery) ([]*entity.TimeSeriesBucket, error)
+	// GetDailyExpenseTotals รวมรายจ่ายรายวันในช่วง [startDate, endDate] พร้อมหมวดหมู่ที่ใช้จ่ายมากที่สุดของแต่ละวัน
+	// วันที่ไม่มีรายจ่ายจะไม่อยู่ในผลลัพธ์
+	GetDailyExpenseTotals(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*entity.CalendarDay, error)
+	// GetExpenseHeatmap รวมรายจ่ายตามวันในสัปดาห์ (ISO) × ชั่วโมงในช่วง [startDate, endDate] จากตาราง transactions
+	// รายการที่ไม่มี transaction_time รวมอยู่ในแถวที่ Hour เป็น -1 ของแต่ละวัน
+	GetExpenseHeatmap(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*entity.HeatmapCell, error)
+	// GetAccountCategoryTotals รวมยอดตามบัญชี × หมวดหมู่ × ประเภทในช่วง [startDate, endDate]
+	GetAccountCategoryTotals(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*entity.AccountCategoryTotal, error)
+	// RebuildDailyCategoryTotals คำนวณ daily_category_totals ใหม่จากตาราง transactions
+	// ถ้า userID เป็น nil จะคำนวณใหม่ทั้งหมด คืนค่าจำนวนแถวที่สร้าง
+	RebuildDailyCategoryTotals(ctx context.Context, userID *uuid.UUID) (int64, error)
+}
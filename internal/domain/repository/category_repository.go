@@ -2,12 +2,19 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"savvy-backend/internal/domain/entity"
 
 	"github.com/google/uuid"
 )
 
+// ErrBudgetPeriodMismatch คือ error เมื่อรวมหมวดหมู่ที่มีงบ active ต่างรอบกัน ซึ่งรวมยอดกันไม่ได้
+var ErrBudgetPeriodMismatch = errors.New("source and target budgets have different periods")
+
+// ErrCategoryInUse คือ error เมื่อลบหมวดหมู่ที่ยังมีรายการ รายการประจำ หรืองบอ้างถึงอยู่
+var ErrCategoryInUse = errors.New("category is in use")
+
 type CategoryFilter struct {
 	UserID     *uuid.UUID
 	IsSystem   *bool
@@ -18,6 +25,7 @@ type CategoryFilter struct {
 
 type CategoryRepository interface {
 	Create(ctx context.Context, category *entity.Category) error
+	// GetByID คืนหมวดหมู่แม้จะ archive แล้ว เพื่อให้รายการเก่ายังอ่านชื่อหมวดหมู่ได้ ผู้เรียกที่จะผูกข้อมูลใหม่ต้องตรวจ IsArchived เอง
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Category, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Category, error)
 	GetSystemCategories(ctx context.Context) ([]*entity.Category, error)
@@ -27,4 +35,22 @@ type CategoryRepository interface {
 	Unarchive(ctx context.Context, id uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetCategoryUsageStats(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]int64, error)
+	// GetUsage คืนจำนวนรายการ รายการประจำ และงบของผู้ใช้ในแต่ละหมวดหมู่ที่ผู้ใช้เห็น (ระบบ + ส่วนตัว รวมที่ archive แล้ว)
+	GetUsage(ctx context.Context, userID uuid.UUID) ([]*entity.CategoryUsage, error)
+	// GetUsageByID นับทุกข้อมูลที่อ้างถึงหมวดหมู่ไม่ว่าจะเป็นของผู้ใช้คนไหน ใช้ตรวจก่อนลบ
+	GetUsageByID(ctx context.Context, categoryID uuid.UUID) (*entity.CategoryUsage, error)
+	// GetDescendantIDs คืน id ของหมวดหมู่ย่อยทุกระดับ (ไม่รวมตัวเอง)
+	GetDescendantIDs(ctx context.Context, categoryID uuid.UUID) ([]uuid.UUID, error)
+	// Merge ย้ายรายการ, รายการประจำ, งบ และหมวดหมู่ย่อยของผู้ใช้จาก sourceID ไป targetID แล้ว archive sourceID
+	// ทั้งหมดใน transaction เดียว คืน ErrBudgetPeriodMismatch ถ้างบ active ของทั้งสองหมวดหมู่ต่างรอบกัน
+	Merge(ctx context.Context, userID, sourceID, targetID uuid.UUID) (*entity.CategoryMergeResult, error)
+	// MergeAndDelete รวม sourceID เข้า targetID แบบเดียวกับ Merge แล้วลบ sourceID ใน transaction เดียวกัน
+	// คืน ErrCategoryInUse และไม่เปลี่ยนแปลงอะไรถ้ายังมีข้อมูลอ้างถึง sourceID หลังรวม
+	MergeAndDelete(ctx context.Context, userID, sourceID, targetID uuid.UUID) (*entity.CategoryMergeResult, error)
+	// GetOverrides คืน override หมวดหมู่ระบบทั้งหมดของผู้ใช้
+	GetOverrides(ctx context.Context, userID uuid.UUID) ([]*entity.CategoryOverride, error)
+	GetOverride(ctx context.Context, userID, categoryID uuid.UUID) (*entity.CategoryOverride, error)
+	// UpsertOverride สร้างหรือแทนที่ override ของ (UserID, CategoryID)
+	UpsertOverride(ctx context.Context, override *entity.CategoryOverride) error
+	DeleteOverride(ctx context.Context, userID, categoryID uuid.UUID) error
 }
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// ErrMuteExists คือ error เมื่อผู้ใช้ mute หมวดหมู่หรือประเภท insight เดิมซ้ำ
+var ErrMuteExists = errors.New("mute already exists")
+
+type InsightFeedbackRepository interface {
+	// SaveFeedback บันทึกคะแนน ถ้าเคยให้คะแนน insight นี้แล้วจะแทนที่ของเดิม
+	SaveFeedback(ctx context.Context, feedback *entity.InsightFeedback) error
+	GetFeedbackSummary(ctx context.Context, userID uuid.UUID, since time.Time) (map[entity.InsightType]*entity.InsightTypeFeedback, error)
+	// CreateMute คืน ErrMuteExists ถ้ามี mute เดียวกันอยู่แล้ว
+	CreateMute(ctx context.Context, mute *entity.InsightMute) error
+	GetMuteByID(ctx context.Context, id uuid.UUID) (*entity.InsightMute, error)
+	GetMutes(ctx context.Context, userID uuid.UUID) ([]*entity.InsightMute, error)
+	DeleteMute(ctx context.Context, id uuid.UUID) error
+}
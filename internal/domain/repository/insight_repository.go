@@ -15,20 +15,36 @@ type InsightFilter struct {
 	Priority  *entity.InsightPriority
 	IsRead    *bool
 	ValidOnly bool // filter out expired insights
-	Limit     int
-	Offset    int
+	// Inbox filters: dismissed and currently snoozed insights are hidden unless requested
+	IncludeDismissed bool
+	IncludeSnoozed   bool
+	IncludeResolved  bool
+	Limit            int
+	Offset           int
 }
 
 type InsightRepository interface {
 	Create(ctx context.Context, insight *entity.Insight) error
+	// Upsert สร้าง insight ใหม่ หรืออัปเดตแถวเดิมที่มี fingerprint เดียวกัน (insight.ID จะถูกแทนด้วย ID ของแถวเดิม)
+	Upsert(ctx context.Context, insight *entity.Insight) error
+	// ResolveStale ปิด insight ประเภทที่กำหนดซึ่ง fingerprint ไม่อยู่ใน activeFingerprints แล้ว
+	ResolveStale(ctx context.Context, userID uuid.UUID, insightTypes []entity.InsightType, activeFingerprints []string, resolvedAt time.Time) (int64, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Insight, error)
 	GetByFilter(ctx context.Context, filter InsightFilter) ([]*entity.Insight, error)
+	CountByFilter(ctx context.Context, filter InsightFilter) (int, error)
+	GetUnreadCountByType(ctx context.Context, userID uuid.UUID) (map[entity.InsightType]int, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*entity.Insight, error)
 	GetUnreadByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Insight, error)
 	MarkAsRead(ctx context.Context, id uuid.UUID) error
+	MarkAsUnread(ctx context.Context, id uuid.UUID) error
 	MarkAllAsRead(ctx context.Context, userID uuid.UUID) error
+	Dismiss(ctx context.Context, id uuid.UUID, dismissedAt time.Time) error
+	Snooze(ctx context.Context, id uuid.UUID, until time.Time) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	DeleteExpired(ctx context.Context, before time.Time) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+	// GetLastGeneratedAt คืนเวลาที่ generator สร้างหรือ Upsert insight ประเภทนี้ครั้งล่าสุด (nil ถ้ายังไม่เคย)
+	GetLastGeneratedAt(ctx context.Context, userID uuid.UUID, insightType entity.InsightType) (*time.Time, error)
 	GetSpendingAnomalies(ctx context.Context, userID uuid.UUID, months int) ([]*entity.SpendingAnomaly, error)
+	// GetSpendingPatterns จัดกลุ่มรายจ่ายตามหมวดหมู่ วัน และช่วงเวลา รายการที่ไม่มีเวลายังถูกนับในกลุ่มที่ TimeOfDay ว่าง
 	GetSpendingPatterns(ctx context.Context, userID uuid.UUID, days int) ([]*entity.SpendingPattern, error)
 }
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+type NetWorthRepository interface {
+	// Upsert บันทึก snapshot แทนที่ของเดิมถ้าผู้ใช้มี snapshot ของวันนั้นอยู่แล้ว
+	Upsert(ctx context.Context, snapshot *entity.NetWorthSnapshot) error
+	// GetByRange คืน snapshot ในช่วง [from, to] เรียงตามวันที่
+	GetByRange(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*entity.NetWorthSnapshot, error)
+}
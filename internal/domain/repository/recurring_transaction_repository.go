@@ -7,6 +7,7 @@ import (
 	"savvy-backend/internal/domain/entity"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type RecurringTransactionFilter struct {
@@ -28,4 +29,10 @@ type RecurringTransactionRepository interface {
 	UpdateNextExecutionDate(ctx context.Context, id uuid.UUID, nextDate time.Time) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	MarkAsExecuted(ctx context.Context, id uuid.UUID, executedAt time.Time) error
+
+	// Execute สร้างรายการจริง อัปเดตสถานะกฎ และบันทึกประวัติการรันใน transaction เดียวกัน
+	Execute(ctx context.Context, recurring *entity.RecurringTransaction, transaction *entity.Transaction, execution *entity.RecurringExecution) error
+
+	// Execution history (ใช้ประมาณยอดของรายการที่ยอดไม่คงที่)
+	GetRecentExecutionAmounts(ctx context.Context, recurringID uuid.UUID, limit int) ([]decimal.Decimal, error)
 }
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+type SpendingAnomalyFilter struct {
+	UserID       uuid.UUID
+	ReviewStatus *entity.AnomalyReviewStatus
+	Severity     *string
+	Limit        int
+	Offset       int
+}
+
+// SpendingAnomalyRepository เก็บผลการตรวจจับความผิดปกติรายรายการในตาราง spending_anomalies
+type SpendingAnomalyRepository interface {
+	// Create ไม่บันทึกซ้ำถ้ารายการเดียวกันถูกตรวจพบด้วยประเภทเดิมแล้ว
+	Create(ctx context.Context, anomaly *entity.TransactionAnomaly) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.TransactionAnomaly, error)
+	GetByFilter(ctx context.Context, filter SpendingAnomalyFilter) ([]*entity.TransactionAnomaly, error)
+	CountByFilter(ctx context.Context, filter SpendingAnomalyFilter) (int, error)
+	UpdateReviewStatus(ctx context.Context, id uuid.UUID, status entity.AnomalyReviewStatus, reviewedAt time.Time) error
+}
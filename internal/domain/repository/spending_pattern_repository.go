@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"savvy-backend/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// SpendingPatternRepository เก็บผลของงานวิเคราะห์รูปแบบการใช้จ่ายในตาราง spending_patterns
+type SpendingPatternRepository interface {
+	// ReplaceForUser แทนที่รูปแบบประเภทนั้นทั้งหมดของผู้ใช้ด้วยผลการวิเคราะห์รอบล่าสุด
+	ReplaceForUser(ctx context.Context, userID uuid.UUID, patternType string, patterns []*entity.SpendingPatternTrend) error
+	GetByUser(ctx context.Context, userID uuid.UUID, patternType string) ([]*entity.SpendingPatternTrend, error)
+}
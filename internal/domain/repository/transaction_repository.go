@@ -28,7 +28,16 @@ type TransactionRepository interface {
 	Create(ctx context.Context, transaction *entity.Transaction) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Transaction, error)
 	GetByFilter(ctx context.Context, filter TransactionFilter) ([]*entity.Transaction, error)
+	// GetRecentWithDetails ดึงรายการล่าสุดพร้อมชื่อหมวดหมู่และบัญชีด้วย join แทนการ lookup ทีละรายการ
+	GetRecentWithDetails(ctx context.Context, userID uuid.UUID, limit int) ([]*entity.TransactionDetail, error)
 	Update(ctx context.Context, transaction *entity.Transaction) error
+	// UpdateCategoryByFilter ย้ายทุกรายการที่ตรงกับ filter (ไม่สนใจ Limit/Offset) ไปหมวดหมู่ categoryID ใน transaction เดียว
+	// คืนค่าจำนวนรายการที่ถูกย้าย
+	UpdateCategoryByFilter(ctx context.Context, filter TransactionFilter, categoryID uuid.UUID) (int64, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetMonthlySpending(ctx context.Context, userID uuid.UUID, year int, month int) (map[uuid.UUID]float64, error)
+	// GetDiscretionarySpending รวมรายจ่ายที่ไม่ได้สร้างจากรายการประจำ แยกตามบัญชีและหมวดหมู่
+	GetDiscretionarySpending(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*entity.DiscretionarySpend, error)
+	// GetMonthlyCashFlow รวมรายรับ/รายจ่ายรายเดือนในช่วง [startDate, endDate) เดือนที่ไม่มีรายการจะไม่อยู่ในผลลัพธ์
+	GetMonthlyCashFlow(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*entity.MonthlyCashFlow, error)
 }
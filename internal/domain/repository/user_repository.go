@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"savvy-backend/internal/domain/entity"
@@ -9,6 +10,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrCalendarFeedNotFound คือ error เมื่อไม่มีผู้ใช้ที่ active ถือ token ของปฏิทินนี้ (token ผิด ถูกเปลี่ยน หรือถูกยกเลิก)
+var ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
 type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error)
@@ -16,4 +20,11 @@ type UserRepository interface {
 	Update(ctx context.Context, user *entity.User) error
 	UpdateLastLogin(ctx context.Context, userID uuid.UUID, loginTime time.Time) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	// GetByCalendarFeedToken คืน ErrCalendarFeedNotFound ถ้าไม่มีผู้ใช้ที่ active ถือ token นี้
+	GetByCalendarFeedToken(ctx context.Context, token string) (*entity.User, error)
+	UpdateCalendarFeedToken(ctx context.Context, userID uuid.UUID, token string) error
+	// ClearCalendarFeedToken ลบ token ของผู้ใช้ ทำให้ URL ปฏิทินเดิมใช้ไม่ได้อีก
+	ClearCalendarFeedToken(ctx context.Context, userID uuid.UUID) error
+	// GetActiveUserIDs คืน ID ของผู้ใช้ที่ active ทีละหน้า (เรียงตาม created_at) สำหรับงาน background
+	GetActiveUserIDs(ctx context.Context, limit, offset int) ([]uuid.UUID, error)
 }
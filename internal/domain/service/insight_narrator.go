@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/i18n"
+)
+
+// Narration คือหัวข้อและเนื้อหาของ insight ที่ narrator สร้างขึ้น
+// Message เก็บ key และ params ของ catalog ไว้เพื่อแสดงผลใหม่เป็นภาษาอื่นได้
+type Narration struct {
+	Title   string       `json:"title"`
+	Content string       `json:"content"`
+	Message i18n.Message `json:"-"`
+}
+
+// InsightNarrator แปลงผลการวิเคราะห์ที่มีโครงสร้างเป็นข้อความภาษาธรรมชาติตามภาษาและสกุลเงินของผู้ใช้
+// แต่ละครั้งที่เรียกจะได้รับข้อมูลของผู้ใช้คนเดียวเท่านั้น implementation ต้องไม่เก็บหรือรวมข้อมูลข้ามการเรียก
+type InsightNarrator interface {
+	NarrateAnomaly(ctx context.Context, audience i18n.Audience, anomaly *entity.SpendingAnomaly) (*Narration, error)
+	NarratePattern(ctx context.Context, audience i18n.Audience, pattern *entity.SpendingPattern) (*Narration, error)
+	NarrateBudgetAlert(ctx context.Context, audience i18n.Audience, progress *entity.BudgetProgress) (*Narration, error)
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ResponseCache เก็บ response ของ endpoint ที่อ่านอย่างเดียว
+// key ของแต่ละผู้ใช้ต้องมี UserVersion อยู่ด้วย เมื่อเรียก InvalidateUser version จะเปลี่ยนและ key เดิมทั้งหมดจะไม่ถูกอ่านอีก
+// (entry เก่าหมดอายุตาม TTL หรือถูกทิ้งเมื่อ cache เต็ม) ผู้เรียกควรอ่าน version ก่อนคำนวณ response
+// เพื่อไม่ให้ผลที่คำนวณจากข้อมูลก่อนการแก้ไขถูกเก็บไว้ใต้ version ใหม่
+type ResponseCache interface {
+	UserVersion(ctx context.Context, userID uuid.UUID) (uint64, error)
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	InvalidateUser(ctx context.Context, userID uuid.UUID) error
+}
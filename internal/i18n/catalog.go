@@ -0,0 +1,194 @@
+package i18n
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// Message คือข้อความที่ยังไม่ได้แปล เก็บ key และ params ไว้กับ insight เพื่อแสดงผลใหม่เป็นภาษาอื่นได้
+// key ใน catalog คือ "<Key>.title", "<Key>.content" และ "<Key>.action" (ถ้ามี)
+type Message struct {
+	Key    string                 `json:"key"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// Rendered คือข้อความที่แปลแล้ว Action ว่างถ้า message นั้นไม่มีปุ่ม action
+type Rendered struct {
+	Title   string
+	Content string
+	Action  string
+}
+
+// placeholder ใน template มีรูปแบบ {name} หรือ {name|format}
+// format: money (ใช้สกุลเงินของ audience), percent (ทศนิยม 1 ตำแหน่ง), label (แปลผ่าน "label.<ค่า>")
+var placeholderPattern = regexp.MustCompile(`\{([a-z_]+)(?:\|([a-z]+))?\}`)
+
+var catalogs = map[string]map[string]string{
+	LocaleThai: {
+		"anomaly.high.title":     "🚨 การใช้จ่าย {category} เพิ่มขึ้นอย่างมาก!",
+		"anomaly.high.content":   "เดือนนี้คุณใช้จ่ายในหมวด '{category}' ถึง {current_amount|money} เพิ่มขึ้น {percentage_increase|percent} จากค่าเฉลี่ย {average_amount|money} ลองตรวจสอบว่ามีรายจ่ายผิดปกติหรือไม่",
+		"anomaly.medium.title":   "⚠️ การใช้จ่าย {category} เพิ่มขึ้น",
+		"anomaly.medium.content": "เดือนนี้คุณใช้จ่ายในหมวด '{category}' {current_amount|money} เพิ่มขึ้น {percentage_increase|percent} จากค่าเฉลี่ย {average_amount|money}",
+		"anomaly.low.title":      "💡 การใช้จ่าย {category} เปลี่ยนแปลง",
+		"anomaly.low.content":    "เดือนนี้คุณใช้จ่ายในหมวด '{category}' {current_amount|money} เพิ่มขึ้น {percentage_increase|percent} จากค่าเฉลี่ย {average_amount|money}",
+
+		"pattern.title":   "📊 พฤติกรรมการใช้จ่าย {category}",
+		"pattern.content": "คุณมักใช้จ่ายหมวด '{category}' ในช่วง{time_of_day|label} ของวัน{day_of_week|label} โดยเฉลี่ย {average_amount|money}/ครั้ง ({frequency_count} ครั้งในเดือนที่ผ่านมา)",
+
+		"pattern.any_time.title":   "📊 พฤติกรรมการใช้จ่าย {category}",
+		"pattern.any_time.content": "คุณมักใช้จ่ายหมวด '{category}' ในวัน{day_of_week|label} โดยเฉลี่ย {average_amount|money}/ครั้ง ({frequency_count} ครั้งในเดือนที่ผ่านมา)",
+
+		"budget.near.title":   "งบประมาณ {category} ใกล้หมดแล้ว",
+		"budget.near.content": "คุณใช้งบประมาณหมวดหมู่ {category} ไปแล้ว {progress_percentage|percent} ({spent_amount|money} จาก {budget_amount|money})",
+		"budget.over.title":   "เกินงบประมาณ {category}",
+		"budget.over.content": "คุณใช้จ่ายหมวดหมู่ {category} เกินงบประมาณแล้ว {over_amount|money} ({progress_percentage|percent})",
+
+		"category_recommendation.title":   "💡 คำแนะนำหมวดหมู่",
+		"category_recommendation.content": "จากรายการ '{note}' ระบบแนะนำหมวดหมู่: {suggestions}",
+
+		"savings.title":   "💰 โอกาสประหยัด {category}",
+		"savings.content": "คุณใช้จ่าย '{category}' บ่อย ({frequency_count} ครั้ง/เดือน) เฉลี่ย {average_amount|money}/ครั้ง หากลดลง 20% จะประหยัดได้ {monthly_savings|money}/เดือน",
+
+		"subscription_detected.title":   "🔁 พบค่าบริการประจำ: {note}",
+		"subscription_detected.content": "พบรายการ '{note}' {occurrences} ครั้ง ทุก{frequency|label} ครั้งละประมาณ {amount|money} แต่ยังไม่ได้ตั้งเป็นรายการประจำ เพิ่มเป็นรายการประจำเพื่อไม่ให้ลืมค่าบริการนี้",
+		"subscription_detected.action":  "เพิ่มเป็นรายการประจำ",
+
+		"subscription_price_increase.title":   "📈 ค่าบริการ {note} ขึ้นราคา",
+		"subscription_price_increase.content": "ค่าบริการ '{note}' ที่เรียกเก็บล่าสุด {new_amount|money} เพิ่มขึ้น {percentage_increase|percent} จากเดิม {previous_amount|money} ลองตรวจสอบว่ายังคุ้มค่าอยู่หรือไม่",
+
+		"cashflow_warning.title":   "ยอดเงินในบัญชี {account} อาจติดลบ",
+		"cashflow_warning.content": "คาดว่ายอดคงเหลือในบัญชี {account} จะติดลบในวันที่ {date} (อีก {days} วัน) และอาจต่ำสุดถึง {lowest_balance|money} ลองโอนเงินเข้าบัญชีหรือเลื่อนรายจ่ายที่ไม่จำเป็น",
+
+		"label.Morning":   "เช้า",
+		"label.Afternoon": "บ่าย",
+		"label.Evening":   "เย็น",
+		"label.Night":     "กลางคืน",
+		"label.Monday":    "จันทร์",
+		"label.Tuesday":   "อังคาร",
+		"label.Wednesday": "พุธ",
+		"label.Thursday":  "พฤหัสบดี",
+		"label.Friday":    "ศุกร์",
+		"label.Saturday":  "เสาร์",
+		"label.Sunday":    "อาทิตย์",
+		"label.daily":     "วัน",
+		"label.weekly":    "สัปดาห์",
+		"label.monthly":   "เดือน",
+		"label.yearly":    "ปี",
+
+		"label.savings":          "เงินออม",
+		"label.existing_balance": "เงินคงเหลือเดิม",
+	},
+	LocaleEnglish: {
+		"anomaly.high.title":     "🚨 {category} spending jumped sharply!",
+		"anomaly.high.content":   "You spent {current_amount|money} on '{category}' this month, up {percentage_increase|percent} from your average of {average_amount|money}. Check for any unusual expenses.",
+		"anomaly.medium.title":   "⚠️ {category} spending is up",
+		"anomaly.medium.content": "You spent {current_amount|money} on '{category}' this month, up {percentage_increase|percent} from your average of {average_amount|money}.",
+		"anomaly.low.title":      "💡 {category} spending changed",
+		"anomaly.low.content":    "You spent {current_amount|money} on '{category}' this month, up {percentage_increase|percent} from your average of {average_amount|money}.",
+
+		"pattern.title":   "📊 Your {category} spending habit",
+		"pattern.content": "You often spend on '{category}' on {day_of_week|label} {time_of_day|label}s, {average_amount|money} per purchase on average ({frequency_count} times in the past month).",
+
+		"pattern.any_time.title":   "📊 Your {category} spending habit",
+		"pattern.any_time.content": "You often spend on '{category}' on {day_of_week|label}s, {average_amount|money} per purchase on average ({frequency_count} times in the past month).",
+
+		"budget.near.title":   "{category} budget is almost used up",
+		"budget.near.content": "You have used {progress_percentage|percent} of your {category} budget ({spent_amount|money} of {budget_amount|money}).",
+		"budget.over.title":   "Over budget: {category}",
+		"budget.over.content": "You are {over_amount|money} over your {category} budget ({progress_percentage|percent}).",
+
+		"category_recommendation.title":   "💡 Category suggestion",
+		"category_recommendation.content": "Based on '{note}', suggested categories: {suggestions}",
+
+		"savings.title":   "💰 Savings opportunity: {category}",
+		"savings.content": "You spend on '{category}' often ({frequency_count} times a month, {average_amount|money} on average). Cutting back 20% would save {monthly_savings|money} a month.",
+
+		"subscription_detected.title":   "🔁 Subscription found: {note}",
+		"subscription_detected.content": "'{note}' was charged {occurrences} times, once every {frequency|label}, about {amount|money} each time, but it is not set up as a recurring transaction. Add it so this charge is not forgotten.",
+		"subscription_detected.action":  "Add as recurring",
+
+		"subscription_price_increase.title":   "📈 {note} price went up",
+		"subscription_price_increase.content": "The latest '{note}' charge was {new_amount|money}, up {percentage_increase|percent} from {previous_amount|money}. Check whether it is still worth it.",
+
+		"cashflow_warning.title":   "{account} may go negative",
+		"cashflow_warning.content": "The balance of {account} is projected to go negative on {date} (in {days} days) and may drop to {lowest_balance|money}. Consider a transfer or postponing non-essential spending.",
+
+		"label.Morning":   "morning",
+		"label.Afternoon": "afternoon",
+		"label.Evening":   "evening",
+		"label.Night":     "night",
+		"label.Monday":    "Monday",
+		"label.Tuesday":   "Tuesday",
+		"label.Wednesday": "Wednesday",
+		"label.Thursday":  "Thursday",
+		"label.Friday":    "Friday",
+		"label.Saturday":  "Saturday",
+		"label.Sunday":    "Sunday",
+		"label.daily":     "day",
+		"label.weekly":    "week",
+		"label.monthly":   "month",
+		"label.yearly":    "year",
+
+		"label.savings":          "Savings",
+		"label.existing_balance": "Existing balance",
+	},
+}
+
+// Render แปลข้อความเป็นภาษาของ audience ถ้าไม่มี key ในภาษานั้นจะใช้ภาษาเริ่มต้น
+func Render(audience Audience, message Message) Rendered {
+	return Rendered{
+		Title:   renderTemplate(audience, lookup(audience.Locale, message.Key+".title"), message.Params),
+		Content: renderTemplate(audience, lookup(audience.Locale, message.Key+".content"), message.Params),
+		Action:  renderTemplate(audience, lookup(audience.Locale, message.Key+".action"), message.Params),
+	}
+}
+
+// Label แปลป้ายกำกับ "label.<name>" ตามภาษา คืน name เดิมถ้าไม่มีใน catalog
+func Label(locale, name string) string {
+	if label := lookup(locale, "label."+name); label != "" {
+		return label
+	}
+	return name
+}
+
+func lookup(locale, key string) string {
+	if text, ok := catalogs[locale][key]; ok {
+		return text
+	}
+	return catalogs[DefaultLocale][key]
+}
+
+func renderTemplate(audience Audience, template string, params map[string]interface{}) string {
+	return placeholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		match := placeholderPattern.FindStringSubmatch(placeholder)
+		value, ok := params[match[1]]
+		if !ok {
+			return placeholder
+		}
+
+		switch match[2] {
+		case "money":
+			amount, err := decimal.NewFromString(fmt.Sprint(value))
+			if err != nil {
+				return fmt.Sprint(value)
+			}
+			return FormatMoney(amount, audience.Currency, audience.Locale)
+		case "percent":
+			percentage, err := strconv.ParseFloat(fmt.Sprint(value), 64)
+			if err != nil {
+				return fmt.Sprint(value)
+			}
+			return fmt.Sprintf("%.1f%%", percentage)
+		case "label":
+			if label := lookup(audience.Locale, "label."+fmt.Sprint(value)); label != "" {
+				return label
+			}
+			return fmt.Sprint(value)
+		default:
+			return fmt.Sprint(value)
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package i18n
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"savvy-backend/internal/domain/entity"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRender(t *testing.T) {
+	english := NewAudience("en", "USD")
+	thai := NewAudience("th", "THB")
+
+	tests := []struct {
+		name        string
+		audience    Audience
+		message     Message
+		wantTitle   string
+		wantContent string
+		wantAction  string
+	}{
+		{
+			name:     "money and percent in english",
+			audience: english,
+			message: Message{Key: "budget.near", Params: map[string]interface{}{
+				"category": "Food", "progress_percentage": 85.25, "spent_amount": "850.5", "budget_amount": 1000,
+			}},
+			wantTitle:   "Food budget is almost used up",
+			wantContent: "You have used 85.2% of your Food budget ($850.50 of $1,000.00).",
+		},
+		{
+			name:     "same message in thai",
+			audience: thai,
+			message: Message{Key: "budget.near", Params: map[string]interface{}{
+				"category": "อาหาร", "progress_percentage": 85.25, "spent_amount": "850.5", "budget_amount": 1000,
+			}},
+			wantTitle:   "งบประมาณ อาหาร ใกล้หมดแล้ว",
+			wantContent: "คุณใช้งบประมาณหมวดหมู่ อาหาร ไปแล้ว 85.2% (850.50 บาท จาก 1,000.00 บาท)",
+		},
+		{
+			name:     "labels and action",
+			audience: english,
+			message: Message{Key: "subscription_detected", Params: map[string]interface{}{
+				"note": "Netflix", "occurrences": 4, "frequency": "monthly", "amount": "419",
+			}},
+			wantTitle:   "🔁 Subscription found: Netflix",
+			wantContent: "'Netflix' was charged 4 times, once every month, about $419.00 each time, but it is not set up as a recurring transaction. Add it so this charge is not forgotten.",
+			wantAction:  "Add as recurring",
+		},
+		{
+			name:     "unknown label falls back to value",
+			audience: english,
+			message: Message{Key: "pattern", Params: map[string]interface{}{
+				"category": "Coffee", "day_of_week": "Someday", "time_of_day": "Morning", "average_amount": 3.5, "frequency_count": 6,
+			}},
+			wantTitle:   "📊 Your Coffee spending habit",
+			wantContent: "You often spend on 'Coffee' on Someday mornings, $3.50 per purchase on average (6 times in the past month).",
+		},
+		{
+			name:     "pattern without time of day",
+			audience: english,
+			message: PatternMessage(&entity.SpendingPattern{
+				CategoryName: "Groceries", DayOfWeek: "Saturday", AverageAmount: decimal.NewFromInt(850), FrequencyCount: 4,
+			}),
+			wantTitle:   "📊 Your Groceries spending habit",
+			wantContent: "You often spend on 'Groceries' on Saturdays, $850.00 per purchase on average (4 times in the past month).",
+		},
+		{
+			name:     "missing param keeps placeholder and invalid money keeps value",
+			audience: english,
+			message: Message{Key: "budget.over", Params: map[string]interface{}{
+				"over_amount": "n/a", "progress_percentage": "abc",
+			}},
+			wantTitle:   "Over budget: {category}",
+			wantContent: "You are n/a over your {category} budget (abc).",
+		},
+		{
+			name:        "unsupported locale uses default catalog",
+			audience:    Audience{Locale: "fr", Currency: "THB"},
+			message:     Message{Key: "category_recommendation", Params: map[string]interface{}{"note": "กาแฟ", "suggestions": "อาหาร"}},
+			wantTitle:   "💡 คำแนะนำหมวดหมู่",
+			wantContent: "จากรายการ 'กาแฟ' ระบบแนะนำหมวดหมู่: อาหาร",
+		},
+		{
+			name:     "unknown key renders empty",
+			audience: english,
+			message:  Message{Key: "does_not_exist"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(tt.audience, tt.message)
+			if got.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", got.Title, tt.wantTitle)
+			}
+			if got.Content != tt.wantContent {
+				t.Errorf("Content = %q, want %q", got.Content, tt.wantContent)
+			}
+			if got.Action != tt.wantAction {
+				t.Errorf("Action = %q, want %q", got.Action, tt.wantAction)
+			}
+		})
+	}
+}
+
+// message ถูกเก็บเป็น JSON กับ insight ตัวเลขจึงกลับมาเป็น float64 ต้องแสดงผลได้เหมือนเดิม
+func TestRenderAfterJSONRoundTrip(t *testing.T) {
+	original := Message{Key: "budget.over", Params: map[string]interface{}{
+		"category": "Travel", "over_amount": "1250.4", "progress_percentage": 112.5,
+	}}
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded Message
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	audience := NewAudience("en", "EUR")
+	if got, want := Render(audience, decoded), Render(audience, original); got != want {
+		t.Fatalf("Render(decoded) = %+v, want %+v", got, want)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	tests := []struct {
+		locale string
+		name   string
+		want   string
+	}{
+		{locale: LocaleThai, name: "savings", want: "เงินออม"},
+		{locale: LocaleEnglish, name: "existing_balance", want: "Existing balance"},
+		{locale: "fr", name: "savings", want: "เงินออม"},
+		{locale: LocaleEnglish, name: "unknown", want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := Label(tt.locale, tt.name); got != tt.want {
+			t.Errorf("Label(%q, %q) = %q, want %q", tt.locale, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	for _, locale := range SupportedLocales {
+		for _, other := range SupportedLocales {
+			for key := range catalogs[locale] {
+				if _, ok := catalogs[other][key]; !ok {
+					t.Errorf("key %q exists in %q but not in %q", key, locale, other)
+				}
+			}
+		}
+	}
+}
+
+func TestCatalogPlaceholdersMatch(t *testing.T) {
+	for key, thai := range catalogs[LocaleThai] {
+		english, ok := catalogs[LocaleEnglish][key]
+		if !ok {
+			continue
+		}
+		if got, want := placeholders(english), placeholders(thai); got != want {
+			t.Errorf("key %q placeholders differ: en %s, th %s", key, got, want)
+		}
+	}
+}
+
+func placeholders(template string) string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, match := range placeholderPattern.FindAllString(template, -1) {
+		if !seen[match] {
+			seen[match] = true
+			names = append(names, match)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
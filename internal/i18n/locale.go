@@ -0,0 +1,61 @@
+package i18n
+
+import "strings"
+
+const (
+	LocaleThai    = "th"
+	LocaleEnglish = "en"
+
+	DefaultLocale   = LocaleThai
+	DefaultCurrency = "THB"
+)
+
+// SupportedLocales คือภาษาที่มีใน catalog
+var SupportedLocales = []string{LocaleThai, LocaleEnglish}
+
+// Audience คือภาษาและสกุลเงินที่ใช้แสดงข้อความให้ผู้ใช้คนหนึ่ง
+type Audience struct {
+	Locale   string
+	Currency string
+}
+
+func DefaultAudience() Audience {
+	return Audience{Locale: DefaultLocale, Currency: DefaultCurrency}
+}
+
+// NewAudience ใช้ค่าเริ่มต้นแทนภาษาที่ไม่รองรับหรือสกุลเงินที่ว่าง
+func NewAudience(locale, currency string) Audience {
+	audience := DefaultAudience()
+	if normalized := NormalizeLocale(locale); normalized != "" {
+		audience.Locale = normalized
+	}
+	if currency != "" {
+		audience.Currency = strings.ToUpper(currency)
+	}
+	return audience
+}
+
+// NormalizeLocale แปลง "en-US", "th_TH" เป็น "en", "th" และคืนค่าว่างถ้าไม่รองรับ
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+	for _, supported := range SupportedLocales {
+		if locale == supported {
+			return supported
+		}
+	}
+	return ""
+}
+
+// LocaleFromAcceptLanguage เลือกภาษาแรกที่รองรับจาก header Accept-Language
+func LocaleFromAcceptLanguage(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if locale := NormalizeLocale(tag); locale != "" {
+			return locale
+		}
+	}
+	return ""
+}
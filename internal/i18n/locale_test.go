@@ -0,0 +1,63 @@
+package i18n
+
+import "testing"
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "th", want: LocaleThai},
+		{input: "en", want: LocaleEnglish},
+		{input: "en-US", want: LocaleEnglish},
+		{input: "th_TH", want: LocaleThai},
+		{input: "  EN-gb ", want: LocaleEnglish},
+		{input: "TH", want: LocaleThai},
+		{input: "fr", want: ""},
+		{input: "fr-TH", want: ""},
+		{input: "", want: ""},
+		{input: "-en", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeLocale(tt.input); got != tt.want {
+			t.Errorf("NormalizeLocale(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleFromAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "en-US,en;q=0.9", want: LocaleEnglish},
+		{header: "fr-FR, th;q=0.8, en;q=0.5", want: LocaleThai},
+		{header: "de, fr", want: ""},
+		{header: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := LocaleFromAcceptLanguage(tt.header); got != tt.want {
+			t.Errorf("LocaleFromAcceptLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestNewAudience(t *testing.T) {
+	tests := []struct {
+		locale   string
+		currency string
+		want     Audience
+	}{
+		{locale: "en-US", currency: "usd", want: Audience{Locale: LocaleEnglish, Currency: "USD"}},
+		{locale: "fr", currency: "", want: DefaultAudience()},
+		{locale: "", currency: "eur", want: Audience{Locale: DefaultLocale, Currency: "EUR"}},
+	}
+
+	for _, tt := range tests {
+		if got := NewAudience(tt.locale, tt.currency); got != tt.want {
+			t.Errorf("NewAudience(%q, %q) = %+v, want %+v", tt.locale, tt.currency, got, tt.want)
+		}
+	}
+}
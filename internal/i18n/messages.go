@@ -0,0 +1,75 @@
+package i18n
+
+import (
+	"strings"
+
+	"savvy-backend/internal/domain/entity"
+)
+
+// ข้อความของ insight ที่ narrator ทุกตัวใช้ร่วมกัน จำนวนเงินเก็บเป็น string เพื่อไม่ให้เสียความแม่นยำ
+
+func AnomalyMessage(anomaly *entity.SpendingAnomaly) Message {
+	severity := anomaly.Severity
+	if severity != "high" && severity != "medium" {
+		severity = "low"
+	}
+
+	return Message{
+		Key: "anomaly." + severity,
+		Params: map[string]interface{}{
+			"category":            anomaly.CategoryName,
+			"current_amount":      anomaly.CurrentAmount.StringFixed(2),
+			"average_amount":      anomaly.AverageAmount.StringFixed(2),
+			"percentage_increase": anomaly.PercentageIncrease,
+		},
+	}
+}
+
+// PatternMessage ใช้ข้อความที่ไม่ระบุช่วงเวลาเมื่อรูปแบบมาจากรายการที่ไม่มีเวลา
+func PatternMessage(pattern *entity.SpendingPattern) Message {
+	if pattern.TimeOfDay == "" {
+		return Message{
+			Key: "pattern.any_time",
+			Params: map[string]interface{}{
+				"category":        pattern.CategoryName,
+				"day_of_week":     strings.TrimSpace(pattern.DayOfWeek),
+				"average_amount":  pattern.AverageAmount.StringFixed(2),
+				"frequency_count": pattern.FrequencyCount,
+			},
+		}
+	}
+
+	return Message{
+		Key: "pattern",
+		Params: map[string]interface{}{
+			"category":        pattern.CategoryName,
+			"day_of_week":     strings.TrimSpace(pattern.DayOfWeek),
+			"time_of_day":     pattern.TimeOfDay,
+			"average_amount":  pattern.AverageAmount.StringFixed(2),
+			"frequency_count": pattern.FrequencyCount,
+		},
+	}
+}
+
+func BudgetAlertMessage(progress *entity.BudgetProgress) Message {
+	if progress.IsOverBudget {
+		return Message{
+			Key: "budget.over",
+			Params: map[string]interface{}{
+				"category":            progress.CategoryName,
+				"over_amount":         progress.SpentAmount.Sub(progress.BudgetAmount).StringFixed(2),
+				"progress_percentage": progress.ProgressPercentage,
+			},
+		}
+	}
+
+	return Message{
+		Key: "budget.near",
+		Params: map[string]interface{}{
+			"category":            progress.CategoryName,
+			"spent_amount":        progress.SpentAmount.StringFixed(2),
+			"budget_amount":       progress.BudgetAmount.StringFixed(2),
+			"progress_percentage": progress.ProgressPercentage,
+		},
+	}
+}
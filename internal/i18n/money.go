@@ -0,0 +1,68 @@
+package i18n
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+var currencySymbols = map[string]string{
+	"THB": "฿",
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+}
+
+// สกุลเงินที่ไม่มีทศนิยม
+var zeroDecimalCurrencies = map[string]bool{
+	"JPY": true,
+	"KRW": true,
+	"VND": true,
+}
+
+// FormatMoney จัดรูปแบบจำนวนเงินตามสกุลเงินและภาษา
+// ภาษาไทยกับเงินบาทใช้ "1,234.56 บาท" แบบเดิม ส่วนกรณีอื่นใช้สัญลักษณ์นำหน้า หรือรหัสสกุลเงินต่อท้ายถ้าไม่รู้จัก
+func FormatMoney(amount decimal.Decimal, currency, locale string) string {
+	currency = strings.ToUpper(currency)
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	places := int32(2)
+	if zeroDecimalCurrencies[currency] {
+		places = 0
+	}
+
+	sign := ""
+	if amount.IsNegative() {
+		sign = "-"
+		amount = amount.Neg()
+	}
+	number := groupThousands(amount.StringFixed(places))
+
+	if currency == "THB" && locale == LocaleThai {
+		return sign + number + " บาท"
+	}
+	if symbol, ok := currencySymbols[currency]; ok {
+		return sign + symbol + number
+	}
+	return sign + number + " " + currency
+}
+
+func groupThousands(number string) string {
+	integer, fraction := number, ""
+	if i := strings.IndexByte(number, '.'); i >= 0 {
+		integer, fraction = number[:i], number[i:]
+	}
+
+	var b strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+
+	return b.String() + fraction
+}
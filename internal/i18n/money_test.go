@@ -0,0 +1,40 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		currency string
+		locale   string
+		want     string
+	}{
+		{name: "thai baht in thai", amount: "1234.5", currency: "THB", locale: LocaleThai, want: "1,234.50 บาท"},
+		{name: "thai baht in english", amount: "1234.5", currency: "THB", locale: LocaleEnglish, want: "฿1,234.50"},
+		{name: "empty currency defaults to baht", amount: "99", currency: "", locale: LocaleThai, want: "99.00 บาท"},
+		{name: "lowercase currency", amount: "10", currency: "usd", locale: LocaleEnglish, want: "$10.00"},
+		{name: "euro", amount: "1000000", currency: "EUR", locale: LocaleEnglish, want: "€1,000,000.00"},
+		{name: "zero decimal currency rounds", amount: "1234.56", currency: "JPY", locale: LocaleEnglish, want: "¥1,235"},
+		{name: "unknown currency uses code suffix", amount: "50.1", currency: "SGD", locale: LocaleEnglish, want: "50.10 SGD"},
+		{name: "zero decimal without symbol", amount: "15000", currency: "KRW", locale: LocaleThai, want: "15,000 KRW"},
+		{name: "negative", amount: "-1500.75", currency: "USD", locale: LocaleEnglish, want: "-$1,500.75"},
+		{name: "negative thai", amount: "-20", currency: "THB", locale: LocaleThai, want: "-20.00 บาท"},
+		{name: "zero", amount: "0", currency: "GBP", locale: LocaleEnglish, want: "£0.00"},
+		{name: "rounds half up", amount: "0.005", currency: "USD", locale: LocaleEnglish, want: "$0.01"},
+		{name: "below one thousand", amount: "999.99", currency: "USD", locale: LocaleEnglish, want: "$999.99"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatMoney(decimal.RequireFromString(tt.amount), tt.currency, tt.locale)
+			if got != tt.want {
+				t.Fatalf("FormatMoney(%s, %q, %q) = %q, want %q", tt.amount, tt.currency, tt.locale, got, tt.want)
+			}
+		})
+	}
+}
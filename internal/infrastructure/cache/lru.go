@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"savvy-backend/internal/domain/service"
+
+	"github.com/google/uuid"
+)
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// lruCache เป็น cache ในหน่วยความจำของ process เดียว เมื่อเต็มจะทิ้ง entry ที่ไม่ได้ใช้นานที่สุด
+// ใช้ได้เมื่อรัน server instance เดียว ถ้ามีหลาย instance ให้ใช้ Redis เพื่อให้การ invalidate เห็นตรงกัน
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+	versions map[uuid.UUID]uint64
+}
+
+func NewLRUCache(capacity int) service.ResponseCache {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+
+	return &lruCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		versions: make(map[uuid.UUID]uint64),
+	}
+}
+
+func (c *lruCache) UserVersion(ctx context.Context, userID uuid.UUID) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.versions[userID], nil
+}
+
+func (c *lruCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.remove(element)
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+func (c *lruCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &lruEntry{
+		key:       key,
+		value:     value,
+		expiresAt: time.Now().Add(ttl),
+	}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+
+	return nil
+}
+
+func (c *lruCache) InvalidateUser(ctx context.Context, userID uuid.UUID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.versions[userID]++
+	return nil
+}
+
+func (c *lruCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruEntry).key)
+}
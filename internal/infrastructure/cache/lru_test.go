@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewLRUCache(2)
+	ctx := context.Background()
+
+	cache.Set(ctx, "a", []byte("1"), time.Minute)
+	cache.Set(ctx, "b", []byte("2"), time.Minute)
+
+	// ใช้ a ล่าสุด ทำให้ b เป็นตัวที่ถูกทิ้งเมื่อเพิ่ม c
+	if _, found, _ := cache.Get(ctx, "a"); !found {
+		t.Fatal("Get(a) missed before eviction")
+	}
+	cache.Set(ctx, "c", []byte("3"), time.Minute)
+
+	tests := []struct {
+		key   string
+		found bool
+	}{
+		{key: "a", found: true},
+		{key: "b", found: false},
+		{key: "c", found: true},
+	}
+	for _, tt := range tests {
+		if _, found, _ := cache.Get(ctx, tt.key); found != tt.found {
+			t.Errorf("Get(%s) found = %v, want %v", tt.key, found, tt.found)
+		}
+	}
+}
+
+func TestLRUCacheOverwriteDoesNotGrow(t *testing.T) {
+	cache := NewLRUCache(2).(*lruCache)
+	ctx := context.Background()
+
+	cache.Set(ctx, "a", []byte("1"), time.Minute)
+	cache.Set(ctx, "a", []byte("2"), time.Minute)
+
+	if cache.order.Len() != 1 {
+		t.Fatalf("entries = %d, want 1", cache.order.Len())
+	}
+	if value, _, _ := cache.Get(ctx, "a"); string(value) != "2" {
+		t.Fatalf("Get(a) = %q, want \"2\"", value)
+	}
+}
+
+func TestLRUCacheExpiry(t *testing.T) {
+	cache := NewLRUCache(10).(*lruCache)
+	ctx := context.Background()
+
+	cache.Set(ctx, "fresh", []byte("1"), time.Minute)
+	cache.Set(ctx, "stale", []byte("2"), time.Minute)
+	cache.entries["stale"].Value.(*lruEntry).expiresAt = time.Now().Add(-time.Second)
+
+	if _, found, _ := cache.Get(ctx, "fresh"); !found {
+		t.Error("Get(fresh) missed")
+	}
+	if _, found, _ := cache.Get(ctx, "stale"); found {
+		t.Error("Get(stale) returned an expired entry")
+	}
+	if _, ok := cache.entries["stale"]; ok {
+		t.Error("expired entry was not removed")
+	}
+}
+
+func TestLRUCacheSkipsNonPositiveTTL(t *testing.T) {
+	cache := NewLRUCache(10)
+	ctx := context.Background()
+
+	for _, ttl := range []time.Duration{0, -time.Second} {
+		cache.Set(ctx, "key", []byte("value"), ttl)
+		if _, found, _ := cache.Get(ctx, "key"); found {
+			t.Fatalf("Set(ttl=%v) stored an entry", ttl)
+		}
+	}
+}
+
+func TestLRUCacheUserVersion(t *testing.T) {
+	cache := NewLRUCache(10)
+	ctx := context.Background()
+	userID, otherID := uuid.New(), uuid.New()
+
+	cache.InvalidateUser(ctx, userID)
+	cache.InvalidateUser(ctx, userID)
+
+	if version, _ := cache.UserVersion(ctx, userID); version != 2 {
+		t.Errorf("UserVersion(user) = %d, want 2", version)
+	}
+	if version, _ := cache.UserVersion(ctx, otherID); version != 0 {
+		t.Errorf("UserVersion(other) = %d, want 0", version)
+	}
+}
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"savvy-backend/internal/domain/service"
+
+	"github.com/google/uuid"
+)
+
+// noopCache ใช้เมื่อปิด cache ทุกการอ่านเป็น miss แต่ HTTP ETag ยังทำงานได้ตามปกติ
+type noopCache struct{}
+
+func NewNoopCache() service.ResponseCache {
+	return noopCache{}
+}
+
+func (noopCache) UserVersion(ctx context.Context, userID uuid.UUID) (uint64, error) {
+	return 0, nil
+}
+
+func (noopCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+func (noopCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return nil
+}
+
+func (noopCache) InvalidateUser(ctx context.Context, userID uuid.UUID) error {
+	return nil
+}
@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"savvy-backend/internal/config"
+	"savvy-backend/internal/domain/service"
+
+	"github.com/google/uuid"
+)
+
+const redisKeyPrefix = "savvy:cache:"
+
+var errRedisNil = errors.New("redis: nil reply")
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// redisCache ใช้ server ที่เข้ากันได้กับ Redis protocol (Redis, Valkey, KeyDB, ...) ร่วมกันได้หลาย instance
+// ใช้เฉพาะคำสั่ง GET/SET/INCR จึงพูด RESP โดยตรงแทนการเพิ่ม dependency
+type redisCache struct {
+	cfg  config.CacheConfig
+	pool chan *redisConn
+}
+
+func NewRedisCache(cfg *config.CacheConfig) service.ResponseCache {
+	poolSize := cfg.RedisPoolSize
+	if poolSize <= 0 {
+		poolSize = 10
+	}
+
+	return &redisCache{
+		cfg:  *cfg,
+		pool: make(chan *redisConn, poolSize),
+	}
+}
+
+func (r *redisCache) UserVersion(ctx context.Context, userID uuid.UUID) (uint64, error) {
+	reply, err := r.do(ctx, "GET", versionKey(userID))
+	if errors.Is(err, errRedisNil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseUint(string(reply.([]byte)), 10, 64)
+}
+
+func (r *redisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := r.do(ctx, "GET", redisKeyPrefix+key)
+	if errors.Is(err, errRedisNil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return reply.([]byte), true, nil
+}
+
+// Set ไม่เก็บค่าที่ ttl น้อยกว่า 1ms เพราะ Redis ไม่รับ PX 0 และ entry ที่ไม่มีวันหมดอายุจะค้างอยู่ตลอดไป
+func (r *redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl < time.Millisecond {
+		return nil
+	}
+
+	_, err := r.do(ctx, "SET", redisKeyPrefix+key, string(value), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	return err
+}
+
+func (r *redisCache) InvalidateUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.do(ctx, "INCR", versionKey(userID))
+	return err
+}
+
+func versionKey(userID uuid.UUID) string {
+	return redisKeyPrefix + "version:" + userID.String()
+}
+
+// do ส่งคำสั่งหนึ่งคำสั่งผ่าน connection จาก pool connection ที่เกิด error จะถูกปิดทิ้งไม่คืนเข้า pool
+func (r *redisCache) do(ctx context.Context, args ...string) (interface{}, error) {
+	conn, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(r.cfg.RedisTimeout)
+	}
+	conn.conn.SetDeadline(deadline)
+
+	reply, err := conn.command(args...)
+	if err != nil && !errors.Is(err, errRedisNil) {
+		var replyErr redisError
+		if !errors.As(err, &replyErr) {
+			conn.conn.Close()
+			return nil, err
+		}
+	}
+
+	select {
+	case r.pool <- conn:
+	default:
+		conn.conn.Close()
+	}
+
+	return reply, err
+}
+
+func (r *redisCache) acquire(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-r.pool:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: r.cfg.RedisTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", r.cfg.RedisAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	netConn.SetDeadline(time.Now().Add(r.cfg.RedisTimeout))
+
+	conn := &redisConn{conn: netConn, reader: bufio.NewReader(netConn)}
+	if r.cfg.RedisPassword != "" {
+		if _, err := conn.command("AUTH", r.cfg.RedisPassword); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("failed to authenticate with redis: %w", err)
+		}
+	}
+	if r.cfg.RedisDB != 0 {
+		if _, err := conn.command("SELECT", strconv.Itoa(r.cfg.RedisDB)); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("failed to select redis database: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+func (c *redisConn) command(args ...string) (interface{}, error) {
+	request := make([]byte, 0, 64)
+	request = append(request, '*')
+	request = strconv.AppendInt(request, int64(len(args)), 10)
+	request = append(request, '\r', '\n')
+	for _, arg := range args {
+		request = append(request, '$')
+		request = strconv.AppendInt(request, int64(len(arg)), 10)
+		request = append(request, '\r', '\n')
+		request = append(request, arg...)
+		request = append(request, '\r', '\n')
+	}
+
+	if _, err := c.conn.Write(request); err != nil {
+		return nil, err
+	}
+
+	return c.readReply()
+}
+
+// readReply อ่าน reply แบบ simple string, error, integer และ bulk string ซึ่งครอบคลุมคำสั่งที่ใช้
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	payload := line[1 : len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, redisError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed bulk length %q", payload)
+		}
+		if size < 0 {
+			return nil, errRedisNil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	default:
+		return nil, fmt.Errorf("redis: unsupported reply type %q", line[0])
+	}
+}
@@ -0,0 +1,349 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"savvy-backend/internal/config"
+
+	"github.com/google/uuid"
+)
+
+// fakeRedis เป็น server RESP ขนาดเล็กที่รองรับเฉพาะคำสั่งที่ redisCache ใช้
+type fakeRedis struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	data     map[string]string
+	commands [][]string
+	accepted int
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	server := &fakeRedis{listener: listener, data: make(map[string]string)}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+
+	return server
+}
+
+func (s *fakeRedis) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.accepted++
+		s.mu.Unlock()
+
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, args)
+		reply := s.reply(args)
+		s.mu.Unlock()
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeRedis) reply(args []string) string {
+	switch strings.ToUpper(args[0]) {
+	case "GET":
+		value, ok := s.data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "SET":
+		if len(args) == 5 && args[3] == "PX" && args[4] == "0" {
+			return "-ERR invalid expire time in 'set' command\r\n"
+		}
+		s.data[args[1]] = args[2]
+		return "+OK\r\n"
+	case "INCR":
+		current, _ := strconv.ParseInt(s.data[args[1]], 10, 64)
+		current++
+		s.data[args[1]] = strconv.FormatInt(current, 10)
+		return fmt.Sprintf(":%d\r\n", current)
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+func (s *fakeRedis) acceptedConnections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accepted
+}
+
+func (s *fakeRedis) commandCount(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, args := range s.commands {
+		if strings.EqualFold(args[0], name) {
+			count++
+		}
+	}
+	return count
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected command %q", line)
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(header[1:]))
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args = append(args, string(data[:size]))
+	}
+
+	return args, nil
+}
+
+func newTestRedisCache(server *fakeRedis) *redisCache {
+	return NewRedisCache(&config.CacheConfig{
+		RedisAddr:     server.listener.Addr().String(),
+		RedisPoolSize: 2,
+		RedisTimeout:  time.Second,
+	}).(*redisCache)
+}
+
+func TestRedisCacheGetSet(t *testing.T) {
+	server := newFakeRedis(t)
+	cache := newTestRedisCache(server)
+	ctx := context.Background()
+
+	if _, found, err := cache.Get(ctx, "missing"); err != nil || found {
+		t.Fatalf("Get(missing) = found %v, err %v; want miss without error", found, err)
+	}
+
+	if err := cache.Set(ctx, "key", []byte("value"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	value, found, err := cache.Get(ctx, "key")
+	if err != nil || !found || string(value) != "value" {
+		t.Fatalf("Get(key) = %q, found %v, err %v; want \"value\"", value, found, err)
+	}
+}
+
+func TestRedisCacheSetSkipsNonPositiveTTL(t *testing.T) {
+	server := newFakeRedis(t)
+	cache := newTestRedisCache(server)
+
+	for _, ttl := range []time.Duration{0, -time.Second, time.Microsecond} {
+		if err := cache.Set(context.Background(), "key", []byte("value"), ttl); err != nil {
+			t.Fatalf("Set(ttl=%v) error = %v", ttl, err)
+		}
+	}
+
+	if got := server.commandCount("SET"); got != 0 {
+		t.Fatalf("SET sent %d time(s), want 0", got)
+	}
+}
+
+func TestRedisCacheUserVersion(t *testing.T) {
+	server := newFakeRedis(t)
+	cache := newTestRedisCache(server)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	version, err := cache.UserVersion(ctx, userID)
+	if err != nil || version != 0 {
+		t.Fatalf("UserVersion() before invalidate = %d, %v; want 0", version, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := cache.InvalidateUser(ctx, userID); err != nil {
+			t.Fatalf("InvalidateUser() error = %v", err)
+		}
+	}
+
+	version, err = cache.UserVersion(ctx, userID)
+	if err != nil || version != 2 {
+		t.Fatalf("UserVersion() after two invalidations = %d, %v; want 2", version, err)
+	}
+}
+
+func TestRedisCacheErrorReplyKeepsConnection(t *testing.T) {
+	server := newFakeRedis(t)
+	cache := newTestRedisCache(server)
+	ctx := context.Background()
+
+	_, err := cache.do(ctx, "BOGUS")
+	var replyErr redisError
+	if !errors.As(err, &replyErr) {
+		t.Fatalf("do(BOGUS) error = %v, want redisError", err)
+	}
+
+	if _, _, err := cache.Get(ctx, "key"); err != nil {
+		t.Fatalf("Get() after error reply: %v", err)
+	}
+
+	if got := server.acceptedConnections(); got != 1 {
+		t.Fatalf("accepted %d connection(s), want 1 (error replies must not drop the connection)", got)
+	}
+}
+
+func TestRedisCachePoolReuse(t *testing.T) {
+	server := newFakeRedis(t)
+	cache := newTestRedisCache(server)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		if _, _, err := cache.Get(ctx, "key"); err != nil {
+			t.Fatalf("Get() #%d error = %v", i, err)
+		}
+	}
+
+	if got := server.acceptedConnections(); got != 1 {
+		t.Fatalf("accepted %d connection(s), want 1", got)
+	}
+}
+
+func TestRedisCacheDialFailure(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	cache := NewRedisCache(&config.CacheConfig{RedisAddr: addr, RedisTimeout: 100 * time.Millisecond})
+	if _, _, err := cache.Get(context.Background(), "key"); err == nil {
+		t.Fatal("Get() against a closed port returned no error")
+	}
+}
+
+func TestRedisConnReadReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    interface{}
+		wantErr error
+		anyErr  bool
+	}{
+		{name: "simple string", input: "+OK\r\n", want: "OK"},
+		{name: "integer", input: ":42\r\n", want: int64(42)},
+		{name: "bulk string", input: "$5\r\nhello\r\n", want: []byte("hello")},
+		{name: "empty bulk string", input: "$0\r\n\r\n", want: []byte{}},
+		{name: "nil bulk string", input: "$-1\r\n", wantErr: errRedisNil},
+		{name: "error reply", input: "-ERR boom\r\n", wantErr: redisError("ERR boom")},
+		{name: "missing carriage return", input: "+OK\n", anyErr: true},
+		{name: "bad bulk length", input: "$abc\r\n", anyErr: true},
+		{name: "truncated bulk", input: "$5\r\nhel", anyErr: true},
+		{name: "unsupported type", input: "*1\r\n", anyErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &redisConn{reader: bufio.NewReader(strings.NewReader(tt.input))}
+			got, err := conn.readReply()
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("readReply() error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.anyErr:
+				if err == nil {
+					t.Fatalf("readReply() = %v, want error", got)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("readReply() error = %v", err)
+				}
+				if fmt.Sprintf("%#v", got) != fmt.Sprintf("%#v", tt.want) {
+					t.Fatalf("readReply() = %#v, want %#v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRedisConnCommandOverPipe(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		args, err := readCommand(bufio.NewReader(server))
+		if err != nil {
+			close(received)
+			return
+		}
+		received <- args
+		io.WriteString(server, ":7\r\n")
+	}()
+
+	conn := &redisConn{conn: client, reader: bufio.NewReader(client)}
+	reply, err := conn.command("INCR", "counter")
+	if err != nil {
+		t.Fatalf("command() error = %v", err)
+	}
+	if reply != int64(7) {
+		t.Fatalf("command() = %#v, want int64(7)", reply)
+	}
+
+	args := <-received
+	if strings.Join(args, " ") != "INCR counter" {
+		t.Fatalf("server received %q, want \"INCR counter\"", args)
+	}
+}
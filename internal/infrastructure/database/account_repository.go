@@ -9,6 +9,7 @@ import (
 	"savvy-backend/internal/domain/repository"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type accountRepository struct {
@@ -122,3 +123,105 @@ func (r *accountRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
+
+func (r *accountRepository) GetCurrentBalances(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]decimal.Decimal, error) {
+	query := `
+		SELECT a.id,
+			   a.initial_balance
+			   + COALESCE(SUM(CASE WHEN t.type = 'income' THEN t.amount ELSE 0 END), 0)
+			   - COALESCE(SUM(CASE WHEN t.type = 'expense' THEN t.amount ELSE 0 END), 0) as balance
+		FROM accounts a
+		LEFT JOIN transactions t ON t.account_id = a.id AND t.user_id = a.user_id
+		WHERE a.user_id = $1
+		GROUP BY a.id, a.initial_balance
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := make(map[uuid.UUID]decimal.Decimal)
+	for rows.Next() {
+		var accountID uuid.UUID
+		var balance decimal.Decimal
+		if err := rows.Scan(&accountID, &balance); err != nil {
+			return nil, err
+		}
+		balances[accountID] = balance
+	}
+
+	return balances, rows.Err()
+}
+
+func (r *accountRepository) GetBalancesAsOf(ctx context.Context, userID uuid.UUID, asOf time.Time) (map[uuid.UUID]decimal.Decimal, error) {
+	query := `
+		SELECT a.id,
+			   a.initial_balance
+			   + COALESCE(SUM(CASE WHEN t.type = 'income' THEN t.amount ELSE 0 END), 0)
+			   - COALESCE(SUM(CASE WHEN t.type = 'expense' THEN t.amount ELSE 0 END), 0) as balance
+		FROM accounts a
+		LEFT JOIN transactions t ON t.account_id = a.id AND t.user_id = a.user_id AND t.transaction_date <= $2
+		WHERE a.user_id = $1
+		GROUP BY a.id, a.initial_balance
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := make(map[uuid.UUID]decimal.Decimal)
+	for rows.Next() {
+		var accountID uuid.UUID
+		var balance decimal.Decimal
+		if err := rows.Scan(&accountID, &balance); err != nil {
+			return nil, err
+		}
+		balances[accountID] = balance
+	}
+
+	return balances, rows.Err()
+}
+
+func (r *accountRepository) GetMonthEndBalances(ctx context.Context, userID uuid.UUID, from, to time.Time) (map[string]map[uuid.UUID]decimal.Decimal, error) {
+	// สร้างเดือนด้วย generate_series แล้วรวมรายการที่ก่อนวันแรกของเดือนถัดไป ได้ยอดทุกเดือนใน query เดียว
+	query := `
+		SELECT m.month_start, a.id,
+			   a.initial_balance
+			   + COALESCE(SUM(CASE WHEN t.type = 'income' THEN t.amount ELSE 0 END), 0)
+			   - COALESCE(SUM(CASE WHEN t.type = 'expense' THEN t.amount ELSE 0 END), 0) as balance
+		FROM generate_series(date_trunc('month', $2::date), date_trunc('month', $3::date), interval '1 month') AS m(month_start)
+		CROSS JOIN accounts a
+		LEFT JOIN transactions t ON t.account_id = a.id AND t.user_id = a.user_id
+			AND t.transaction_date < m.month_start + interval '1 month'
+		WHERE a.user_id = $1
+		GROUP BY m.month_start, a.id, a.initial_balance
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := make(map[string]map[uuid.UUID]decimal.Decimal)
+	for rows.Next() {
+		var monthStart time.Time
+		var accountID uuid.UUID
+		var balance decimal.Decimal
+		if err := rows.Scan(&monthStart, &accountID, &balance); err != nil {
+			return nil, err
+		}
+
+		month := monthStart.Format("2006-01")
+		if balances[month] == nil {
+			balances[month] = make(map[uuid.UUID]decimal.Decimal)
+		}
+		balances[month][accountID] = balance
+	}
+
+	return balances, rows.Err()
+}
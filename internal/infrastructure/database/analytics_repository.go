@@ -0,0 +1,383 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+type analyticsRepository struct {
+	db *sql.DB
+}
+
+func NewAnalyticsRepository(db *sql.DB) repository.AnalyticsRepository {
+	return &analyticsRepository{db: db}
+}
+
+func (r *analyticsRepository) GetCategoryTotals(ctx context.Context, userID uuid.UUID, transactionType entity.TransactionType, startDate, endDate time.Time) ([]*entity.CategoryTotal, error) {
+	query := `
+		SELECT d.category_id, COALESCE(co.name, c.name), COALESCE(co.icon_name, c.icon_name), COALESCE(co.color_hex, c.color_hex),
+			   SUM(d.total) as total, SUM(d.transaction_count) as count
+		FROM daily_category_totals d
+		INNER JOIN categories c ON c.id = d.category_id
+		LEFT JOIN category_overrides co ON co.category_id = c.id AND co.user_id = d.user_id
+		WHERE d.user_id = $1
+		AND d.type = $2
+		AND d.day >= $3
+		AND d.day <= $4
+		GROUP BY 1, 2, 3, 4
+		ORDER BY total DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, transactionType, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []*entity.CategoryTotal
+	for rows.Next() {
+		total := &entity.CategoryTotal{}
+		if err := rows.Scan(&total.CategoryID, &total.CategoryName, &total.IconName, &total.ColorHex, &total.Total, &total.Count); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+
+	return totals, rows.Err()
+}
+
+func (r *analyticsRepository) GetCategoryTotalsAtDepth(ctx context.Context, userID uuid.UUID, transactionType entity.TransactionType, startDate, endDate time.Time, depth int) ([]*entity.CategoryTotal, error) {
+	// tree.path คือ id จากหมวดหมู่หลักลงมาถึงหมวดหมู่นั้น path[depth] จึงเป็นบรรพบุรุษระดับ depth
+	// หมวดหมู่ที่ไม่อยู่ในต้นไม้ (เช่น parent อยู่ในวงวน) ใช้ตัวเอง
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT id, 1 as level, ARRAY[id] as path
+			FROM categories
+			WHERE parent_id IS NULL AND (user_id = $1 OR user_id IS NULL)
+			UNION ALL
+			SELECT c.id, t.level + 1, t.path || c.id
+			FROM categories c
+			INNER JOIN tree t ON c.parent_id = t.id
+			WHERE (c.user_id = $1 OR c.user_id IS NULL)
+			AND NOT c.id = ANY(t.path)
+		), rolled AS (
+			SELECT COALESCE(tree.path[LEAST(tree.level, $5)], d.category_id) as category_id, d.total, d.transaction_count
+			FROM daily_category_totals d
+			LEFT JOIN tree ON tree.id = d.category_id
+			WHERE d.user_id = $1
+			AND d.type = $2
+			AND d.day >= $3
+			AND d.day <= $4
+		)
+		SELECT r.category_id, COALESCE(co.name, c.name), COALESCE(co.icon_name, c.icon_name), COALESCE(co.color_hex, c.color_hex),
+			   SUM(r.total) as total, SUM(r.transaction_count) as count
+		FROM rolled r
+		INNER JOIN categories c ON c.id = r.category_id
+		LEFT JOIN category_overrides co ON co.category_id = c.id AND co.user_id = $1
+		GROUP BY 1, 2, 3, 4
+		ORDER BY total DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, transactionType, startDate, endDate, depth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []*entity.CategoryTotal
+	for rows.Next() {
+		total := &entity.CategoryTotal{}
+		if err := rows.Scan(&total.CategoryID, &total.CategoryName, &total.IconName, &total.ColorHex, &total.Total, &total.Count); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+
+	return totals, rows.Err()
+}
+
+func (r *analyticsRepository) GetMonthlyTotals(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*entity.MonthlyTotal, error) {
+	query := `
+		SELECT DATE_TRUNC('month', d.day)::date as month,
+			   COALESCE(SUM(CASE WHEN d.type = 'income' THEN d.total ELSE 0 END), 0) as income,
+			   COALESCE(SUM(CASE WHEN d.type = 'expense' THEN d.total ELSE 0 END), 0) as expense
+		FROM daily_category_totals d
+		WHERE d.user_id = $1
+		AND d.day >= $2
+		AND d.day < $3
+		GROUP BY 1
+		ORDER BY 1 ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []*entity.MonthlyTotal
+	for rows.Next() {
+		total := &entity.MonthlyTotal{}
+		if err := rows.Scan(&total.Month, &total.Income, &total.Expense); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+
+	return totals, rows.Err()
+}
+
+// granularity ถูกตรวจกับ whitelist นี้ก่อนใส่ลงใน query
+var timeSeriesTruncUnits = map[entity.TimeSeriesGranularity]string{
+	entity.GranularityDay:     "day",
+	entity.GranularityWeek:    "week",
+	entity.GranularityMonth:   "month",
+	entity.GranularityQuarter: "quarter",
+	entity.GranularityYear:    "year",
+}
+
+func (r *analyticsRepository) GetTimeSeries(ctx context.Context, query repository.TimeSeriesQuery) ([]*entity.TimeSeriesBucket, error) {
+	unit, ok := timeSeriesTruncUnits[query.Granularity]
+	if !ok {
+		return nil, fmt.Errorf("unsupported granularity: %s", query.Granularity)
+	}
+
+	var groupKey, groupLabel, join string
+	switch query.GroupBy {
+	case entity.GroupByNone, "":
+		groupKey, groupLabel = "''", "''"
+	case entity.GroupByCategory:
+		groupKey, groupLabel = "t.category_id::text", "COALESCE(co.name, c.name)"
+		join = `INNER JOIN categories c ON c.id = t.category_id
+		LEFT JOIN category_overrides co ON co.category_id = c.id AND co.user_id = t.user_id`
+	case entity.GroupByAccount:
+		groupKey, groupLabel = "t.account_id::text", "a.name"
+		join = "INNER JOIN accounts a ON a.id = t.account_id"
+	case entity.GroupByType:
+		groupKey, groupLabel = "t.type::text", "t.type::text"
+	default:
+		return nil, fmt.Errorf("unsupported group by: %s", query.GroupBy)
+	}
+
+	// ทั้งสองตารางใช้ alias t และมีคอลัมน์ category_id, account_id, type เหมือนกัน ต่างกันแค่คอลัมน์วันที่และยอดรวม
+	table, dateColumn, totalExpr, countExpr := "daily_category_totals", "t.day", "SUM(t.total)", "SUM(t.transaction_count)"
+	conditions, args := buildDailyTotalConditions(query.Filter)
+	if !canUseDailyTotals(query.Filter) {
+		table, dateColumn, totalExpr, countExpr = "transactions", "t.transaction_date", "SUM(t.amount)", "COUNT(*)"
+		conditions, args = buildTransactionConditions(query.Filter)
+	}
+
+	sqlQuery := fmt.Sprintf(`
+		SELECT DATE_TRUNC('%s', %s)::date as period_start,
+			   %s as group_key,
+			   %s as group_label,
+			   %s as total,
+			   %s as count
+		FROM %s t
+		%s
+		WHERE %s
+		GROUP BY 1, 2, 3
+		ORDER BY 1 ASC, 2 ASC
+	`, unit, dateColumn, groupKey, groupLabel, totalExpr, countExpr, table, join, strings.Join(conditions, " AND "))
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []*entity.TimeSeriesBucket
+	for rows.Next() {
+		bucket := &entity.TimeSeriesBucket{}
+		if err := rows.Scan(&bucket.PeriodStart, &bucket.GroupKey, &bucket.GroupLabel, &bucket.Total, &bucket.Count); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
+
+func (r *analyticsRepository) GetDailyExpenseTotals(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*entity.CalendarDay, error) {
+	// รวมต่อวันต่อหมวดหมู่ก่อน แล้วใช้ window function หายอดรวมของวันและหมวดหมู่อันดับแรกใน query เดียว
+	query := `
+		WITH per_category AS (
+			SELECT d.day, d.category_id, COALESCE(co.name, c.name) as name,
+				   COALESCE(co.icon_name, c.icon_name) as icon_name, COALESCE(co.color_hex, c.color_hex) as color_hex,
+				   SUM(d.total) as total, SUM(d.transaction_count) as count
+			FROM daily_category_totals d
+			INNER JOIN categories c ON c.id = d.category_id
+			LEFT JOIN category_overrides co ON co.category_id = c.id AND co.user_id = d.user_id
+			WHERE d.user_id = $1
+			AND d.type = 'expense'
+			AND d.day >= $2
+			AND d.day <= $3
+			GROUP BY 1, 2, 3, 4, 5
+		), ranked AS (
+			SELECT per_category.*,
+				   SUM(total) OVER (PARTITION BY day) as day_total,
+				   SUM(count) OVER (PARTITION BY day) as day_count,
+				   ROW_NUMBER() OVER (PARTITION BY day ORDER BY total DESC, name ASC) as rank
+			FROM per_category
+		)
+		SELECT day, day_total, day_count, category_id, name, icon_name, color_hex, total, count
+		FROM ranked
+		WHERE rank = 1
+		ORDER BY day ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []*entity.CalendarDay
+	for rows.Next() {
+		day := &entity.CalendarDay{TopCategory: &entity.CategoryTotal{}}
+		err := rows.Scan(
+			&day.Date,
+			&day.Total,
+			&day.Count,
+			&day.TopCategory.CategoryID,
+			&day.TopCategory.CategoryName,
+			&day.TopCategory.IconName,
+			&day.TopCategory.ColorHex,
+			&day.TopCategory.Total,
+			&day.TopCategory.Count,
+		)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+
+	return days, rows.Err()
+}
+
+func (r *analyticsRepository) GetExpenseHeatmap(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*entity.HeatmapCell, error) {
+	// ใช้ transaction_time เหมือน GetSpendingPatterns ของ insight แต่แบ่งละเอียดเป็นรายชั่วโมงและใช้ช่วงวันที่ที่ระบุ
+	query := `
+		SELECT EXTRACT(ISODOW FROM t.transaction_date)::int as weekday,
+			   COALESCE(EXTRACT(HOUR FROM t.transaction_time)::int, -1) as hour,
+			   SUM(t.amount) as total,
+			   COUNT(*) as count
+		FROM transactions t
+		WHERE t.user_id = $1
+		AND t.type = 'expense'
+		AND t.transaction_date >= $2
+		AND t.transaction_date <= $3
+		GROUP BY 1, 2
+		ORDER BY 1 ASC, 2 ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cells []*entity.HeatmapCell
+	for rows.Next() {
+		cell := &entity.HeatmapCell{}
+		if err := rows.Scan(&cell.Weekday, &cell.Hour, &cell.Total, &cell.Count); err != nil {
+			return nil, err
+		}
+		cells = append(cells, cell)
+	}
+
+	return cells, rows.Err()
+}
+
+func (r *analyticsRepository) GetAccountCategoryTotals(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*entity.AccountCategoryTotal, error) {
+	query := `
+		SELECT d.account_id, a.name, d.category_id, COALESCE(co.name, c.name), COALESCE(co.color_hex, c.color_hex), d.type,
+			   SUM(d.total) as total
+		FROM daily_category_totals d
+		INNER JOIN accounts a ON a.id = d.account_id
+		INNER JOIN categories c ON c.id = d.category_id
+		LEFT JOIN category_overrides co ON co.category_id = c.id AND co.user_id = d.user_id
+		WHERE d.user_id = $1
+		AND d.day >= $2
+		AND d.day <= $3
+		GROUP BY 1, 2, 3, 4, 5, 6
+		ORDER BY total DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []*entity.AccountCategoryTotal
+	for rows.Next() {
+		total := &entity.AccountCategoryTotal{}
+		err := rows.Scan(
+			&total.AccountID,
+			&total.AccountName,
+			&total.CategoryID,
+			&total.CategoryName,
+			&total.ColorHex,
+			&total.Type,
+			&total.Total,
+		)
+		if err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+
+	return totals, rows.Err()
+}
+
+func (r *analyticsRepository) RebuildDailyCategoryTotals(ctx context.Context, userID *uuid.UUID) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// ล็อกตารางสรุปไว้ระหว่างคำนวณใหม่ ไม่ให้รายการที่สร้างพร้อมกันถูกนับซ้ำหรือหายไป
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE daily_category_totals IN EXCLUSIVE MODE`); err != nil {
+		return 0, err
+	}
+
+	deleteQuery := `DELETE FROM daily_category_totals`
+	insertQuery := `
+		INSERT INTO daily_category_totals (user_id, account_id, category_id, type, day, total, transaction_count, updated_at)
+		SELECT user_id, account_id, category_id, type, transaction_date, SUM(amount), COUNT(*), NOW()
+		FROM transactions
+	`
+	var args []interface{}
+	if userID != nil {
+		deleteQuery += ` WHERE user_id = $1`
+		insertQuery += ` WHERE user_id = $1`
+		args = append(args, *userID)
+	}
+	insertQuery += ` GROUP BY user_id, account_id, category_id, type, transaction_date`
+
+	if _, err := tx.ExecContext(ctx, deleteQuery, args...); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, insertQuery, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rows, tx.Commit()
+}
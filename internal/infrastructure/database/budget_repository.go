@@ -207,24 +207,40 @@ func (r *budgetRepository) Delete(ctx context.Context, id uuid.UUID) error {
 }
 
 func (r *budgetRepository) GetBudgetProgress(ctx context.Context, userID uuid.UUID, year, month int) ([]*entity.BudgetProgress, error) {
+	// ยอดใช้จ่ายของงบรวมหมวดหมู่ย่อยทุกระดับ (budget_categories คือหมวดหมู่ของงบพร้อมหมวดหมู่ย่อย)
 	query := `
+		WITH RECURSIVE budget_categories AS (
+			SELECT b.id as budget_id, b.category_id, ARRAY[b.category_id] as path
+			FROM budgets b
+			WHERE b.user_id = $1 AND b.is_active = true
+			UNION ALL
+			SELECT bc.budget_id, c.id, bc.path || c.id
+			FROM categories c
+			INNER JOIN budget_categories bc ON c.parent_id = bc.category_id
+			WHERE NOT c.id = ANY(bc.path)
+		)
 		SELECT 
 			b.id as budget_id,
+			b.category_id,
 			b.amount as budget_amount,
-			c.name as category_name,
-			COALESCE(SUM(CASE WHEN t.type = 'expense' THEN t.amount ELSE 0 END), 0) as spent_amount
+			COALESCE(co.name, c.name) as category_name,
+			COALESCE(SUM(d.total), 0) as spent_amount
 		FROM budgets b
 		INNER JOIN categories c ON b.category_id = c.id
-		LEFT JOIN transactions t ON b.category_id = t.category_id 
-			AND t.user_id = b.user_id 
-			AND EXTRACT(YEAR FROM t.transaction_date) = $2
-			AND EXTRACT(MONTH FROM t.transaction_date) = $3
+		LEFT JOIN category_overrides co ON co.category_id = c.id AND co.user_id = b.user_id
+		INNER JOIN budget_categories bc ON bc.budget_id = b.id
+		LEFT JOIN daily_category_totals d ON d.category_id = bc.category_id
+			AND d.user_id = b.user_id
+			AND d.type = 'expense'
+			AND d.day >= $2
+			AND d.day < $3
 		WHERE b.user_id = $1 AND b.is_active = true
-		GROUP BY b.id, b.amount, c.name
-		ORDER BY c.name ASC
+		GROUP BY b.id, b.category_id, b.amount, c.name, co.name
+		ORDER BY category_name ASC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, userID, year, month)
+	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	rows, err := r.db.QueryContext(ctx, query, userID, monthStart, monthStart.AddDate(0, 1, 0))
 	if err != nil {
 		return nil, err
 	}
@@ -232,11 +248,11 @@ func (r *budgetRepository) GetBudgetProgress(ctx context.Context, userID uuid.UU
 
 	var results []*entity.BudgetProgress
 	for rows.Next() {
-		var budgetID uuid.UUID
+		var budgetID, categoryID uuid.UUID
 		var budgetAmount, spentAmount decimal.Decimal
 		var categoryName string
 
-		err := rows.Scan(&budgetID, &budgetAmount, &categoryName, &spentAmount)
+		err := rows.Scan(&budgetID, &categoryID, &budgetAmount, &categoryName, &spentAmount)
 		if err != nil {
 			return nil, err
 		}
@@ -249,12 +265,14 @@ func (r *budgetRepository) GetBudgetProgress(ctx context.Context, userID uuid.UU
 
 		progress := &entity.BudgetProgress{
 			BudgetID:           budgetID,
+			CategoryID:         categoryID,
 			CategoryName:       categoryName,
 			BudgetAmount:       budgetAmount,
 			SpentAmount:        spentAmount,
 			RemainingAmount:    remainingAmount,
 			ProgressPercentage: progressPercentage,
 			IsOverBudget:       spentAmount.GreaterThan(budgetAmount),
+			ProjectedAmount:    spentAmount,
 			Period:             fmt.Sprintf("%d-%02d", year, month),
 		}
 
@@ -266,19 +284,33 @@ func (r *budgetRepository) GetBudgetProgress(ctx context.Context, userID uuid.UU
 
 func (r *budgetRepository) GetBudgetProgressByCategory(ctx context.Context, userID, categoryID uuid.UUID, year, month int) (*entity.BudgetProgress, error) {
 	query := `
+		WITH RECURSIVE budget_categories AS (
+			SELECT b.id as budget_id, b.category_id, ARRAY[b.category_id] as path
+			FROM budgets b
+			WHERE b.user_id = $1 AND b.category_id = $2 AND b.is_active = true
+			UNION ALL
+			SELECT bc.budget_id, c.id, bc.path || c.id
+			FROM categories c
+			INNER JOIN budget_categories bc ON c.parent_id = bc.category_id
+			WHERE NOT c.id = ANY(bc.path)
+		)
 		SELECT 
 			b.id as budget_id,
+			b.category_id,
 			b.amount as budget_amount,
-			c.name as category_name,
-			COALESCE(SUM(CASE WHEN t.type = 'expense' THEN t.amount ELSE 0 END), 0) as spent_amount
+			COALESCE(co.name, c.name) as category_name,
+			COALESCE(SUM(d.total), 0) as spent_amount
 		FROM budgets b
 		INNER JOIN categories c ON b.category_id = c.id
-		LEFT JOIN transactions t ON b.category_id = t.category_id 
-			AND t.user_id = b.user_id 
-			AND EXTRACT(YEAR FROM t.transaction_date) = $3
-			AND EXTRACT(MONTH FROM t.transaction_date) = $4
+		LEFT JOIN category_overrides co ON co.category_id = c.id AND co.user_id = b.user_id
+		INNER JOIN budget_categories bc ON bc.budget_id = b.id
+		LEFT JOIN daily_category_totals d ON d.category_id = bc.category_id
+			AND d.user_id = b.user_id
+			AND d.type = 'expense'
+			AND d.day >= $3
+			AND d.day < $4
 		WHERE b.user_id = $1 AND b.category_id = $2 AND b.is_active = true
-		GROUP BY b.id, b.amount, c.name
+		GROUP BY b.id, b.category_id, b.amount, c.name, co.name
 		LIMIT 1
 	`
 
@@ -286,8 +318,9 @@ func (r *budgetRepository) GetBudgetProgressByCategory(ctx context.Context, user
 	var budgetAmount, spentAmount decimal.Decimal
 	var categoryName string
 
-	err := r.db.QueryRowContext(ctx, query, userID, categoryID, year, month).Scan(
-		&budgetID, &budgetAmount, &categoryName, &spentAmount,
+	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	err := r.db.QueryRowContext(ctx, query, userID, categoryID, monthStart, monthStart.AddDate(0, 1, 0)).Scan(
+		&budgetID, &categoryID, &budgetAmount, &categoryName, &spentAmount,
 	)
 	if err != nil {
 		return nil, err
@@ -301,12 +334,14 @@ func (r *budgetRepository) GetBudgetProgressByCategory(ctx context.Context, user
 
 	return &entity.BudgetProgress{
 		BudgetID:           budgetID,
+		CategoryID:         categoryID,
 		CategoryName:       categoryName,
 		BudgetAmount:       budgetAmount,
 		SpentAmount:        spentAmount,
 		RemainingAmount:    remainingAmount,
 		ProgressPercentage: progressPercentage,
 		IsOverBudget:       spentAmount.GreaterThan(budgetAmount),
+		ProjectedAmount:    spentAmount,
 		Period:             fmt.Sprintf("%d-%02d", year, month),
 	}, nil
 }
@@ -23,13 +23,14 @@ func NewCategoryRepository(db *sql.DB) repository.CategoryRepository {
 
 func (r *categoryRepository) Create(ctx context.Context, category *entity.Category) error {
 	query := `
-		INSERT INTO categories (id, user_id, name, type, icon_name, color_hex, is_archived, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO categories (id, user_id, parent_id, name, type, icon_name, color_hex, is_archived, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
 		category.ID,
 		category.UserID,
+		category.ParentID,
 		category.Name,
 		category.Type,
 		category.IconName,
@@ -44,14 +45,15 @@ func (r *categoryRepository) Create(ctx context.Context, category *entity.Catego
 
 func (r *categoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Category, error) {
 	query := `
-		SELECT id, user_id, name, type, icon_name, color_hex, is_archived, created_at, updated_at
-		FROM categories WHERE id = $1 AND is_archived = false
+		SELECT id, user_id, parent_id, name, type, icon_name, color_hex, is_archived, created_at, updated_at
+		FROM categories WHERE id = $1
 	`
 
 	category := &entity.Category{}
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&category.ID,
 		&category.UserID,
+		&category.ParentID,
 		&category.Name,
 		&category.Type,
 		&category.IconName,
@@ -70,7 +72,7 @@ func (r *categoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity
 
 func (r *categoryRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Category, error) {
 	query := `
-		SELECT id, user_id, name, type, icon_name, color_hex, is_archived, created_at, updated_at
+		SELECT id, user_id, parent_id, name, type, icon_name, color_hex, is_archived, created_at, updated_at
 		FROM categories 
 		WHERE user_id = $1 AND is_archived = false
 		ORDER BY name ASC
@@ -88,6 +90,7 @@ func (r *categoryRepository) GetByUserID(ctx context.Context, userID uuid.UUID)
 		err := rows.Scan(
 			&category.ID,
 			&category.UserID,
+			&category.ParentID,
 			&category.Name,
 			&category.Type,
 			&category.IconName,
@@ -107,7 +110,7 @@ func (r *categoryRepository) GetByUserID(ctx context.Context, userID uuid.UUID)
 
 func (r *categoryRepository) GetSystemCategories(ctx context.Context) ([]*entity.Category, error) {
 	query := `
-		SELECT id, user_id, name, type, icon_name, color_hex, is_archived, created_at, updated_at
+		SELECT id, user_id, parent_id, name, type, icon_name, color_hex, is_archived, created_at, updated_at
 		FROM categories 
 		WHERE user_id IS NULL AND is_archived = false
 		ORDER BY name ASC
@@ -125,6 +128,7 @@ func (r *categoryRepository) GetSystemCategories(ctx context.Context) ([]*entity
 		err := rows.Scan(
 			&category.ID,
 			&category.UserID,
+			&category.ParentID,
 			&category.Name,
 			&category.Type,
 			&category.IconName,
@@ -145,7 +149,7 @@ func (r *categoryRepository) GetSystemCategories(ctx context.Context) ([]*entity
 func (r *categoryRepository) Update(ctx context.Context, category *entity.Category) error {
 	query := `
 		UPDATE categories 
-		SET name = $2, type = $3, icon_name = $4, color_hex = $5, updated_at = $6
+		SET name = $2, type = $3, icon_name = $4, color_hex = $5, parent_id = $6, updated_at = $7
 		WHERE id = $1
 	`
 
@@ -157,6 +161,7 @@ func (r *categoryRepository) Update(ctx context.Context, category *entity.Catego
 		category.Type,
 		category.IconName,
 		category.ColorHex,
+		category.ParentID,
 		category.UpdatedAt,
 	)
 
@@ -208,7 +213,7 @@ func (r *categoryRepository) GetByFilter(ctx context.Context, filter repository.
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, user_id, name, type, icon_name, color_hex, is_archived, created_at, updated_at
+		SELECT id, user_id, parent_id, name, type, icon_name, color_hex, is_archived, created_at, updated_at
 		FROM categories 
 		%s
 		ORDER BY name ASC
@@ -226,6 +231,7 @@ func (r *categoryRepository) GetByFilter(ctx context.Context, filter repository.
 		err := rows.Scan(
 			&category.ID,
 			&category.UserID,
+			&category.ParentID,
 			&category.Name,
 			&category.Type,
 			&category.IconName,
@@ -253,7 +259,7 @@ func (r *categoryRepository) GetCategoryUsageStats(ctx context.Context, userID u
 	query := `
 		SELECT c.id, COUNT(t.id) as usage_count
 		FROM categories c
-		LEFT JOIN transactions t ON c.id = t.category_id
+		LEFT JOIN transactions t ON c.id = t.category_id AND t.user_id = $1
 		WHERE (c.user_id = $1 OR c.user_id IS NULL)
 		GROUP BY c.id
 		ORDER BY usage_count DESC
@@ -292,3 +298,372 @@ func (r *categoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
+
+// GetDescendantIDs คืน id ของหมวดหมู่ย่อยทุกระดับใต้ categoryID (ไม่รวมตัวเอง) รวมหมวดหมู่ที่ archive แล้ว
+func (r *categoryRepository) GetDescendantIDs(ctx context.Context, categoryID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		WITH RECURSIVE descendants AS (
+			SELECT id, ARRAY[id] as path
+			FROM categories
+			WHERE parent_id = $1
+			UNION ALL
+			SELECT c.id, d.path || c.id
+			FROM categories c
+			INNER JOIN descendants d ON c.parent_id = d.id
+			WHERE NOT c.id = ANY(d.path)
+		)
+		SELECT DISTINCT id FROM descendants
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r *categoryRepository) Merge(ctx context.Context, userID, sourceID, targetID uuid.UUID) (*entity.CategoryMergeResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := mergeCategory(ctx, tx, userID, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *categoryRepository) MergeAndDelete(ctx context.Context, userID, sourceID, targetID uuid.UUID) (*entity.CategoryMergeResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := mergeCategory(ctx, tx, userID, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	// หลังรวมแล้วยังอาจมีข้อมูลของผู้ใช้อื่นอ้างถึงหมวดหมู่นี้อยู่ ถ้ามีให้ยกเลิกทั้งการรวมและการลบ
+	var transactionCount, recurringCount, budgetCount int64
+	err = tx.QueryRowContext(ctx, `
+		SELECT (SELECT COUNT(*) FROM transactions WHERE category_id = $1),
+			   (SELECT COUNT(*) FROM recurring_transactions WHERE category_id = $1),
+			   (SELECT COUNT(*) FROM budgets WHERE category_id = $1)
+	`, sourceID).Scan(&transactionCount, &recurringCount, &budgetCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check category usage: %w", err)
+	}
+	if transactionCount > 0 || recurringCount > 0 || budgetCount > 0 {
+		return nil, fmt.Errorf("%w: %d transaction(s), %d recurring transaction(s), %d budget(s)",
+			repository.ErrCategoryInUse, transactionCount, recurringCount, budgetCount)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// mergeCategory ย้ายข้อมูลของผู้ใช้จาก sourceID ไป targetID แล้ว archive sourceID ภายใน tx ที่ผู้เรียกเป็นเจ้าของ
+func mergeCategory(ctx context.Context, tx *sql.Tx, userID, sourceID, targetID uuid.UUID) (*entity.CategoryMergeResult, error) {
+	var err error
+	result := &entity.CategoryMergeResult{SourceID: sourceID, TargetID: targetID}
+
+	result.TransactionsMoved, err = recategorizeTransactions(ctx, tx, repository.TransactionFilter{
+		UserID:     userID,
+		CategoryID: &sourceID,
+	}, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move transactions: %w", err)
+	}
+
+	execResult, err := tx.ExecContext(ctx, `
+		UPDATE recurring_transactions SET category_id = $3, updated_at = NOW()
+		WHERE user_id = $1 AND category_id = $2
+	`, userID, sourceID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move recurring transactions: %w", err)
+	}
+	result.RecurringMoved, _ = execResult.RowsAffected()
+
+	// budgets มี unique (user_id, category_id, is_active) ถ้าปลายทางมีงบสถานะเดียวกันอยู่แล้ว
+	// งบที่ active จะถูกรวมยอดเข้าด้วยกัน ส่วนงบที่ไม่ active ของต้นทางจะถูกลบ
+	// งบที่ active ต่างรอบกัน (เช่น รายเดือนกับรายปี) รวมยอดกันไม่ได้ จึงยกเลิกการรวมทั้งหมด
+	var sourcePeriod, targetPeriod entity.BudgetPeriod
+	err = tx.QueryRowContext(ctx, `
+		SELECT source.period, target.period
+		FROM budgets source
+		INNER JOIN budgets target ON target.user_id = source.user_id AND target.is_active = true AND target.category_id = $3
+		WHERE source.user_id = $1 AND source.category_id = $2 AND source.is_active = true
+		AND source.period <> target.period
+		LIMIT 1
+	`, userID, sourceID, targetID).Scan(&sourcePeriod, &targetPeriod)
+	switch {
+	case err == nil:
+		return nil, fmt.Errorf("%w: source budget is %s, target budget is %s", repository.ErrBudgetPeriodMismatch, sourcePeriod, targetPeriod)
+	case err != sql.ErrNoRows:
+		return nil, fmt.Errorf("failed to check budgets: %w", err)
+	}
+
+	execResult, err = tx.ExecContext(ctx, `
+		UPDATE budgets target
+		SET amount = target.amount + source.amount, updated_at = NOW()
+		FROM budgets source
+		WHERE source.user_id = $1 AND source.category_id = $2 AND source.is_active = true
+		AND target.user_id = $1 AND target.category_id = $3 AND target.is_active = true
+		AND target.period = source.period
+	`, userID, sourceID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge budgets: %w", err)
+	}
+	result.BudgetsMerged, _ = execResult.RowsAffected()
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM budgets source
+		WHERE source.user_id = $1 AND source.category_id = $2
+		AND EXISTS (
+			SELECT 1 FROM budgets target
+			WHERE target.user_id = $1 AND target.category_id = $3 AND target.is_active = source.is_active
+		)
+	`, userID, sourceID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove merged budgets: %w", err)
+	}
+
+	execResult, err = tx.ExecContext(ctx, `
+		UPDATE budgets SET category_id = $3, updated_at = NOW()
+		WHERE user_id = $1 AND category_id = $2
+	`, userID, sourceID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move budgets: %w", err)
+	}
+	result.BudgetsMoved, _ = execResult.RowsAffected()
+
+	execResult, err = tx.ExecContext(ctx, `
+		UPDATE categories SET parent_id = $3, updated_at = NOW()
+		WHERE user_id = $1 AND parent_id = $2
+	`, userID, sourceID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move sub-categories: %w", err)
+	}
+	result.SubcategoriesMoved, _ = execResult.RowsAffected()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE categories SET is_archived = true, updated_at = NOW()
+		WHERE id = $1
+	`, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive category: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *categoryRepository) GetUsage(ctx context.Context, userID uuid.UUID) ([]*entity.CategoryUsage, error) {
+	query := `
+		SELECT c.id, COALESCE(co.name, c.name), c.type, c.is_archived,
+			   COALESCE(t.count, 0), COALESCE(rt.count, 0), COALESCE(b.count, 0), t.last_used_at
+		FROM categories c
+		LEFT JOIN category_overrides co ON co.category_id = c.id AND co.user_id = $1
+		LEFT JOIN (
+			SELECT category_id, COUNT(*) as count, MAX(transaction_date) as last_used_at
+			FROM transactions
+			WHERE user_id = $1
+			GROUP BY category_id
+		) t ON t.category_id = c.id
+		LEFT JOIN (
+			SELECT category_id, COUNT(*) as count
+			FROM recurring_transactions
+			WHERE user_id = $1
+			GROUP BY category_id
+		) rt ON rt.category_id = c.id
+		LEFT JOIN (
+			SELECT category_id, COUNT(*) as count
+			FROM budgets
+			WHERE user_id = $1
+			GROUP BY category_id
+		) b ON b.category_id = c.id
+		WHERE (c.user_id = $1 OR c.user_id IS NULL)
+		ORDER BY COALESCE(t.count, 0) DESC, COALESCE(co.name, c.name) ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usages []*entity.CategoryUsage
+	for rows.Next() {
+		usage := &entity.CategoryUsage{}
+		err := rows.Scan(
+			&usage.CategoryID,
+			&usage.CategoryName,
+			&usage.Type,
+			&usage.IsArchived,
+			&usage.TransactionCount,
+			&usage.RecurringCount,
+			&usage.BudgetCount,
+			&usage.LastUsedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, usage)
+	}
+
+	return usages, rows.Err()
+}
+
+func (r *categoryRepository) GetUsageByID(ctx context.Context, categoryID uuid.UUID) (*entity.CategoryUsage, error) {
+	query := `
+		SELECT c.id, c.name, c.type, c.is_archived,
+			   (SELECT COUNT(*) FROM transactions WHERE category_id = c.id),
+			   (SELECT COUNT(*) FROM recurring_transactions WHERE category_id = c.id),
+			   (SELECT COUNT(*) FROM budgets WHERE category_id = c.id),
+			   (SELECT MAX(transaction_date) FROM transactions WHERE category_id = c.id)
+		FROM categories c
+		WHERE c.id = $1
+	`
+
+	usage := &entity.CategoryUsage{}
+	err := r.db.QueryRowContext(ctx, query, categoryID).Scan(
+		&usage.CategoryID,
+		&usage.CategoryName,
+		&usage.Type,
+		&usage.IsArchived,
+		&usage.TransactionCount,
+		&usage.RecurringCount,
+		&usage.BudgetCount,
+		&usage.LastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+func (r *categoryRepository) GetOverrides(ctx context.Context, userID uuid.UUID) ([]*entity.CategoryOverride, error) {
+	query := `
+		SELECT id, user_id, category_id, name, icon_name, color_hex, is_hidden, created_at, updated_at
+		FROM category_overrides
+		WHERE user_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []*entity.CategoryOverride
+	for rows.Next() {
+		override := &entity.CategoryOverride{}
+		err := rows.Scan(
+			&override.ID,
+			&override.UserID,
+			&override.CategoryID,
+			&override.Name,
+			&override.IconName,
+			&override.ColorHex,
+			&override.IsHidden,
+			&override.CreatedAt,
+			&override.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, override)
+	}
+
+	return overrides, rows.Err()
+}
+
+func (r *categoryRepository) GetOverride(ctx context.Context, userID, categoryID uuid.UUID) (*entity.CategoryOverride, error) {
+	query := `
+		SELECT id, user_id, category_id, name, icon_name, color_hex, is_hidden, created_at, updated_at
+		FROM category_overrides
+		WHERE user_id = $1 AND category_id = $2
+	`
+
+	override := &entity.CategoryOverride{}
+	err := r.db.QueryRowContext(ctx, query, userID, categoryID).Scan(
+		&override.ID,
+		&override.UserID,
+		&override.CategoryID,
+		&override.Name,
+		&override.IconName,
+		&override.ColorHex,
+		&override.IsHidden,
+		&override.CreatedAt,
+		&override.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return override, nil
+}
+
+func (r *categoryRepository) UpsertOverride(ctx context.Context, override *entity.CategoryOverride) error {
+	// ถ้ามี override อยู่แล้วจะคง id และ created_at เดิมไว้ แล้วอ่านกลับมาใส่ใน override
+	query := `
+		INSERT INTO category_overrides (id, user_id, category_id, name, icon_name, color_hex, is_hidden, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id, category_id) DO UPDATE
+		SET name = EXCLUDED.name,
+			icon_name = EXCLUDED.icon_name,
+			color_hex = EXCLUDED.color_hex,
+			is_hidden = EXCLUDED.is_hidden,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
+	`
+
+	override.UpdatedAt = time.Now()
+
+	return r.db.QueryRowContext(ctx, query,
+		override.ID,
+		override.UserID,
+		override.CategoryID,
+		override.Name,
+		override.IconName,
+		override.ColorHex,
+		override.IsHidden,
+		override.CreatedAt,
+		override.UpdatedAt,
+	).Scan(&override.ID, &override.CreatedAt)
+}
+
+func (r *categoryRepository) DeleteOverride(ctx context.Context, userID, categoryID uuid.UUID) error {
+	query := `DELETE FROM category_overrides WHERE user_id = $1 AND category_id = $2`
+	_, err := r.db.ExecContext(ctx, query, userID, categoryID)
+	return err
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// applyDailyCategoryTotal ปรับยอดใน daily_category_totals ตามรายการที่ถูกเพิ่ม (sign = 1) หรือลบออก (sign = -1)
+// ต้องเรียกใน transaction เดียวกับการแก้ไขตาราง transactions เพื่อให้ยอดรวมตรงกับรายการเสมอ
+func applyDailyCategoryTotal(ctx context.Context, tx *sql.Tx, transaction *entity.Transaction, sign int64) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO daily_category_totals (user_id, account_id, category_id, type, day, total, transaction_count, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		ON CONFLICT (user_id, account_id, category_id, type, day) DO UPDATE
+		SET total = daily_category_totals.total + EXCLUDED.total,
+			transaction_count = daily_category_totals.transaction_count + EXCLUDED.transaction_count,
+			updated_at = NOW()
+	`,
+		transaction.UserID,
+		transaction.AccountID,
+		transaction.CategoryID,
+		transaction.Type,
+		transaction.TransactionDate,
+		transaction.Amount.Mul(decimal.NewFromInt(sign)),
+		sign,
+	)
+	if err != nil || sign > 0 {
+		return err
+	}
+
+	// วันที่ไม่เหลือรายการแล้วไม่ต้องเก็บแถวไว้
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM daily_category_totals
+		WHERE user_id = $1 AND account_id = $2 AND category_id = $3 AND type = $4 AND day = $5
+		AND transaction_count <= 0
+	`,
+		transaction.UserID,
+		transaction.AccountID,
+		transaction.CategoryID,
+		transaction.Type,
+		transaction.TransactionDate,
+	)
+	return err
+}
+
+// recategorizeTransactions ย้ายรายการที่ตรงกับ filter ไปหมวดหมู่ categoryID และย้ายยอดใน daily_category_totals ตามไปด้วย
+// ทั้งหมดอยู่ใน tx ที่ส่งเข้ามา คืนค่าจำนวนรายการที่ถูกย้าย (ไม่นับรายการที่อยู่ในหมวดหมู่นั้นอยู่แล้ว)
+func recategorizeTransactions(ctx context.Context, tx *sql.Tx, filter repository.TransactionFilter, categoryID uuid.UUID) (int64, error) {
+	conditions, args := buildTransactionConditions(filter)
+	args = append(args, categoryID)
+	categoryArg := len(args)
+
+	// moved คืนหมวดหมู่เดิมจาก self-join เพราะ RETURNING ของ t เป็นค่าหลังแก้ไข
+	// แถวที่ถูกลด (หมวดหมู่เดิม) กับแถวที่ถูกเพิ่ม (หมวดหมู่ใหม่) ไม่ซ้ำกัน จึงทำใน statement เดียวได้
+	query := fmt.Sprintf(`
+		WITH moved AS (
+			UPDATE transactions t
+			SET category_id = $%[1]d::uuid, updated_at = NOW()
+			FROM transactions old
+			WHERE old.id = t.id
+			AND %[2]s
+			AND t.category_id <> $%[1]d::uuid
+			RETURNING t.user_id, t.account_id, old.category_id as old_category_id, t.type, t.transaction_date as day, t.amount
+		), grouped AS (
+			SELECT user_id, account_id, old_category_id, type, day, SUM(amount) as total, COUNT(*) as count
+			FROM moved
+			GROUP BY user_id, account_id, old_category_id, type, day
+		), removed AS (
+			UPDATE daily_category_totals d
+			SET total = d.total - g.total,
+				transaction_count = d.transaction_count - g.count,
+				updated_at = NOW()
+			FROM grouped g
+			WHERE d.user_id = g.user_id
+			AND d.account_id = g.account_id
+			AND d.category_id = g.old_category_id
+			AND d.type = g.type
+			AND d.day = g.day
+		), added AS (
+			INSERT INTO daily_category_totals (user_id, account_id, category_id, type, day, total, transaction_count, updated_at)
+			SELECT user_id, account_id, $%[1]d::uuid, type, day, SUM(total), SUM(count), NOW()
+			FROM grouped
+			GROUP BY user_id, account_id, type, day
+			ON CONFLICT (user_id, account_id, category_id, type, day) DO UPDATE
+			SET total = daily_category_totals.total + EXCLUDED.total,
+				transaction_count = daily_category_totals.transaction_count + EXCLUDED.transaction_count,
+				updated_at = NOW()
+		)
+		SELECT COUNT(*) FROM moved
+	`, categoryArg, strings.Join(conditions, " AND "))
+
+	var moved int64
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&moved); err != nil {
+		return 0, err
+	}
+	if moved == 0 {
+		return 0, nil
+	}
+
+	// วันที่ไม่เหลือรายการในหมวดหมู่เดิมแล้วไม่ต้องเก็บแถวไว้
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM daily_category_totals
+		WHERE user_id = $1 AND transaction_count <= 0
+	`, filter.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	return moved, nil
+}
+
+// canUseDailyTotals บอกว่า filter นี้ตอบได้จาก daily_category_totals หรือไม่
+// การค้นหาข้อความและช่วงจำนวนเงินต้องดูรายการทีละแถว จึงต้องอ่านจาก transactions
+func canUseDailyTotals(filter repository.TransactionFilter) bool {
+	return filter.SearchQuery == nil && filter.MinAmount == nil && filter.MaxAmount == nil
+}
+
+// buildDailyTotalConditions สร้างเงื่อนไข WHERE จาก TransactionFilter โดยอ้างถึงตาราง daily_category_totals ด้วย alias t
+func buildDailyTotalConditions(filter repository.TransactionFilter) ([]string, []interface{}) {
+	conditions := []string{"t.user_id = $1"}
+	args := []interface{}{filter.UserID}
+
+	if filter.AccountID != nil {
+		args = append(args, *filter.AccountID)
+		conditions = append(conditions, fmt.Sprintf("t.account_id = $%d", len(args)))
+	}
+
+	if filter.CategoryID != nil {
+		args = append(args, *filter.CategoryID)
+		conditions = append(conditions, fmt.Sprintf("t.category_id = $%d", len(args)))
+	}
+
+	if filter.Type != nil {
+		args = append(args, *filter.Type)
+		conditions = append(conditions, fmt.Sprintf("t.type = $%d", len(args)))
+	}
+
+	if filter.StartDate != nil {
+		args = append(args, *filter.StartDate)
+		conditions = append(conditions, fmt.Sprintf("t.day >= $%d", len(args)))
+	}
+
+	if filter.EndDate != nil {
+		args = append(args, *filter.EndDate)
+		conditions = append(conditions, fmt.Sprintf("t.day <= $%d", len(args)))
+	}
+
+	return conditions, args
+}
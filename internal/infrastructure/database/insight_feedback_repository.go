@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// uniqueViolationCode คือ SQLSTATE ของ unique_violation
+const uniqueViolationCode = "23505"
+
+type insightFeedbackRepository struct {
+	db *sql.DB
+}
+
+func NewInsightFeedbackRepository(db *sql.DB) repository.InsightFeedbackRepository {
+	return &insightFeedbackRepository{db: db}
+}
+
+func (r *insightFeedbackRepository) SaveFeedback(ctx context.Context, feedback *entity.InsightFeedback) error {
+	query := `
+		INSERT INTO insight_feedback (id, user_id, insight_id, insight_type, rating, comment, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, insight_id) DO UPDATE SET
+			rating = EXCLUDED.rating,
+			comment = EXCLUDED.comment,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		feedback.ID,
+		feedback.UserID,
+		feedback.InsightID,
+		feedback.InsightType,
+		feedback.Rating,
+		feedback.Comment,
+		feedback.CreatedAt,
+		feedback.UpdatedAt,
+	).Scan(&feedback.ID, &feedback.CreatedAt)
+}
+
+func (r *insightFeedbackRepository) GetFeedbackSummary(ctx context.Context, userID uuid.UUID, since time.Time) (map[entity.InsightType]*entity.InsightTypeFeedback, error) {
+	query := `
+		SELECT insight_type,
+			   COUNT(*) FILTER (WHERE rating = 'helpful'),
+			   COUNT(*) FILTER (WHERE rating = 'not_helpful')
+		FROM insight_feedback
+		WHERE user_id = $1 AND updated_at >= $2
+		GROUP BY insight_type
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summary := make(map[entity.InsightType]*entity.InsightTypeFeedback)
+	for rows.Next() {
+		var insightType entity.InsightType
+		feedback := &entity.InsightTypeFeedback{}
+		if err := rows.Scan(&insightType, &feedback.Helpful, &feedback.NotHelpful); err != nil {
+			return nil, err
+		}
+		summary[insightType] = feedback
+	}
+
+	return summary, rows.Err()
+}
+
+func (r *insightFeedbackRepository) CreateMute(ctx context.Context, mute *entity.InsightMute) error {
+	query := `
+		INSERT INTO insight_mutes (id, user_id, scope, category_id, insight_type, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		mute.ID,
+		mute.UserID,
+		mute.Scope,
+		mute.CategoryID,
+		mute.InsightType,
+		mute.CreatedAt,
+	)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode {
+		return repository.ErrMuteExists
+	}
+
+	return err
+}
+
+func (r *insightFeedbackRepository) GetMuteByID(ctx context.Context, id uuid.UUID) (*entity.InsightMute, error) {
+	query := `
+		SELECT id, user_id, scope, category_id, insight_type, created_at
+		FROM insight_mutes WHERE id = $1
+	`
+
+	mute := &entity.InsightMute{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&mute.ID,
+		&mute.UserID,
+		&mute.Scope,
+		&mute.CategoryID,
+		&mute.InsightType,
+		&mute.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return mute, nil
+}
+
+func (r *insightFeedbackRepository) GetMutes(ctx context.Context, userID uuid.UUID) ([]*entity.InsightMute, error) {
+	query := `
+		SELECT id, user_id, scope, category_id, insight_type, created_at
+		FROM insight_mutes WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mutes []*entity.InsightMute
+	for rows.Next() {
+		mute := &entity.InsightMute{}
+		err := rows.Scan(
+			&mute.ID,
+			&mute.UserID,
+			&mute.Scope,
+			&mute.CategoryID,
+			&mute.InsightType,
+			&mute.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		mutes = append(mutes, mute)
+	}
+
+	return mutes, rows.Err()
+}
+
+func (r *insightFeedbackRepository) DeleteMute(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM insight_mutes WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
@@ -11,6 +11,7 @@ import (
 	"savvy-backend/internal/domain/repository"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type insightRepository struct {
@@ -26,9 +27,9 @@ func (r *insightRepository) Create(ctx context.Context, insight *entity.Insight)
 		INSERT INTO insights (
 			id, user_id, type, priority, title, content, action_text, is_read,
 			related_entity_id, related_entity_type, related_data, valid_until,
-			created_at, updated_at
+			fingerprint, message_key, message_params, locale, created_at, updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -44,6 +45,10 @@ func (r *insightRepository) Create(ctx context.Context, insight *entity.Insight)
 		insight.RelatedEntityType,
 		insight.RelatedData,
 		insight.ValidUntil,
+		insight.Fingerprint,
+		insight.MessageKey,
+		insight.MessageParams,
+		insight.Locale,
 		insight.CreatedAt,
 		insight.UpdatedAt,
 	)
@@ -51,11 +56,90 @@ func (r *insightRepository) Create(ctx context.Context, insight *entity.Insight)
 	return err
 }
 
+func (r *insightRepository) Upsert(ctx context.Context, insight *entity.Insight) error {
+	if insight.Fingerprint == nil {
+		return r.Create(ctx, insight)
+	}
+
+	// insight ที่เคย resolve แล้วกลับมาเกิดใหม่ถือเป็นเรื่องใหม่ จึงรีเซ็ตสถานะอ่าน/dismiss
+	query := `
+		INSERT INTO insights (
+			id, user_id, type, priority, title, content, action_text, is_read,
+			related_entity_id, related_entity_type, related_data, valid_until,
+			fingerprint, message_key, message_params, locale, created_at, updated_at, generated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $18)
+		ON CONFLICT (user_id, fingerprint) DO UPDATE SET
+			priority = EXCLUDED.priority,
+			title = EXCLUDED.title,
+			content = EXCLUDED.content,
+			action_text = EXCLUDED.action_text,
+			related_data = EXCLUDED.related_data,
+			valid_until = EXCLUDED.valid_until,
+			message_key = EXCLUDED.message_key,
+			message_params = EXCLUDED.message_params,
+			locale = EXCLUDED.locale,
+			is_read = CASE WHEN insights.resolved_at IS NOT NULL THEN false ELSE insights.is_read END,
+			dismissed_at = CASE WHEN insights.resolved_at IS NOT NULL THEN NULL ELSE insights.dismissed_at END,
+			resolved_at = NULL,
+			updated_at = EXCLUDED.updated_at,
+			generated_at = EXCLUDED.generated_at
+		RETURNING id, is_read, dismissed_at, snoozed_until, created_at
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		insight.ID,
+		insight.UserID,
+		insight.Type,
+		insight.Priority,
+		insight.Title,
+		insight.Content,
+		insight.ActionText,
+		insight.IsRead,
+		insight.RelatedEntityID,
+		insight.RelatedEntityType,
+		insight.RelatedData,
+		insight.ValidUntil,
+		insight.Fingerprint,
+		insight.MessageKey,
+		insight.MessageParams,
+		insight.Locale,
+		insight.CreatedAt,
+		insight.UpdatedAt,
+	).Scan(&insight.ID, &insight.IsRead, &insight.DismissedAt, &insight.SnoozedUntil, &insight.CreatedAt)
+}
+
+func (r *insightRepository) ResolveStale(ctx context.Context, userID uuid.UUID, insightTypes []entity.InsightType, activeFingerprints []string, resolvedAt time.Time) (int64, error) {
+	types := make([]string, len(insightTypes))
+	for i, insightType := range insightTypes {
+		types[i] = string(insightType)
+	}
+	if activeFingerprints == nil {
+		activeFingerprints = []string{}
+	}
+
+	query := `
+		UPDATE insights
+		SET resolved_at = $4, updated_at = $4
+		WHERE user_id = $1
+		  AND type = ANY($2)
+		  AND fingerprint IS NOT NULL
+		  AND resolved_at IS NULL
+		  AND NOT (fingerprint = ANY($3))
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, pq.Array(types), pq.Array(activeFingerprints), resolvedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 func (r *insightRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Insight, error) {
 	query := `
 		SELECT id, user_id, type, priority, title, content, action_text, is_read,
 			   related_entity_id, related_entity_type, related_data, valid_until,
-			   created_at, updated_at
+			   dismissed_at, snoozed_until, fingerprint, resolved_at, message_key, message_params, locale, created_at, updated_at
 		FROM insights WHERE id = $1
 	`
 
@@ -73,6 +157,13 @@ func (r *insightRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.
 		&insight.RelatedEntityType,
 		&insight.RelatedData,
 		&insight.ValidUntil,
+		&insight.DismissedAt,
+		&insight.SnoozedUntil,
+		&insight.Fingerprint,
+		&insight.ResolvedAt,
+		&insight.MessageKey,
+		&insight.MessageParams,
+		&insight.Locale,
 		&insight.CreatedAt,
 		&insight.UpdatedAt,
 	)
@@ -85,44 +176,16 @@ func (r *insightRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.
 }
 
 func (r *insightRepository) GetByFilter(ctx context.Context, filter repository.InsightFilter) ([]*entity.Insight, error) {
-	var conditions []string
-	var args []interface{}
-	argCount := 0
-
-	// Build WHERE clause
-	conditions = append(conditions, "user_id = $1")
-	args = append(args, filter.UserID)
-	argCount = 1
-
-	if filter.Type != nil {
-		argCount++
-		conditions = append(conditions, fmt.Sprintf("type = $%d", argCount))
-		args = append(args, *filter.Type)
-	}
-
-	if filter.Priority != nil {
-		argCount++
-		conditions = append(conditions, fmt.Sprintf("priority = $%d", argCount))
-		args = append(args, *filter.Priority)
-	}
-
-	if filter.IsRead != nil {
-		argCount++
-		conditions = append(conditions, fmt.Sprintf("is_read = $%d", argCount))
-		args = append(args, *filter.IsRead)
-	}
-
-	if filter.ValidOnly {
-		conditions = append(conditions, "(valid_until IS NULL OR valid_until > NOW())")
-	}
+	conditions, args := buildInsightConditions(filter)
+	argCount := len(args)
 
 	query := `
 		SELECT id, user_id, type, priority, title, content, action_text, is_read,
 			   related_entity_id, related_entity_type, related_data, valid_until,
-			   created_at, updated_at
+			   dismissed_at, snoozed_until, fingerprint, resolved_at, message_key, message_params, locale, created_at, updated_at
 		FROM insights 
 		WHERE ` + strings.Join(conditions, " AND ") + `
-		ORDER BY priority DESC, created_at DESC
+		ORDER BY CASE priority WHEN 'high' THEN 3 WHEN 'medium' THEN 2 ELSE 1 END DESC, created_at DESC
 	`
 
 	if filter.Limit > 0 {
@@ -159,6 +222,13 @@ func (r *insightRepository) GetByFilter(ctx context.Context, filter repository.I
 			&insight.RelatedEntityType,
 			&insight.RelatedData,
 			&insight.ValidUntil,
+			&insight.DismissedAt,
+			&insight.SnoozedUntil,
+			&insight.Fingerprint,
+			&insight.ResolvedAt,
+			&insight.MessageKey,
+			&insight.MessageParams,
+			&insight.Locale,
 			&insight.CreatedAt,
 			&insight.UpdatedAt,
 		)
@@ -171,6 +241,95 @@ func (r *insightRepository) GetByFilter(ctx context.Context, filter repository.I
 	return insights, nil
 }
 
+func (r *insightRepository) CountByFilter(ctx context.Context, filter repository.InsightFilter) (int, error) {
+	conditions, args := buildInsightConditions(filter)
+	query := `SELECT COUNT(*) FROM insights WHERE ` + strings.Join(conditions, " AND ")
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+func (r *insightRepository) GetUnreadCountByType(ctx context.Context, userID uuid.UUID) (map[entity.InsightType]int, error) {
+	isRead := false
+	conditions, args := buildInsightConditions(repository.InsightFilter{
+		UserID:    userID,
+		IsRead:    &isRead,
+		ValidOnly: true,
+	})
+	query := `
+		SELECT type, COUNT(*) FROM insights
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		GROUP BY type
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[entity.InsightType]int)
+	for rows.Next() {
+		var insightType entity.InsightType
+		var count int
+		if err := rows.Scan(&insightType, &count); err != nil {
+			return nil, err
+		}
+		counts[insightType] = count
+	}
+
+	return counts, nil
+}
+
+// buildInsightConditions สร้าง WHERE ของ InsightFilter ใช้ร่วมกันระหว่าง list และ count
+func buildInsightConditions(filter repository.InsightFilter) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	argCount := 0
+
+	// Build WHERE clause
+	conditions = append(conditions, "user_id = $1")
+	args = append(args, filter.UserID)
+	argCount = 1
+
+	if filter.Type != nil {
+		argCount++
+		conditions = append(conditions, fmt.Sprintf("type = $%d", argCount))
+		args = append(args, *filter.Type)
+	}
+
+	if filter.Priority != nil {
+		argCount++
+		conditions = append(conditions, fmt.Sprintf("priority = $%d", argCount))
+		args = append(args, *filter.Priority)
+	}
+
+	if filter.IsRead != nil {
+		argCount++
+		conditions = append(conditions, fmt.Sprintf("is_read = $%d", argCount))
+		args = append(args, *filter.IsRead)
+	}
+
+	if filter.ValidOnly {
+		conditions = append(conditions, "(valid_until IS NULL OR valid_until > NOW())")
+	}
+
+	if !filter.IncludeDismissed {
+		conditions = append(conditions, "dismissed_at IS NULL")
+	}
+
+	if !filter.IncludeResolved {
+		conditions = append(conditions, "resolved_at IS NULL")
+	}
+
+	if !filter.IncludeSnoozed {
+		conditions = append(conditions, "(snoozed_until IS NULL OR snoozed_until <= NOW())")
+	}
+
+	return conditions, args
+}
+
 func (r *insightRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*entity.Insight, error) {
 	filter := repository.InsightFilter{
 		UserID:    userID,
@@ -197,22 +356,57 @@ func (r *insightRepository) MarkAsRead(ctx context.Context, id uuid.UUID) error
 	return err
 }
 
+func (r *insightRepository) MarkAsUnread(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE insights SET is_read = false, updated_at = $2 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, time.Now())
+	return err
+}
+
 func (r *insightRepository) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
 	query := `UPDATE insights SET is_read = true, updated_at = $2 WHERE user_id = $1 AND is_read = false`
 	_, err := r.db.ExecContext(ctx, query, userID, time.Now())
 	return err
 }
 
+func (r *insightRepository) Dismiss(ctx context.Context, id uuid.UUID, dismissedAt time.Time) error {
+	query := `UPDATE insights SET dismissed_at = $2, updated_at = $3 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, dismissedAt, time.Now())
+	return err
+}
+
+func (r *insightRepository) Snooze(ctx context.Context, id uuid.UUID, until time.Time) error {
+	query := `UPDATE insights SET snoozed_until = $2, updated_at = $3 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, until, time.Now())
+	return err
+}
+
 func (r *insightRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM insights WHERE id = $1`
 	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *insightRepository) DeleteExpired(ctx context.Context, before time.Time) error {
+func (r *insightRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
 	query := `DELETE FROM insights WHERE valid_until IS NOT NULL AND valid_until < $1`
-	_, err := r.db.ExecContext(ctx, query, before)
-	return err
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *insightRepository) GetLastGeneratedAt(ctx context.Context, userID uuid.UUID, insightType entity.InsightType) (*time.Time, error) {
+	query := `SELECT MAX(generated_at) FROM insights WHERE user_id = $1 AND type = $2`
+
+	var lastGeneratedAt sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query, userID, insightType).Scan(&lastGeneratedAt); err != nil {
+		return nil, err
+	}
+	if !lastGeneratedAt.Valid {
+		return nil, nil
+	}
+
+	return &lastGeneratedAt.Time, nil
 }
 
 func (r *insightRepository) GetSpendingAnomalies(ctx context.Context, userID uuid.UUID, months int) ([]*entity.SpendingAnomaly, error) {
@@ -220,15 +414,16 @@ func (r *insightRepository) GetSpendingAnomalies(ctx context.Context, userID uui
 		WITH monthly_spending AS (
 			SELECT 
 				t.category_id,
-				c.name as category_name,
+				COALESCE(co.name, c.name) as category_name,
 				DATE_TRUNC('month', t.transaction_date) as month,
 				SUM(t.amount) as total_amount
 			FROM transactions t
 			INNER JOIN categories c ON t.category_id = c.id
+			LEFT JOIN category_overrides co ON co.category_id = c.id AND co.user_id = t.user_id
 			WHERE t.user_id = $1 
 			  AND t.type = 'expense'
 			  AND t.transaction_date >= NOW() - INTERVAL '%d months'
-			GROUP BY t.category_id, c.name, DATE_TRUNC('month', t.transaction_date)
+			GROUP BY t.category_id, COALESCE(co.name, c.name), DATE_TRUNC('month', t.transaction_date)
 		),
 		averages AS (
 			SELECT 
@@ -243,14 +438,15 @@ func (r *insightRepository) GetSpendingAnomalies(ctx context.Context, userID uui
 		current_month AS (
 			SELECT 
 				t.category_id,
-				c.name as category_name,
+				COALESCE(co.name, c.name) as category_name,
 				SUM(t.amount) as current_amount
 			FROM transactions t
 			INNER JOIN categories c ON t.category_id = c.id
+			LEFT JOIN category_overrides co ON co.category_id = c.id AND co.user_id = t.user_id
 			WHERE t.user_id = $1 
 			  AND t.type = 'expense'
 			  AND DATE_TRUNC('month', t.transaction_date) = DATE_TRUNC('month', NOW())
-			GROUP BY t.category_id, c.name
+			GROUP BY t.category_id, COALESCE(co.name, c.name)
 		)
 		SELECT 
 			cm.category_id,
@@ -306,26 +502,29 @@ func (r *insightRepository) GetSpendingPatterns(ctx context.Context, userID uuid
 	query := `
 		SELECT 
 			t.category_id,
-			c.name as category_name,
+			COALESCE(co.name, c.name) as category_name,
 			TO_CHAR(t.transaction_date, 'Day') as day_of_week,
 			CASE 
-				WHEN EXTRACT(HOUR FROM t.created_at) BETWEEN 6 AND 11 THEN 'Morning'
-				WHEN EXTRACT(HOUR FROM t.created_at) BETWEEN 12 AND 17 THEN 'Afternoon'
-				WHEN EXTRACT(HOUR FROM t.created_at) BETWEEN 18 AND 22 THEN 'Evening'
+				WHEN t.transaction_time IS NULL THEN NULL
+				WHEN EXTRACT(HOUR FROM t.transaction_time) BETWEEN 6 AND 11 THEN 'Morning'
+				WHEN EXTRACT(HOUR FROM t.transaction_time) BETWEEN 12 AND 17 THEN 'Afternoon'
+				WHEN EXTRACT(HOUR FROM t.transaction_time) BETWEEN 18 AND 22 THEN 'Evening'
 				ELSE 'Night'
 			END as time_of_day,
 			COUNT(*) as frequency_count,
 			AVG(t.amount) as avg_amount
 		FROM transactions t
 		INNER JOIN categories c ON t.category_id = c.id
+		LEFT JOIN category_overrides co ON co.category_id = c.id AND co.user_id = t.user_id
 		WHERE t.user_id = $1 
 		  AND t.type = 'expense'
 		  AND t.transaction_date >= NOW() - INTERVAL '%d days'
-		GROUP BY t.category_id, c.name, TO_CHAR(t.transaction_date, 'Day'), 
+		GROUP BY t.category_id, COALESCE(co.name, c.name), TO_CHAR(t.transaction_date, 'Day'), 
 				 CASE 
-					WHEN EXTRACT(HOUR FROM t.created_at) BETWEEN 6 AND 11 THEN 'Morning'
-					WHEN EXTRACT(HOUR FROM t.created_at) BETWEEN 12 AND 17 THEN 'Afternoon'
-					WHEN EXTRACT(HOUR FROM t.created_at) BETWEEN 18 AND 22 THEN 'Evening'
+					WHEN t.transaction_time IS NULL THEN NULL
+					WHEN EXTRACT(HOUR FROM t.transaction_time) BETWEEN 6 AND 11 THEN 'Morning'
+					WHEN EXTRACT(HOUR FROM t.transaction_time) BETWEEN 12 AND 17 THEN 'Afternoon'
+					WHEN EXTRACT(HOUR FROM t.transaction_time) BETWEEN 18 AND 22 THEN 'Evening'
 					ELSE 'Night'
 				 END
 		HAVING COUNT(*) >= 3 -- At least 3 transactions in this pattern
@@ -343,11 +542,12 @@ func (r *insightRepository) GetSpendingPatterns(ctx context.Context, userID uuid
 	var patterns []*entity.SpendingPattern
 	for rows.Next() {
 		pattern := &entity.SpendingPattern{}
+		var timeOfDay sql.NullString
 		err := rows.Scan(
 			&pattern.CategoryID,
 			&pattern.CategoryName,
 			&pattern.DayOfWeek,
-			&pattern.TimeOfDay,
+			&timeOfDay,
 			&pattern.FrequencyCount,
 			&pattern.AverageAmount,
 		)
@@ -356,6 +556,7 @@ func (r *insightRepository) GetSpendingPatterns(ctx context.Context, userID uuid
 		}
 
 		pattern.DayOfWeek = strings.TrimSpace(pattern.DayOfWeek)
+		pattern.TimeOfDay = timeOfDay.String
 		pattern.Period = fmt.Sprintf("Last %d days", days)
 		patterns = append(patterns, pattern)
 	}
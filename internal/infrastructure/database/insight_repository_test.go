@@ -0,0 +1,97 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/infrastructure/database"
+)
+
+// testDatabaseEnv ชี้ไปยังฐานข้อมูลสำหรับทดสอบโดยเฉพาะ (ต้อง migrate แล้ว) ถ้าไม่ได้ตั้งค่าจะข้ามการทดสอบ
+const testDatabaseEnv = "TEST_DATABASE_DSN"
+
+func openTestDatabase(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv(testDatabaseEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set; skipping database test", testDatabaseEnv)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := db.Ping(); err != nil {
+		t.Fatalf("failed to ping test database: %v", err)
+	}
+	return db
+}
+
+// createTestUser สร้างผู้ใช้ชั่วคราว ข้อมูลที่ผูกกับผู้ใช้ถูกลบตาม ON DELETE CASCADE เมื่อจบการทดสอบ
+func createTestUser(t *testing.T, db *sql.DB) uuid.UUID {
+	t.Helper()
+
+	userID := uuid.New()
+	if _, err := db.Exec(`
+		INSERT INTO users (id, email, password_hash, display_name)
+		VALUES ($1, $2, 'test', 'Repository Test')
+	`, userID, fmt.Sprintf("test-%s@example.invalid", userID)); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := db.Exec(`DELETE FROM users WHERE id = $1`, userID); err != nil {
+			t.Errorf("failed to delete test user %s: %v", userID, err)
+		}
+	})
+	return userID
+}
+
+func TestInsightUpsertBumpsGeneratedAt(t *testing.T) {
+	db := openTestDatabase(t)
+	ctx := context.Background()
+	userID := createTestUser(t, db)
+	repo := database.NewInsightRepository(db)
+
+	newInsight := func(generatedAt time.Time) *entity.Insight {
+		insight := entity.NewAdvancedInsight(userID, entity.InsightTypeSpendingPattern, entity.InsightPriorityLow, "title", "content")
+		insight.CreatedAt = generatedAt
+		insight.UpdatedAt = generatedAt
+		insight.SetFingerprint("weekly")
+		return insight
+	}
+
+	firstRun := time.Now().Add(-40 * 24 * time.Hour).Truncate(time.Second)
+	first := newInsight(firstRun)
+	if err := repo.Upsert(ctx, first); err != nil {
+		t.Fatalf("Upsert() first error = %v", err)
+	}
+
+	// รอบถัดมาสร้าง insight fingerprint เดิม แถวเดิมถูกอัปเดตแต่ created_at ไม่เปลี่ยน
+	secondRun := time.Now().Truncate(time.Second)
+	second := newInsight(secondRun)
+	if err := repo.Upsert(ctx, second); err != nil {
+		t.Fatalf("Upsert() second error = %v", err)
+	}
+	if second.ID != first.ID || !second.CreatedAt.Equal(firstRun) {
+		t.Fatalf("second upsert = id %s created %v, want existing row %s created %v", second.ID, second.CreatedAt, first.ID, firstRun)
+	}
+
+	lastGeneratedAt, err := repo.GetLastGeneratedAt(ctx, userID, entity.InsightTypeSpendingPattern)
+	if err != nil {
+		t.Fatalf("GetLastGeneratedAt() error = %v", err)
+	}
+	if lastGeneratedAt == nil || !lastGeneratedAt.Equal(secondRun) {
+		t.Fatalf("GetLastGeneratedAt() = %v, want %v", lastGeneratedAt, secondRun)
+	}
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+type netWorthRepository struct {
+	db *sql.DB
+}
+
+func NewNetWorthRepository(db *sql.DB) repository.NetWorthRepository {
+	return &netWorthRepository{db: db}
+}
+
+func (r *netWorthRepository) Upsert(ctx context.Context, snapshot *entity.NetWorthSnapshot) error {
+	breakdown, err := json.Marshal(snapshot.Breakdown)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO net_worth_snapshots (id, user_id, snapshot_date, total_assets, total_liabilities, net_worth, breakdown, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, snapshot_date) DO UPDATE
+		SET total_assets = EXCLUDED.total_assets,
+			total_liabilities = EXCLUDED.total_liabilities,
+			net_worth = EXCLUDED.net_worth,
+			breakdown = EXCLUDED.breakdown,
+			created_at = EXCLUDED.created_at
+		RETURNING id
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		snapshot.ID,
+		snapshot.UserID,
+		snapshot.SnapshotDate,
+		snapshot.TotalAssets,
+		snapshot.TotalLiabilities,
+		snapshot.NetWorth,
+		breakdown,
+		snapshot.CreatedAt,
+	).Scan(&snapshot.ID)
+}
+
+func (r *netWorthRepository) GetByRange(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*entity.NetWorthSnapshot, error) {
+	query := `
+		SELECT id, user_id, snapshot_date, total_assets, total_liabilities, net_worth, breakdown, created_at
+		FROM net_worth_snapshots
+		WHERE user_id = $1
+		AND snapshot_date >= $2
+		AND snapshot_date <= $3
+		ORDER BY snapshot_date ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []*entity.NetWorthSnapshot
+	for rows.Next() {
+		snapshot := &entity.NetWorthSnapshot{}
+		var breakdown []byte
+		err := rows.Scan(
+			&snapshot.ID,
+			&snapshot.UserID,
+			&snapshot.SnapshotDate,
+			&snapshot.TotalAssets,
+			&snapshot.TotalLiabilities,
+			&snapshot.NetWorth,
+			&breakdown,
+			&snapshot.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(breakdown, &snapshot.Breakdown); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, rows.Err()
+}
@@ -11,6 +11,7 @@ import (
 	"savvy-backend/internal/domain/repository"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type recurringTransactionRepository struct {
@@ -26,9 +27,10 @@ func (r *recurringTransactionRepository) Create(ctx context.Context, recurring *
 		INSERT INTO recurring_transactions (
 			id, user_id, category_id, account_id, amount, type, note, frequency,
 			start_date, end_date, next_execution_date, last_execution_date,
-			is_active, auto_execute, remaining_executions, created_at, updated_at
+			is_active, auto_execute, remaining_executions, amount_mode, estimate_window,
+			min_amount, max_amount, created_at, updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -47,6 +49,10 @@ func (r *recurringTransactionRepository) Create(ctx context.Context, recurring *
 		recurring.IsActive,
 		recurring.AutoExecute,
 		recurring.RemainingExecutions,
+		recurring.AmountMode,
+		recurring.EstimateWindow,
+		recurring.MinAmount,
+		recurring.MaxAmount,
 		recurring.CreatedAt,
 		recurring.UpdatedAt,
 	)
@@ -58,7 +64,8 @@ func (r *recurringTransactionRepository) GetByID(ctx context.Context, id uuid.UU
 	query := `
 		SELECT id, user_id, category_id, account_id, amount, type, note, frequency,
 			   start_date, end_date, next_execution_date, last_execution_date,
-			   is_active, auto_execute, remaining_executions, created_at, updated_at
+			   is_active, auto_execute, remaining_executions, amount_mode, estimate_window,
+			   min_amount, max_amount, created_at, updated_at
 		FROM recurring_transactions WHERE id = $1
 	`
 
@@ -79,6 +86,10 @@ func (r *recurringTransactionRepository) GetByID(ctx context.Context, id uuid.UU
 		&recurring.IsActive,
 		&recurring.AutoExecute,
 		&recurring.RemainingExecutions,
+		&recurring.AmountMode,
+		&recurring.EstimateWindow,
+		&recurring.MinAmount,
+		&recurring.MaxAmount,
 		&recurring.CreatedAt,
 		&recurring.UpdatedAt,
 	)
@@ -139,7 +150,8 @@ func (r *recurringTransactionRepository) GetByFilter(ctx context.Context, filter
 	query := `
 		SELECT id, user_id, category_id, account_id, amount, type, note, frequency,
 			   start_date, end_date, next_execution_date, last_execution_date,
-			   is_active, auto_execute, remaining_executions, created_at, updated_at
+			   is_active, auto_execute, remaining_executions, amount_mode, estimate_window,
+			   min_amount, max_amount, created_at, updated_at
 		FROM recurring_transactions 
 		WHERE ` + strings.Join(conditions, " AND ") + `
 		ORDER BY next_execution_date ASC, created_at DESC
@@ -170,6 +182,10 @@ func (r *recurringTransactionRepository) GetByFilter(ctx context.Context, filter
 			&recurring.IsActive,
 			&recurring.AutoExecute,
 			&recurring.RemainingExecutions,
+			&recurring.AmountMode,
+			&recurring.EstimateWindow,
+			&recurring.MinAmount,
+			&recurring.MaxAmount,
 			&recurring.CreatedAt,
 			&recurring.UpdatedAt,
 		)
@@ -186,7 +202,8 @@ func (r *recurringTransactionRepository) GetDueTransactions(ctx context.Context,
 	query := `
 		SELECT id, user_id, category_id, account_id, amount, type, note, frequency,
 			   start_date, end_date, next_execution_date, last_execution_date,
-			   is_active, auto_execute, remaining_executions, created_at, updated_at
+			   is_active, auto_execute, remaining_executions, amount_mode, estimate_window,
+			   min_amount, max_amount, created_at, updated_at
 		FROM recurring_transactions 
 		WHERE is_active = true 
 		  AND next_execution_date <= $1
@@ -220,6 +237,10 @@ func (r *recurringTransactionRepository) GetDueTransactions(ctx context.Context,
 			&recurring.IsActive,
 			&recurring.AutoExecute,
 			&recurring.RemainingExecutions,
+			&recurring.AmountMode,
+			&recurring.EstimateWindow,
+			&recurring.MinAmount,
+			&recurring.MaxAmount,
 			&recurring.CreatedAt,
 			&recurring.UpdatedAt,
 		)
@@ -237,7 +258,8 @@ func (r *recurringTransactionRepository) Update(ctx context.Context, recurring *
 		UPDATE recurring_transactions 
 		SET category_id = $2, account_id = $3, amount = $4, type = $5, note = $6,
 		    frequency = $7, start_date = $8, end_date = $9, next_execution_date = $10,
-		    is_active = $11, auto_execute = $12, remaining_executions = $13, updated_at = $14
+		    is_active = $11, auto_execute = $12, remaining_executions = $13, amount_mode = $14,
+		    estimate_window = $15, min_amount = $16, max_amount = $17, updated_at = $18
 		WHERE id = $1
 	`
 
@@ -257,6 +279,10 @@ func (r *recurringTransactionRepository) Update(ctx context.Context, recurring *
 		recurring.IsActive,
 		recurring.AutoExecute,
 		recurring.RemainingExecutions,
+		recurring.AmountMode,
+		recurring.EstimateWindow,
+		recurring.MinAmount,
+		recurring.MaxAmount,
 		recurring.UpdatedAt,
 	)
 
@@ -295,3 +321,81 @@ func (r *recurringTransactionRepository) MarkAsExecuted(ctx context.Context, id
 	_, err := r.db.ExecContext(ctx, query, id, executedAt, time.Now())
 	return err
 }
+
+// Execute บันทึกผลการรันกฎหนึ่งครั้งใน transaction เดียว: เพิ่มรายการจริง (พร้อมยอดใน daily_category_totals),
+// อัปเดตสถานะของกฎ และบันทึกประวัติการรัน ถ้าขั้นใดล้มเหลวจะไม่มีอะไรถูกบันทึกเลย
+func (r *recurringTransactionRepository) Execute(ctx context.Context, recurring *entity.RecurringTransaction, transaction *entity.Transaction, execution *entity.RecurringExecution) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertTransaction(ctx, tx, transaction); err != nil {
+		return fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	recurring.UpdatedAt = time.Now()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE recurring_transactions
+		SET last_execution_date = $2, next_execution_date = $3, is_active = $4,
+		    remaining_executions = $5, updated_at = $6
+		WHERE id = $1
+	`,
+		recurring.ID,
+		recurring.LastExecutionDate,
+		recurring.NextExecutionDate,
+		recurring.IsActive,
+		recurring.RemainingExecutions,
+		recurring.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update recurring transaction: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO recurring_transaction_executions (
+			id, recurring_transaction_id, transaction_id, estimated_amount, actual_amount, executed_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`,
+		execution.ID,
+		execution.RecurringTransactionID,
+		execution.TransactionID,
+		execution.EstimatedAmount,
+		execution.ActualAmount,
+		execution.ExecutedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record execution: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *recurringTransactionRepository) GetRecentExecutionAmounts(ctx context.Context, recurringID uuid.UUID, limit int) ([]decimal.Decimal, error) {
+	query := `
+		SELECT actual_amount
+		FROM recurring_transaction_executions
+		WHERE recurring_transaction_id = $1
+		ORDER BY executed_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, recurringID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var amounts []decimal.Decimal
+	for rows.Next() {
+		var amount decimal.Decimal
+		if err := rows.Scan(&amount); err != nil {
+			return nil, err
+		}
+		amounts = append(amounts, amount)
+	}
+
+	return amounts, nil
+}
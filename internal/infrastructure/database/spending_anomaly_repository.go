@@ -0,0 +1,195 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+type spendingAnomalyRepository struct {
+	db *sql.DB
+}
+
+func NewSpendingAnomalyRepository(db *sql.DB) repository.SpendingAnomalyRepository {
+	return &spendingAnomalyRepository{db: db}
+}
+
+const spendingAnomalySelect = `
+	SELECT sa.id, sa.user_id, sa.transaction_id, sa.category_id, sa.anomaly_type, sa.severity,
+		   COALESCE(sa.expected_range_min, 0), COALESCE(sa.expected_range_max, 0), sa.actual_amount,
+		   COALESCE(sa.deviation_percentage, 0), COALESCE(sa.robust_z_score, 0), COALESCE(sa.sample_size, 0),
+		   sa.detection_date, sa.description, sa.review_status, sa.reviewed_at,
+		   t.note, t.transaction_date, COALESCE(co.name, c.name)
+	FROM spending_anomalies sa
+	JOIN transactions t ON sa.transaction_id = t.id
+	LEFT JOIN categories c ON sa.category_id = c.id
+	LEFT JOIN category_overrides co ON co.category_id = c.id AND co.user_id = sa.user_id
+`
+
+func (r *spendingAnomalyRepository) Create(ctx context.Context, anomaly *entity.TransactionAnomaly) error {
+	query := `
+		INSERT INTO spending_anomalies (
+			id, user_id, transaction_id, category_id, anomaly_type, severity,
+			expected_range_min, expected_range_max, actual_amount, deviation_percentage,
+			robust_z_score, sample_size, detection_date, description, review_status
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (user_id, transaction_id, anomaly_type) DO NOTHING
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		anomaly.ID,
+		anomaly.UserID,
+		anomaly.TransactionID,
+		anomaly.CategoryID,
+		anomaly.AnomalyType,
+		anomaly.Severity,
+		anomaly.ExpectedRangeMin,
+		anomaly.ExpectedRangeMax,
+		anomaly.ActualAmount,
+		anomaly.DeviationPercentage,
+		anomaly.RobustZScore,
+		anomaly.SampleSize,
+		anomaly.DetectionDate,
+		anomaly.Description,
+		anomaly.ReviewStatus,
+	)
+
+	return err
+}
+
+func (r *spendingAnomalyRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.TransactionAnomaly, error) {
+	query := spendingAnomalySelect + ` WHERE sa.id = $1`
+
+	return scanTransactionAnomaly(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *spendingAnomalyRepository) GetByFilter(ctx context.Context, filter repository.SpendingAnomalyFilter) ([]*entity.TransactionAnomaly, error) {
+	conditions, args := buildSpendingAnomalyConditions(filter)
+	argCount := len(args)
+
+	query := spendingAnomalySelect + `
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY sa.detection_date DESC
+	`
+
+	if filter.Limit > 0 {
+		argCount++
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, filter.Limit)
+	}
+
+	if filter.Offset > 0 {
+		argCount++
+		query += fmt.Sprintf(" OFFSET $%d", argCount)
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var anomalies []*entity.TransactionAnomaly
+	for rows.Next() {
+		anomaly, err := scanTransactionAnomaly(rows)
+		if err != nil {
+			return nil, err
+		}
+		anomalies = append(anomalies, anomaly)
+	}
+
+	return anomalies, rows.Err()
+}
+
+func (r *spendingAnomalyRepository) CountByFilter(ctx context.Context, filter repository.SpendingAnomalyFilter) (int, error) {
+	conditions, args := buildSpendingAnomalyConditions(filter)
+	query := `SELECT COUNT(*) FROM spending_anomalies sa WHERE ` + strings.Join(conditions, " AND ")
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+func (r *spendingAnomalyRepository) UpdateReviewStatus(ctx context.Context, id uuid.UUID, status entity.AnomalyReviewStatus, reviewedAt time.Time) error {
+	query := `UPDATE spending_anomalies SET review_status = $2, reviewed_at = $3 WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id, status, reviewedAt)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func buildSpendingAnomalyConditions(filter repository.SpendingAnomalyFilter) ([]string, []interface{}) {
+	conditions := []string{"sa.user_id = $1"}
+	args := []interface{}{filter.UserID}
+	argCount := 1
+
+	if filter.ReviewStatus != nil {
+		argCount++
+		conditions = append(conditions, fmt.Sprintf("sa.review_status = $%d", argCount))
+		args = append(args, *filter.ReviewStatus)
+	}
+
+	if filter.Severity != nil {
+		argCount++
+		conditions = append(conditions, fmt.Sprintf("sa.severity = $%d", argCount))
+		args = append(args, *filter.Severity)
+	}
+
+	return conditions, args
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTransactionAnomaly(row rowScanner) (*entity.TransactionAnomaly, error) {
+	anomaly := &entity.TransactionAnomaly{}
+	var transactionDate time.Time
+	err := row.Scan(
+		&anomaly.ID,
+		&anomaly.UserID,
+		&anomaly.TransactionID,
+		&anomaly.CategoryID,
+		&anomaly.AnomalyType,
+		&anomaly.Severity,
+		&anomaly.ExpectedRangeMin,
+		&anomaly.ExpectedRangeMax,
+		&anomaly.ActualAmount,
+		&anomaly.DeviationPercentage,
+		&anomaly.RobustZScore,
+		&anomaly.SampleSize,
+		&anomaly.DetectionDate,
+		&anomaly.Description,
+		&anomaly.ReviewStatus,
+		&anomaly.ReviewedAt,
+		&anomaly.TransactionNote,
+		&transactionDate,
+		&anomaly.CategoryName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	anomaly.TransactionDate = &transactionDate
+	return anomaly, nil
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+type spendingPatternRepository struct {
+	db *sql.DB
+}
+
+func NewSpendingPatternRepository(db *sql.DB) repository.SpendingPatternRepository {
+	return &spendingPatternRepository{db: db}
+}
+
+func (r *spendingPatternRepository) ReplaceForUser(ctx context.Context, userID uuid.UUID, patternType string, patterns []*entity.SpendingPatternTrend) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM spending_patterns WHERE user_id = $1 AND pattern_type = $2`, userID, patternType)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO spending_patterns (
+			id, user_id, category_id, pattern_type, frequency, day_of_week, time_of_day,
+			frequency_count, average_amount, trend, change_percentage, confidence_score,
+			start_date, end_date, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`
+
+	for _, pattern := range patterns {
+		_, err := tx.ExecContext(ctx, query,
+			pattern.ID,
+			pattern.UserID,
+			pattern.CategoryID,
+			pattern.PatternType,
+			pattern.Frequency,
+			pattern.DayOfWeek,
+			pattern.TimeOfDay,
+			pattern.FrequencyCount,
+			pattern.AverageAmount,
+			pattern.Trend,
+			pattern.ChangePercentage,
+			pattern.ConfidenceScore,
+			pattern.StartDate,
+			pattern.EndDate,
+			pattern.CreatedAt,
+			pattern.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *spendingPatternRepository) GetByUser(ctx context.Context, userID uuid.UUID, patternType string) ([]*entity.SpendingPatternTrend, error) {
+	query := `
+		SELECT sp.id, sp.user_id, sp.category_id, COALESCE(co.name, c.name, ''), sp.pattern_type, sp.frequency,
+			   COALESCE(sp.day_of_week, ''), COALESCE(sp.time_of_day, ''), sp.frequency_count,
+			   sp.average_amount, sp.trend, COALESCE(sp.change_percentage, 0), sp.confidence_score,
+			   sp.start_date, sp.end_date, sp.created_at, sp.updated_at
+		FROM spending_patterns sp
+		LEFT JOIN categories c ON sp.category_id = c.id
+		LEFT JOIN category_overrides co ON co.category_id = c.id AND co.user_id = sp.user_id
+		WHERE sp.user_id = $1 AND sp.pattern_type = $2
+		ORDER BY sp.confidence_score DESC, sp.frequency_count DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, patternType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var patterns []*entity.SpendingPatternTrend
+	for rows.Next() {
+		pattern := &entity.SpendingPatternTrend{}
+		err := rows.Scan(
+			&pattern.ID,
+			&pattern.UserID,
+			&pattern.CategoryID,
+			&pattern.CategoryName,
+			&pattern.PatternType,
+			&pattern.Frequency,
+			&pattern.DayOfWeek,
+			&pattern.TimeOfDay,
+			&pattern.FrequencyCount,
+			&pattern.AverageAmount,
+			&pattern.Trend,
+			&pattern.ChangePercentage,
+			&pattern.ConfidenceScore,
+			&pattern.StartDate,
+			&pattern.EndDate,
+			&pattern.CreatedAt,
+			&pattern.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, rows.Err()
+}
@@ -23,12 +23,27 @@ func NewTransactionRepository(db *sql.DB) repository.TransactionRepository {
 }
 
 func (r *transactionRepository) Create(ctx context.Context, transaction *entity.Transaction) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertTransaction(ctx, tx, transaction); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertTransaction เพิ่มรายการพร้อมปรับยอดใน daily_category_totals ภายใน transaction ที่ผู้เรียกเปิดไว้
+func insertTransaction(ctx context.Context, tx *sql.Tx, transaction *entity.Transaction) error {
 	query := `
-		INSERT INTO transactions (id, user_id, category_id, account_id, amount, type, note, transaction_date, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO transactions (id, user_id, category_id, account_id, amount, type, note, transaction_date, transaction_time, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := tx.ExecContext(ctx, query,
 		transaction.ID,
 		transaction.UserID,
 		transaction.CategoryID,
@@ -37,16 +52,21 @@ func (r *transactionRepository) Create(ctx context.Context, transaction *entity.
 		transaction.Type,
 		transaction.Note,
 		transaction.TransactionDate,
+		transaction.TransactionTime,
 		transaction.CreatedAt,
 		transaction.UpdatedAt,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	return applyDailyCategoryTotal(ctx, tx, transaction, 1)
 }
 
 func (r *transactionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Transaction, error) {
 	query := `
-		SELECT id, user_id, category_id, account_id, amount, type, note, transaction_date, created_at, updated_at
+		SELECT id, user_id, category_id, account_id, amount, type, note, transaction_date,
+			   TO_CHAR(transaction_time, 'HH24:MI'), created_at, updated_at
 		FROM transactions WHERE id = $1
 	`
 
@@ -60,6 +80,7 @@ func (r *transactionRepository) GetByID(ctx context.Context, id uuid.UUID) (*ent
 		&transaction.Type,
 		&transaction.Note,
 		&transaction.TransactionDate,
+		&transaction.TransactionTime,
 		&transaction.CreatedAt,
 		&transaction.UpdatedAt,
 	)
@@ -72,54 +93,158 @@ func (r *transactionRepository) GetByID(ctx context.Context, id uuid.UUID) (*ent
 }
 
 func (r *transactionRepository) GetByFilter(ctx context.Context, filter repository.TransactionFilter) ([]*entity.Transaction, error) {
+	conditions, args := buildTransactionConditions(filter)
+	argCount := len(args)
+
+	query := `
+		SELECT t.id, t.user_id, t.category_id, t.account_id, t.amount, t.type, t.note, t.transaction_date,
+			   TO_CHAR(t.transaction_time, 'HH24:MI'), t.created_at, t.updated_at
+		FROM transactions t
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY t.transaction_date DESC, t.created_at DESC
+	`
+
+	if filter.Limit > 0 {
+		argCount++
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, filter.Limit)
+	}
+
+	if filter.Offset > 0 {
+		argCount++
+		query += fmt.Sprintf(" OFFSET $%d", argCount)
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []*entity.Transaction
+	for rows.Next() {
+		transaction := &entity.Transaction{}
+		err := rows.Scan(
+			&transaction.ID,
+			&transaction.UserID,
+			&transaction.CategoryID,
+			&transaction.AccountID,
+			&transaction.Amount,
+			&transaction.Type,
+			&transaction.Note,
+			&transaction.TransactionDate,
+			&transaction.TransactionTime,
+			&transaction.CreatedAt,
+			&transaction.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+func (r *transactionRepository) GetRecentWithDetails(ctx context.Context, userID uuid.UUID, limit int) ([]*entity.TransactionDetail, error) {
+	query := `
+		SELECT t.id, t.user_id, t.category_id, t.account_id, t.amount, t.type, t.note, t.transaction_date,
+			   TO_CHAR(t.transaction_time, 'HH24:MI'), t.created_at, t.updated_at,
+			   COALESCE(co.name, c.name), a.name
+		FROM transactions t
+		INNER JOIN categories c ON c.id = t.category_id
+		LEFT JOIN category_overrides co ON co.category_id = c.id AND co.user_id = t.user_id
+		INNER JOIN accounts a ON a.id = t.account_id
+		WHERE t.user_id = $1
+		ORDER BY t.transaction_date DESC, t.created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var details []*entity.TransactionDetail
+	for rows.Next() {
+		transaction := &entity.Transaction{}
+		detail := &entity.TransactionDetail{Transaction: transaction}
+		err := rows.Scan(
+			&transaction.ID,
+			&transaction.UserID,
+			&transaction.CategoryID,
+			&transaction.AccountID,
+			&transaction.Amount,
+			&transaction.Type,
+			&transaction.Note,
+			&transaction.TransactionDate,
+			&transaction.TransactionTime,
+			&transaction.CreatedAt,
+			&transaction.UpdatedAt,
+			&detail.CategoryName,
+			&detail.AccountName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, detail)
+	}
+
+	return details, rows.Err()
+}
+
+// buildTransactionConditions สร้างเงื่อนไข WHERE จาก TransactionFilter โดยอ้างถึงตาราง transactions ด้วย alias t
+func buildTransactionConditions(filter repository.TransactionFilter) ([]string, []interface{}) {
 	var conditions []string
 	var args []interface{}
 	argCount := 0
 
-	// Build WHERE clause
-	conditions = append(conditions, "user_id = $1")
+	conditions = append(conditions, "t.user_id = $1")
 	args = append(args, filter.UserID)
 	argCount = 1
 
 	if filter.AccountID != nil {
 		argCount++
-		conditions = append(conditions, fmt.Sprintf("account_id = $%d", argCount))
+		conditions = append(conditions, fmt.Sprintf("t.account_id = $%d", argCount))
 		args = append(args, *filter.AccountID)
 	}
 
 	if filter.CategoryID != nil {
 		argCount++
-		conditions = append(conditions, fmt.Sprintf("category_id = $%d", argCount))
+		conditions = append(conditions, fmt.Sprintf("t.category_id = $%d", argCount))
 		args = append(args, *filter.CategoryID)
 	}
 
 	if filter.Type != nil {
 		argCount++
-		conditions = append(conditions, fmt.Sprintf("type = $%d", argCount))
+		conditions = append(conditions, fmt.Sprintf("t.type = $%d", argCount))
 		args = append(args, *filter.Type)
 	}
 
 	if filter.StartDate != nil {
 		argCount++
-		conditions = append(conditions, fmt.Sprintf("transaction_date >= $%d", argCount))
+		conditions = append(conditions, fmt.Sprintf("t.transaction_date >= $%d", argCount))
 		args = append(args, *filter.StartDate)
 	}
 
 	if filter.EndDate != nil {
 		argCount++
-		conditions = append(conditions, fmt.Sprintf("transaction_date <= $%d", argCount))
+		conditions = append(conditions, fmt.Sprintf("t.transaction_date <= $%d", argCount))
 		args = append(args, *filter.EndDate)
 	}
 
-	// เพิ่มการค้นหาจาก note หรือชื่อหมวดหมู่
+	// เพิ่มการค้นหาจาก note หรือชื่อหมวดหมู่ (ใช้ชื่อที่ผู้ใช้ override ไว้ถ้ามี)
 	if filter.SearchQuery != nil {
 		argCount++
 		conditions = append(conditions, fmt.Sprintf(`(
-			note ILIKE $%d OR 
+			t.note ILIKE $%d OR 
 			EXISTS (
-				SELECT 1 FROM categories c 
-				WHERE c.id = transactions.category_id 
-				AND c.name ILIKE $%d
+				SELECT 1 FROM categories sc
+				LEFT JOIN category_overrides sco ON sco.category_id = sc.id AND sco.user_id = t.user_id
+				WHERE sc.id = t.category_id
+				AND COALESCE(sco.name, sc.name) ILIKE $%d
 			)
 		)`, argCount, argCount))
 		searchPattern := "%" + *filter.SearchQuery + "%"
@@ -129,75 +254,58 @@ func (r *transactionRepository) GetByFilter(ctx context.Context, filter reposito
 	// เพิ่มการกรองตามจำนวนเงิน
 	if filter.MinAmount != nil {
 		argCount++
-		conditions = append(conditions, fmt.Sprintf("amount >= $%d", argCount))
+		conditions = append(conditions, fmt.Sprintf("t.amount >= $%d", argCount))
 		args = append(args, *filter.MinAmount)
 	}
 
 	if filter.MaxAmount != nil {
 		argCount++
-		conditions = append(conditions, fmt.Sprintf("amount <= $%d", argCount))
+		conditions = append(conditions, fmt.Sprintf("t.amount <= $%d", argCount))
 		args = append(args, *filter.MaxAmount)
 	}
 
-	query := `
-		SELECT t.id, t.user_id, t.category_id, t.account_id, t.amount, t.type, t.note, t.transaction_date, t.created_at, t.updated_at
-		FROM transactions t
-		WHERE ` + strings.Join(conditions, " AND ") + `
-		ORDER BY t.transaction_date DESC, t.created_at DESC
-	`
+	return conditions, args
+}
 
-	if filter.Limit > 0 {
-		argCount++
-		query += fmt.Sprintf(" LIMIT $%d", argCount)
-		args = append(args, filter.Limit)
+func (r *transactionRepository) Update(ctx context.Context, transaction *entity.Transaction) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback()
 
-	if filter.Offset > 0 {
-		argCount++
-		query += fmt.Sprintf(" OFFSET $%d", argCount)
-		args = append(args, filter.Offset)
+	// ล็อกแถวเดิมไว้เพื่อหักยอดเก่าออกจาก daily_category_totals ก่อนบวกยอดใหม่
+	previous := &entity.Transaction{}
+	err = tx.QueryRowContext(ctx, `
+		SELECT user_id, account_id, category_id, type, transaction_date, amount
+		FROM transactions
+		WHERE id = $1
+		FOR UPDATE
+	`, transaction.ID).Scan(
+		&previous.UserID,
+		&previous.AccountID,
+		&previous.CategoryID,
+		&previous.Type,
+		&previous.TransactionDate,
+		&previous.Amount,
+	)
+	if err == sql.ErrNoRows {
+		return nil
 	}
-
-	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var transactions []*entity.Transaction
-	for rows.Next() {
-		transaction := &entity.Transaction{}
-		err := rows.Scan(
-			&transaction.ID,
-			&transaction.UserID,
-			&transaction.CategoryID,
-			&transaction.AccountID,
-			&transaction.Amount,
-			&transaction.Type,
-			&transaction.Note,
-			&transaction.TransactionDate,
-			&transaction.CreatedAt,
-			&transaction.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		transactions = append(transactions, transaction)
+		return err
 	}
 
-	return transactions, nil
-}
-
-func (r *transactionRepository) Update(ctx context.Context, transaction *entity.Transaction) error {
 	query := `
 		UPDATE transactions 
-		SET category_id = $2, account_id = $3, amount = $4, type = $5, note = $6, transaction_date = $7, updated_at = $8
+		SET category_id = $2, account_id = $3, amount = $4, type = $5, note = $6, transaction_date = $7,
+			transaction_time = $8, updated_at = $9
 		WHERE id = $1
 	`
 
 	transaction.UpdatedAt = time.Now()
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, query,
 		transaction.ID,
 		transaction.CategoryID,
 		transaction.AccountID,
@@ -205,16 +313,72 @@ func (r *transactionRepository) Update(ctx context.Context, transaction *entity.
 		transaction.Type,
 		transaction.Note,
 		transaction.TransactionDate,
+		transaction.TransactionTime,
 		transaction.UpdatedAt,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if err := applyDailyCategoryTotal(ctx, tx, previous, -1); err != nil {
+		return err
+	}
+
+	updated := *transaction
+	updated.UserID = previous.UserID
+	if err := applyDailyCategoryTotal(ctx, tx, &updated, 1); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *transactionRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM transactions WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	deleted := &entity.Transaction{}
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM transactions WHERE id = $1
+		RETURNING user_id, account_id, category_id, type, transaction_date, amount
+	`, id).Scan(
+		&deleted.UserID,
+		&deleted.AccountID,
+		&deleted.CategoryID,
+		&deleted.Type,
+		&deleted.TransactionDate,
+		&deleted.Amount,
+	)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := applyDailyCategoryTotal(ctx, tx, deleted, -1); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *transactionRepository) UpdateCategoryByFilter(ctx context.Context, filter repository.TransactionFilter, categoryID uuid.UUID) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	updated, err := recategorizeTransactions(ctx, tx, filter, categoryID)
+	if err != nil {
+		return 0, err
+	}
+
+	return updated, tx.Commit()
 }
 
 func (r *transactionRepository) GetMonthlySpending(ctx context.Context, userID uuid.UUID, year int, month int) (map[uuid.UUID]float64, error) {
@@ -250,3 +414,67 @@ func (r *transactionRepository) GetMonthlySpending(ctx context.Context, userID u
 
 	return result, nil
 }
+
+func (r *transactionRepository) GetDiscretionarySpending(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*entity.DiscretionarySpend, error) {
+	query := `
+		SELECT t.account_id, t.category_id, SUM(t.amount) as total
+		FROM transactions t
+		LEFT JOIN recurring_transaction_executions e ON e.transaction_id = t.id
+		WHERE t.user_id = $1
+		AND t.type = 'expense'
+		AND t.transaction_date >= $2
+		AND t.transaction_date < $3
+		AND e.id IS NULL
+		GROUP BY t.account_id, t.category_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*entity.DiscretionarySpend
+	for rows.Next() {
+		spend := &entity.DiscretionarySpend{}
+		if err := rows.Scan(&spend.AccountID, &spend.CategoryID, &spend.Total); err != nil {
+			return nil, err
+		}
+		results = append(results, spend)
+	}
+
+	return results, nil
+}
+
+func (r *transactionRepository) GetMonthlyCashFlow(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*entity.MonthlyCashFlow, error) {
+	query := `
+		SELECT DATE_TRUNC('month', t.transaction_date) as month,
+			   COALESCE(SUM(CASE WHEN t.type = 'income' THEN t.amount ELSE 0 END), 0) as income,
+			   COALESCE(SUM(CASE WHEN t.type = 'expense' THEN t.amount ELSE 0 END), 0) as expense,
+			   COALESCE(SUM(CASE WHEN t.type = 'expense' AND e.id IS NOT NULL THEN t.amount ELSE 0 END), 0) as recurring_expense
+		FROM transactions t
+		LEFT JOIN recurring_transaction_executions e ON e.transaction_id = t.id
+		WHERE t.user_id = $1
+		AND t.transaction_date >= $2
+		AND t.transaction_date < $3
+		GROUP BY DATE_TRUNC('month', t.transaction_date)
+		ORDER BY month ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*entity.MonthlyCashFlow
+	for rows.Next() {
+		flow := &entity.MonthlyCashFlow{}
+		if err := rows.Scan(&flow.Month, &flow.Income, &flow.Expense, &flow.RecurringExpense); err != nil {
+			return nil, err
+		}
+		results = append(results, flow)
+	}
+
+	return results, rows.Err()
+}
@@ -21,8 +21,8 @@ func NewUserRepository(db *sql.DB) repository.UserRepository {
 
 func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
 	query := `
-		INSERT INTO users (id, email, password_hash, display_name, currency_preference, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO users (id, email, password_hash, display_name, currency_preference, locale, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -31,6 +31,7 @@ func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
 		user.PasswordHash,
 		user.DisplayName,
 		user.CurrencyPreference,
+		user.Locale,
 		user.IsActive,
 		user.CreatedAt,
 		user.UpdatedAt,
@@ -41,7 +42,7 @@ func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
 
 func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
 	query := `
-		SELECT id, email, password_hash, display_name, currency_preference, is_active, last_login_at, created_at, updated_at
+		SELECT id, email, password_hash, display_name, currency_preference, locale, is_active, last_login_at, created_at, updated_at
 		FROM users WHERE id = $1 AND is_active = true
 	`
 
@@ -52,6 +53,7 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Use
 		&user.PasswordHash,
 		&user.DisplayName,
 		&user.CurrencyPreference,
+		&user.Locale,
 		&user.IsActive,
 		&user.LastLoginAt,
 		&user.CreatedAt,
@@ -67,7 +69,7 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Use
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
 	query := `
-		SELECT id, email, password_hash, display_name, currency_preference, is_active, last_login_at, created_at, updated_at
+		SELECT id, email, password_hash, display_name, currency_preference, locale, is_active, last_login_at, created_at, updated_at
 		FROM users WHERE email = $1
 	`
 
@@ -78,6 +80,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.
 		&user.PasswordHash,
 		&user.DisplayName,
 		&user.CurrencyPreference,
+		&user.Locale,
 		&user.IsActive,
 		&user.LastLoginAt,
 		&user.CreatedAt,
@@ -94,7 +97,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.
 func (r *userRepository) Update(ctx context.Context, user *entity.User) error {
 	query := `
 		UPDATE users 
-		SET display_name = $2, currency_preference = $3, updated_at = $4
+		SET display_name = $2, currency_preference = $3, locale = $4, updated_at = $5
 		WHERE id = $1
 	`
 
@@ -104,6 +107,7 @@ func (r *userRepository) Update(ctx context.Context, user *entity.User) error {
 		user.ID,
 		user.DisplayName,
 		user.CurrencyPreference,
+		user.Locale,
 		user.UpdatedAt,
 	)
 
@@ -121,3 +125,71 @@ func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx, query, id, time.Now())
 	return err
 }
+
+func (r *userRepository) GetByCalendarFeedToken(ctx context.Context, token string) (*entity.User, error) {
+	query := `
+		SELECT id, email, password_hash, display_name, currency_preference, locale, is_active, last_login_at, created_at, updated_at
+		FROM users WHERE calendar_feed_token = $1 AND is_active = true
+	`
+
+	user := &entity.User{}
+	err := r.db.QueryRowContext(ctx, query, token).Scan(
+		&user.ID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.DisplayName,
+		&user.CurrencyPreference,
+		&user.Locale,
+		&user.IsActive,
+		&user.LastLoginAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrCalendarFeedNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *userRepository) UpdateCalendarFeedToken(ctx context.Context, userID uuid.UUID, token string) error {
+	query := `UPDATE users SET calendar_feed_token = $2, updated_at = $3 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, userID, token, time.Now())
+	return err
+}
+
+func (r *userRepository) ClearCalendarFeedToken(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE users SET calendar_feed_token = NULL, updated_at = $2 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, userID, time.Now())
+	return err
+}
+
+func (r *userRepository) GetActiveUserIDs(ctx context.Context, limit, offset int) ([]uuid.UUID, error) {
+	query := `
+		SELECT id FROM users
+		WHERE is_active = true
+		ORDER BY created_at ASC, id ASC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, nil
+}
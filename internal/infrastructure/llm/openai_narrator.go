@@ -0,0 +1,215 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"savvy-backend/internal/config"
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/service"
+	"savvy-backend/internal/i18n"
+
+	"github.com/shopspring/decimal"
+)
+
+const systemPrompt = `You write short personal-finance insights for a budgeting app.
+Reply in %s with a JSON object {"title": "...", "content": "..."} and nothing else.
+The title is at most 60 characters. The content is one or two sentences and uses the amounts exactly as given, including their currency.
+Use only the facts provided by the user message.`
+
+var languageNames = map[string]string{
+	i18n.LocaleThai:    "Thai",
+	i18n.LocaleEnglish: "English",
+}
+
+// openAINarrator เรียก API ที่เข้ากันได้กับ OpenAI Chat Completions เพื่อสร้างข้อความ insight
+// prompt สร้างจากข้อมูลที่ส่งเข้ามาในการเรียกครั้งนั้นเท่านั้น และไม่ส่ง ID ใดๆ ออกไป
+// ถ้าเรียก API ไม่สำเร็จจะใช้ fallback แทน
+type openAINarrator struct {
+	client   *http.Client
+	baseURL  string
+	apiKey   string
+	model    string
+	fallback service.InsightNarrator
+}
+
+func NewOpenAINarrator(cfg *config.LLMConfig, fallback service.InsightNarrator) service.InsightNarrator {
+	return &openAINarrator{
+		client:   &http.Client{Timeout: cfg.Timeout},
+		baseURL:  strings.TrimRight(cfg.BaseURL, "/"),
+		apiKey:   cfg.APIKey,
+		model:    cfg.Model,
+		fallback: fallback,
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (o *openAINarrator) NarrateAnomaly(ctx context.Context, audience i18n.Audience, anomaly *entity.SpendingAnomaly) (*service.Narration, error) {
+	facts := map[string]interface{}{
+		"kind":                "spending_anomaly",
+		"category":            anomaly.CategoryName,
+		"period":              anomaly.Period,
+		"current_amount":      money(audience, anomaly.CurrentAmount),
+		"average_amount":      money(audience, anomaly.AverageAmount),
+		"percentage_increase": fmt.Sprintf("%.1f", anomaly.PercentageIncrease),
+		"severity":            anomaly.Severity,
+	}
+
+	narration, err := o.complete(ctx, audience, facts)
+	if err != nil {
+		return o.fallbackOrError(err, func() (*service.Narration, error) {
+			return o.fallback.NarrateAnomaly(ctx, audience, anomaly)
+		})
+	}
+	narration.Message = i18n.AnomalyMessage(anomaly)
+	return narration, nil
+}
+
+func (o *openAINarrator) NarratePattern(ctx context.Context, audience i18n.Audience, pattern *entity.SpendingPattern) (*service.Narration, error) {
+	facts := map[string]interface{}{
+		"kind":            "spending_pattern",
+		"category":        pattern.CategoryName,
+		"day_of_week":     strings.TrimSpace(pattern.DayOfWeek),
+		"frequency_count": pattern.FrequencyCount,
+		"average_amount":  money(audience, pattern.AverageAmount),
+		"window":          "last 30 days",
+	}
+	if pattern.TimeOfDay != "" {
+		facts["time_of_day"] = pattern.TimeOfDay
+	}
+
+	narration, err := o.complete(ctx, audience, facts)
+	if err != nil {
+		return o.fallbackOrError(err, func() (*service.Narration, error) {
+			return o.fallback.NarratePattern(ctx, audience, pattern)
+		})
+	}
+	narration.Message = i18n.PatternMessage(pattern)
+	return narration, nil
+}
+
+func (o *openAINarrator) NarrateBudgetAlert(ctx context.Context, audience i18n.Audience, progress *entity.BudgetProgress) (*service.Narration, error) {
+	facts := map[string]interface{}{
+		"kind":                "budget_alert",
+		"category":            progress.CategoryName,
+		"period":              progress.Period,
+		"budget_amount":       money(audience, progress.BudgetAmount),
+		"spent_amount":        money(audience, progress.SpentAmount),
+		"remaining_amount":    money(audience, progress.RemainingAmount),
+		"progress_percentage": fmt.Sprintf("%.1f", progress.ProgressPercentage),
+		"is_over_budget":      progress.IsOverBudget,
+	}
+
+	narration, err := o.complete(ctx, audience, facts)
+	if err != nil {
+		return o.fallbackOrError(err, func() (*service.Narration, error) {
+			return o.fallback.NarrateBudgetAlert(ctx, audience, progress)
+		})
+	}
+	narration.Message = i18n.BudgetAlertMessage(progress)
+	return narration, nil
+}
+
+func (o *openAINarrator) fallbackOrError(err error, fallback func() (*service.Narration, error)) (*service.Narration, error) {
+	if o.fallback == nil {
+		return nil, err
+	}
+	return fallback()
+}
+
+// money จัดรูปแบบจำนวนเงินตามสกุลเงินของผู้ใช้ก่อนส่งให้ model เพื่อไม่ให้ model แปลงสกุลเงินเอง
+func money(audience i18n.Audience, amount decimal.Decimal) string {
+	return i18n.FormatMoney(amount, audience.Currency, audience.Locale)
+}
+
+// complete ส่ง facts ของ insight หนึ่งรายการไปให้ model และแปลงคำตอบเป็น Narration ในภาษาของผู้ใช้
+func (o *openAINarrator) complete(ctx context.Context, audience i18n.Audience, facts map[string]interface{}) (*service.Narration, error) {
+	factsJSON, err := json.Marshal(facts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode insight facts: %w", err)
+	}
+
+	body, err := json.Marshal(chatRequest{
+		Model: o.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: fmt.Sprintf(systemPrompt, languageNames[audience.Locale])},
+			{Role: "user", Content: string(factsJSON)},
+		},
+		Temperature: 0.2,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode llm request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create llm request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("llm request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("llm request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+
+	var chat chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chat); err != nil {
+		return nil, fmt.Errorf("failed to decode llm response: %w", err)
+	}
+	if len(chat.Choices) == 0 {
+		return nil, fmt.Errorf("llm response has no choices")
+	}
+
+	return parseNarration(chat.Choices[0].Message.Content)
+}
+
+// parseNarration รองรับทั้ง JSON ล้วนและ JSON ที่ model ห่อมาใน code fence
+func parseNarration(content string) (*service.Narration, error) {
+	content = strings.TrimSpace(content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+
+	var narration service.Narration
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &narration); err != nil {
+		return nil, fmt.Errorf("failed to parse llm narration: %w", err)
+	}
+
+	narration.Title = strings.TrimSpace(narration.Title)
+	narration.Content = strings.TrimSpace(narration.Content)
+	if narration.Title == "" || narration.Content == "" {
+		return nil, fmt.Errorf("llm narration is missing title or content")
+	}
+
+	return &narration, nil
+}
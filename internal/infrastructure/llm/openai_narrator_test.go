@@ -0,0 +1,261 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"savvy-backend/internal/config"
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/service"
+	"savvy-backend/internal/i18n"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// stubNarrator ใช้เป็น fallback ใน test และนับจำนวนครั้งที่ถูกเรียก
+type stubNarrator struct {
+	calls int
+}
+
+func (s *stubNarrator) narration() (*service.Narration, error) {
+	s.calls++
+	return &service.Narration{Title: "fallback title", Content: "fallback content"}, nil
+}
+
+func (s *stubNarrator) NarrateAnomaly(ctx context.Context, audience i18n.Audience, anomaly *entity.SpendingAnomaly) (*service.Narration, error) {
+	return s.narration()
+}
+
+func (s *stubNarrator) NarratePattern(ctx context.Context, audience i18n.Audience, pattern *entity.SpendingPattern) (*service.Narration, error) {
+	return s.narration()
+}
+
+func (s *stubNarrator) NarrateBudgetAlert(ctx context.Context, audience i18n.Audience, progress *entity.BudgetProgress) (*service.Narration, error) {
+	return s.narration()
+}
+
+func chatReply(content string) string {
+	body, _ := json.Marshal(chatResponse{Choices: []struct {
+		Message chatMessage `json:"message"`
+	}{{Message: chatMessage{Role: "assistant", Content: content}}}})
+	return string(body)
+}
+
+func newTestNarrator(serverURL string, timeout time.Duration, fallback service.InsightNarrator) *openAINarrator {
+	return NewOpenAINarrator(&config.LLMConfig{
+		BaseURL: serverURL + "/",
+		APIKey:  "test-key",
+		Model:   "test-model",
+		Timeout: timeout,
+	}, fallback).(*openAINarrator)
+}
+
+func testAnomaly(categoryName string) *entity.SpendingAnomaly {
+	return &entity.SpendingAnomaly{
+		CategoryID:         uuid.New(),
+		CategoryName:       categoryName,
+		CurrentAmount:      decimal.NewFromInt(4500),
+		AverageAmount:      decimal.NewFromInt(2000),
+		PercentageIncrease: 125,
+		Period:             "2026-10",
+		Severity:           "high",
+	}
+}
+
+func TestOpenAINarratorSuccess(t *testing.T) {
+	var requests []chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("path = %s, want /chat/completions", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Authorization = %q, want \"Bearer test-key\"", got)
+		}
+
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		requests = append(requests, req)
+
+		fmt.Fprint(w, chatReply("```json\n{\"title\": \"Food spending jumped\", \"content\": \"You spent more on food.\"}\n```"))
+	}))
+	defer server.Close()
+
+	fallback := &stubNarrator{}
+	narrator := newTestNarrator(server.URL, time.Second, fallback)
+	audience := i18n.NewAudience("en", "THB")
+
+	first := testAnomaly("Food")
+	narration, err := narrator.NarrateAnomaly(context.Background(), audience, first)
+	if err != nil {
+		t.Fatalf("NarrateAnomaly() error = %v", err)
+	}
+	if narration.Title != "Food spending jumped" || narration.Content != "You spent more on food." {
+		t.Fatalf("NarrateAnomaly() = %+v", narration)
+	}
+	if narration.Message.Key != i18n.AnomalyMessage(first).Key {
+		t.Errorf("Message.Key = %q, want %q", narration.Message.Key, i18n.AnomalyMessage(first).Key)
+	}
+	if fallback.calls != 0 {
+		t.Errorf("fallback called %d time(s), want 0", fallback.calls)
+	}
+
+	second := testAnomaly("Travel")
+	if _, err := narrator.NarrateAnomaly(context.Background(), audience, second); err != nil {
+		t.Fatalf("NarrateAnomaly() second call error = %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("server received %d request(s), want 2", len(requests))
+	}
+	for i, req := range requests {
+		if req.Model != "test-model" || len(req.Messages) != 2 {
+			t.Fatalf("request %d = %+v", i, req)
+		}
+		if !strings.Contains(req.Messages[0].Content, "English") {
+			t.Errorf("request %d system prompt does not ask for English", i)
+		}
+	}
+
+	// prompt ของแต่ละครั้งต้องมีเฉพาะ facts ของ insight นั้น ไม่มี ID และไม่ปนข้อมูลของการเรียกครั้งก่อน
+	tests := []struct {
+		name      string
+		prompt    string
+		anomaly   *entity.SpendingAnomaly
+		forbidden []string
+	}{
+		{name: "first", prompt: requests[0].Messages[1].Content, anomaly: first, forbidden: []string{first.CategoryID.String(), "Travel"}},
+		{name: "second", prompt: requests[1].Messages[1].Content, anomaly: second, forbidden: []string{second.CategoryID.String(), first.CategoryID.String(), "Food"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var facts map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.prompt), &facts); err != nil {
+				t.Fatalf("user message is not JSON facts: %v", err)
+			}
+			if facts["category"] != tt.anomaly.CategoryName {
+				t.Errorf("facts category = %v, want %s", facts["category"], tt.anomaly.CategoryName)
+			}
+			for _, value := range tt.forbidden {
+				if strings.Contains(tt.prompt, value) {
+					t.Errorf("prompt %s contains %q", tt.prompt, value)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAINarratorFallback(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		timeout time.Duration
+	}{
+		{
+			name: "non-200 status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "rate limited", http.StatusTooManyRequests)
+			},
+		},
+		{
+			name: "malformed response body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "{not json")
+			},
+		},
+		{
+			name: "no choices",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"choices": []}`)
+			},
+		},
+		{
+			name: "malformed narration content",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, chatReply("Sure! Here is your insight."))
+			},
+		},
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(time.Second):
+				case <-r.Context().Done():
+				}
+			},
+			timeout: 50 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = time.Second
+			}
+
+			fallback := &stubNarrator{}
+			narrator := newTestNarrator(server.URL, timeout, fallback)
+			narration, err := narrator.NarrateAnomaly(context.Background(), i18n.DefaultAudience(), testAnomaly("Food"))
+			if err != nil {
+				t.Fatalf("NarrateAnomaly() error = %v, want fallback narration", err)
+			}
+			if narration.Title != "fallback title" || fallback.calls != 1 {
+				t.Fatalf("NarrateAnomaly() = %+v with %d fallback call(s), want fallback", narration, fallback.calls)
+			}
+
+			withoutFallback := newTestNarrator(server.URL, timeout, nil)
+			if _, err := withoutFallback.NarrateAnomaly(context.Background(), i18n.DefaultAudience(), testAnomaly("Food")); err == nil {
+				t.Fatal("NarrateAnomaly() without fallback returned no error")
+			}
+		})
+	}
+}
+
+func TestParseNarration(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantTitle   string
+		wantContent string
+		wantErr     bool
+	}{
+		{name: "plain json", content: `{"title": "Title", "content": "Body"}`, wantTitle: "Title", wantContent: "Body"},
+		{name: "json code fence", content: "```json\n{\"title\": \"Title\", \"content\": \"Body\"}\n```", wantTitle: "Title", wantContent: "Body"},
+		{name: "bare code fence", content: "```\n{\"title\": \"Title\", \"content\": \"Body\"}\n```", wantTitle: "Title", wantContent: "Body"},
+		{name: "trims whitespace", content: `  {"title": "  Title ", "content": " Body  "}  `, wantTitle: "Title", wantContent: "Body"},
+		{name: "missing title", content: `{"content": "Body"}`, wantErr: true},
+		{name: "blank content", content: `{"title": "Title", "content": "   "}`, wantErr: true},
+		{name: "not json", content: "Title: Body", wantErr: true},
+		{name: "empty", content: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			narration, err := parseNarration(tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseNarration() = %+v, want error", narration)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseNarration() error = %v", err)
+			}
+			if narration.Title != tt.wantTitle || narration.Content != tt.wantContent {
+				t.Fatalf("parseNarration() = %+v, want title %q content %q", narration, tt.wantTitle, tt.wantContent)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"savvy-backend/internal/domain/entity"
 	"savvy-backend/internal/domain/repository"
+	"savvy-backend/internal/domain/service"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -20,12 +21,14 @@ type AccountUsecase interface {
 }
 
 type accountUsecase struct {
-	accountRepo repository.AccountRepository
+	accountRepo   repository.AccountRepository
+	responseCache service.ResponseCache
 }
 
-func NewAccountUsecase(accountRepo repository.AccountRepository) AccountUsecase {
+func NewAccountUsecase(accountRepo repository.AccountRepository, responseCache service.ResponseCache) AccountUsecase {
 	return &accountUsecase{
-		accountRepo: accountRepo,
+		accountRepo:   accountRepo,
+		responseCache: responseCache,
 	}
 }
 
@@ -37,6 +40,7 @@ func (a *accountUsecase) CreateAccount(ctx context.Context, userID uuid.UUID, na
 	if err != nil {
 		return nil, err
 	}
+	invalidateUserCache(ctx, a.responseCache, userID)
 
 	return account, nil
 }
@@ -63,7 +67,12 @@ func (a *accountUsecase) UpdateAccount(ctx context.Context, userID uuid.UUID, ac
 		return errors.New("account does not belong to user")
 	}
 
-	return a.accountRepo.Update(ctx, account)
+	if err := a.accountRepo.Update(ctx, account); err != nil {
+		return err
+	}
+
+	invalidateUserCache(ctx, a.responseCache, userID)
+	return nil
 }
 
 func (a *accountUsecase) DeleteAccount(ctx context.Context, userID, accountID uuid.UUID) error {
@@ -76,5 +85,10 @@ func (a *accountUsecase) DeleteAccount(ctx context.Context, userID, accountID uu
 		return errors.New("account does not belong to user")
 	}
 
-	return a.accountRepo.Delete(ctx, accountID)
+	if err := a.accountRepo.Delete(ctx, accountID); err != nil {
+		return err
+	}
+
+	invalidateUserCache(ctx, a.responseCache, userID)
+	return nil
 }
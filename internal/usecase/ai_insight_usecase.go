@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"savvy-backend/internal/domain/entity"
 	"savvy-backend/internal/domain/repository"
+	"savvy-backend/internal/domain/service"
+	"savvy-backend/internal/i18n"
 
 	"github.com/google/uuid"
 )
@@ -18,15 +22,31 @@ type AIInsightUsecase interface {
 	GenerateSpendingPatternInsights(ctx context.Context, userID uuid.UUID) ([]*entity.Insight, error)
 	GenerateCategoryRecommendations(ctx context.Context, userID uuid.UUID, transactionNote string) ([]*entity.Insight, error)
 	GenerateSavingsRecommendations(ctx context.Context, userID uuid.UUID) ([]*entity.Insight, error)
+	DetectSubscriptions(ctx context.Context, userID uuid.UUID) ([]*entity.Insight, error)
 	ProcessWeeklyInsights(ctx context.Context, userID uuid.UUID) error
-	ProcessAllUsersInsights(ctx context.Context) error
+	ProcessAllUsersInsights(ctx context.Context) (*entity.InsightProcessingReport, error)
+	GetSpendingAnomalies(ctx context.Context, userID uuid.UUID, months int) ([]*entity.SpendingAnomaly, error)
+	GetSpendingPatterns(ctx context.Context, userID uuid.UUID, days int) ([]*entity.SpendingPattern, error)
+	AnalyzeSpendingPatternTrends(ctx context.Context, userID uuid.UUID) ([]*entity.SpendingPatternTrend, error)
+	GetSpendingPatternTrends(ctx context.Context, userID uuid.UUID) ([]*entity.SpendingPatternTrend, error)
 }
 
+// จำนวนผู้ใช้ที่ดึงจากฐานข้อมูลต่อครั้งตอนประมวลผลทุกคน
+const insightUserPageSize = 500
+
 type aiInsightUsecase struct {
 	insightRepo     repository.InsightRepository
 	transactionRepo repository.TransactionRepository
 	categoryRepo    repository.CategoryRepository
 	budgetRepo      repository.BudgetRepository
+	recurringRepo   repository.RecurringTransactionRepository
+	userRepo        repository.UserRepository
+	patternRepo     repository.SpendingPatternRepository
+	feedbackRepo    repository.InsightFeedbackRepository
+	forecast        ForecastUsecase
+	narrator        service.InsightNarrator
+	concurrency     int
+	userTimeout     time.Duration
 }
 
 func NewAIInsightUsecase(
@@ -34,59 +54,83 @@ func NewAIInsightUsecase(
 	transactionRepo repository.TransactionRepository,
 	categoryRepo repository.CategoryRepository,
 	budgetRepo repository.BudgetRepository,
+	recurringRepo repository.RecurringTransactionRepository,
+	userRepo repository.UserRepository,
+	patternRepo repository.SpendingPatternRepository,
+	feedbackRepo repository.InsightFeedbackRepository,
+	forecast ForecastUsecase,
+	narrator service.InsightNarrator,
+	concurrency int,
+	userTimeout time.Duration,
 ) AIInsightUsecase {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
 	return &aiInsightUsecase{
 		insightRepo:     insightRepo,
 		transactionRepo: transactionRepo,
 		categoryRepo:    categoryRepo,
 		budgetRepo:      budgetRepo,
+		recurringRepo:   recurringRepo,
+		userRepo:        userRepo,
+		patternRepo:     patternRepo,
+		feedbackRepo:    feedbackRepo,
+		forecast:        forecast,
+		narrator:        narrator,
+		concurrency:     concurrency,
+		userTimeout:     userTimeout,
 	}
 }
 
 func (a *aiInsightUsecase) GenerateSpendingAnomalyInsights(ctx context.Context, userID uuid.UUID) ([]*entity.Insight, error) {
+	prefs, err := loadInsightPreferences(ctx, a.feedbackRepo, a.categoryRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !a.allowsType(ctx, userID, prefs, entity.InsightTypeAnomalyDetection) {
+		return nil, nil
+	}
+
 	// Look for spending anomalies in the last 6 months
 	anomalies, err := a.insightRepo.GetSpendingAnomalies(ctx, userID, 6)
 	if err != nil {
 		return nil, err
 	}
 
+	audience := loadAudience(ctx, a.userRepo, userID)
 	var insights []*entity.Insight
 
 	for _, anomaly := range anomalies {
-		var priority entity.InsightPriority
-		var title, message string
+		if prefs.isCategoryMuted(anomaly.CategoryID) {
+			continue
+		}
 
+		var priority entity.InsightPriority
 		switch anomaly.Severity {
 		case "high":
 			priority = entity.InsightPriorityHigh
-			title = fmt.Sprintf("🚨 การใช้จ่าย %s เพิ่มขึ้นอย่างมาก!", anomaly.CategoryName)
-			currentAmount, _ := anomaly.CurrentAmount.Float64()
-			avgAmount, _ := anomaly.AverageAmount.Float64()
-			message = fmt.Sprintf("เดือนนี้คุณใช้จ่ายในหมวด '%s' ถึง %.2f บาท เพิ่มขึ้น %.1f%% จากค่าเฉลี่ย %.2f บาท ลองตรวจสอบว่ามีรายจ่ายผิดปกติหรือไม่",
-				anomaly.CategoryName, currentAmount, anomaly.PercentageIncrease, avgAmount)
 		case "medium":
 			priority = entity.InsightPriorityMedium
-			title = fmt.Sprintf("⚠️ การใช้จ่าย %s เพิ่มขึ้น", anomaly.CategoryName)
-			currentAmount, _ := anomaly.CurrentAmount.Float64()
-			avgAmount, _ := anomaly.AverageAmount.Float64()
-			message = fmt.Sprintf("เดือนนี้คุณใช้จ่ายในหมวด '%s' %.2f บาท เพิ่มขึ้น %.1f%% จากค่าเฉลี่ย %.2f บาท",
-				anomaly.CategoryName, currentAmount, anomaly.PercentageIncrease, avgAmount)
 		default:
 			priority = entity.InsightPriorityLow
-			title = fmt.Sprintf("💡 การใช้จ่าย %s เปลี่ยนแปลง", anomaly.CategoryName)
-			currentAmount, _ := anomaly.CurrentAmount.Float64()
-			avgAmount, _ := anomaly.AverageAmount.Float64()
-			message = fmt.Sprintf("เดือนนี้คุณใช้จ่ายในหมวด '%s' %.2f บาท เพิ่มขึ้น %.1f%% จากค่าเฉลี่ย %.2f บาท",
-				anomaly.CategoryName, currentAmount, anomaly.PercentageIncrease, avgAmount)
 		}
 
-		insight := entity.NewAdvancedInsight(userID, entity.InsightTypeAnomalyDetection, priority, title, message)
+		narration, err := a.narrator.NarrateAnomaly(ctx, audience, anomaly)
+		if err != nil {
+			// ใช้ข้อความจาก template แทน ไม่อย่างนั้น saveInsights จะ resolve insight เดิมว่าไม่เกี่ยวข้องแล้ว
+			log.Printf("insight narrator failed for user %s: %v", userID, err)
+			narration = narrate(audience, i18n.AnomalyMessage(anomaly))
+		}
+
+		insight := newNarratedInsight(userID, entity.InsightTypeAnomalyDetection, priority, audience, narration)
 		insight.RelatedEntityID = &anomaly.CategoryID
 		insight.RelatedEntityType = &[]string{"category"}[0]
 
 		// Convert anomaly to JSON for related data
 		anomalyJSON, _ := json.Marshal(anomaly)
 		insight.RelatedData = anomalyJSON
+		insight.SetFingerprint(anomaly.Period)
 
 		validUntil := time.Now().AddDate(0, 0, 7) // Valid for 7 days
 		insight.ValidUntil = &validUntil
@@ -94,42 +138,53 @@ func (a *aiInsightUsecase) GenerateSpendingAnomalyInsights(ctx context.Context,
 		insights = append(insights, insight)
 	}
 
-	// Save insights to database
-	for _, insight := range insights {
-		err := a.insightRepo.Create(ctx, insight)
-		if err != nil {
-			continue // Log error but don't fail
-		}
+	// Save insights to database (regenerated insights update the existing row)
+	if err := saveInsights(ctx, a.insightRepo, userID, []entity.InsightType{entity.InsightTypeAnomalyDetection}, insights); err != nil {
+		return nil, err
 	}
 
 	return insights, nil
 }
 
 func (a *aiInsightUsecase) GenerateSpendingPatternInsights(ctx context.Context, userID uuid.UUID) ([]*entity.Insight, error) {
+	prefs, err := loadInsightPreferences(ctx, a.feedbackRepo, a.categoryRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !a.allowsType(ctx, userID, prefs, entity.InsightTypeSpendingPattern) {
+		return nil, nil
+	}
+
 	// Analyze spending patterns in the last 30 days
 	patterns, err := a.insightRepo.GetSpendingPatterns(ctx, userID, 30)
 	if err != nil {
 		return nil, err
 	}
 
+	audience := loadAudience(ctx, a.userRepo, userID)
 	var insights []*entity.Insight
 
 	for _, pattern := range patterns {
+		if prefs.isCategoryMuted(pattern.CategoryID) {
+			continue
+		}
+
 		if pattern.FrequencyCount >= 5 { // Only show patterns with significant frequency
-			title := fmt.Sprintf("📊 พฤติกรรมการใช้จ่าย %s", pattern.CategoryName)
-			avgAmount, _ := pattern.AverageAmount.Float64()
+			narration, err := a.narrator.NarratePattern(ctx, audience, pattern)
+			if err != nil {
+				log.Printf("insight narrator failed for user %s: %v", userID, err)
+				narration = narrate(audience, i18n.PatternMessage(pattern))
+			}
 			dayOfWeek := strings.TrimSpace(pattern.DayOfWeek)
 
-			message := fmt.Sprintf("คุณมักใช้จ่ายหมวด '%s' ในช่วง%s ของวัน%s โดยเฉลี่ย %.2f บาท/ครั้ง (%d ครั้งในเดือนที่ผ่านมา)",
-				pattern.CategoryName, pattern.TimeOfDay, dayOfWeek, avgAmount, pattern.FrequencyCount)
-
-			insight := entity.NewAdvancedInsight(userID, entity.InsightTypeSpendingPattern, entity.InsightPriorityLow, title, message)
+			insight := newNarratedInsight(userID, entity.InsightTypeSpendingPattern, entity.InsightPriorityLow, audience, narration)
 			insight.RelatedEntityID = &pattern.CategoryID
 			insight.RelatedEntityType = &[]string{"category"}[0]
 
 			// Convert pattern to JSON for related data
 			patternJSON, _ := json.Marshal(pattern)
 			insight.RelatedData = patternJSON
+			insight.SetFingerprint(fmt.Sprintf("30d:%s:%s", dayOfWeek, pattern.TimeOfDay))
 
 			validUntil := time.Now().AddDate(0, 0, 14) // Valid for 14 days
 			insight.ValidUntil = &validUntil
@@ -138,12 +193,9 @@ func (a *aiInsightUsecase) GenerateSpendingPatternInsights(ctx context.Context,
 		}
 	}
 
-	// Save insights to database
-	for _, insight := range insights {
-		err := a.insightRepo.Create(ctx, insight)
-		if err != nil {
-			continue
-		}
+	// Save insights to database (regenerated insights update the existing row)
+	if err := saveInsights(ctx, a.insightRepo, userID, []entity.InsightType{entity.InsightTypeSpendingPattern}, insights); err != nil {
+		return nil, err
 	}
 
 	return insights, nil
@@ -154,6 +206,14 @@ func (a *aiInsightUsecase) GenerateCategoryRecommendations(ctx context.Context,
 		return nil, nil
 	}
 
+	prefs, err := loadInsightPreferences(ctx, a.feedbackRepo, a.categoryRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !a.allowsType(ctx, userID, prefs, entity.InsightTypeCategoryRecommendation) {
+		return nil, nil
+	}
+
 	// Simple keyword-based category recommendation
 	// In a real AI system, this would use NLP/ML models
 	categoryKeywords := map[string][]string{
@@ -181,11 +241,16 @@ func (a *aiInsightUsecase) GenerateCategoryRecommendations(ctx context.Context,
 	var insights []*entity.Insight
 
 	if len(suggestedCategories) > 0 {
-		title := "💡 คำแนะนำหมวดหมู่"
-		message := fmt.Sprintf("จากรายการ '%s' ระบบแนะนำหมวดหมู่: %s",
-			transactionNote, strings.Join(suggestedCategories, ", "))
+		message := i18n.Message{
+			Key: "category_recommendation",
+			Params: map[string]interface{}{
+				"note":        transactionNote,
+				"suggestions": strings.Join(suggestedCategories, ", "),
+			},
+		}
 
-		insight := entity.NewAdvancedInsight(userID, entity.InsightTypeCategoryRecommendation, entity.InsightPriorityLow, title, message)
+		audience := loadAudience(ctx, a.userRepo, userID)
+		insight := newLocalizedInsight(userID, entity.InsightTypeCategoryRecommendation, entity.InsightPriorityLow, audience, message)
 
 		// Add suggested categories to related data
 		suggestionData := map[string]interface{}{
@@ -211,16 +276,29 @@ func (a *aiInsightUsecase) GenerateCategoryRecommendations(ctx context.Context,
 }
 
 func (a *aiInsightUsecase) GenerateSavingsRecommendations(ctx context.Context, userID uuid.UUID) ([]*entity.Insight, error) {
+	prefs, err := loadInsightPreferences(ctx, a.feedbackRepo, a.categoryRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !a.allowsType(ctx, userID, prefs, entity.InsightTypeSavingsRecommendation) {
+		return nil, nil
+	}
+
 	// Get spending patterns to generate savings recommendations
 	patterns, err := a.insightRepo.GetSpendingPatterns(ctx, userID, 30)
 	if err != nil {
 		return nil, err
 	}
 
+	audience := loadAudience(ctx, a.userRepo, userID)
 	var insights []*entity.Insight
 
 	// Find categories with high frequency and suggest savings
 	for _, pattern := range patterns {
+		if prefs.isCategoryMuted(pattern.CategoryID) {
+			continue
+		}
+
 		if pattern.FrequencyCount >= 10 { // High frequency spending
 			avgAmount, _ := pattern.AverageAmount.Float64()
 			totalSpent := avgAmount * float64(pattern.FrequencyCount)
@@ -229,11 +307,17 @@ func (a *aiInsightUsecase) GenerateSavingsRecommendations(ctx context.Context, u
 				potentialSavings := avgAmount * 0.2 // Assume 20% savings potential
 				monthlySavings := potentialSavings * float64(pattern.FrequencyCount)
 
-				title := fmt.Sprintf("💰 โอกาสประหยัด %s", pattern.CategoryName)
-				message := fmt.Sprintf("คุณใช้จ่าย '%s' บ่อย (%d ครั้ง/เดือน) เฉลี่ย %.2f บาท/ครั้ง หากลดลง 20%% จะประหยัดได้ %.2f บาท/เดือน",
-					pattern.CategoryName, pattern.FrequencyCount, avgAmount, monthlySavings)
+				message := i18n.Message{
+					Key: "savings",
+					Params: map[string]interface{}{
+						"category":        pattern.CategoryName,
+						"frequency_count": pattern.FrequencyCount,
+						"average_amount":  pattern.AverageAmount.StringFixed(2),
+						"monthly_savings": fmt.Sprintf("%.2f", monthlySavings),
+					},
+				}
 
-				insight := entity.NewAdvancedInsight(userID, entity.InsightTypeSavingsRecommendation, entity.InsightPriorityMedium, title, message)
+				insight := newLocalizedInsight(userID, entity.InsightTypeSavingsRecommendation, entity.InsightPriorityMedium, audience, message)
 				insight.RelatedEntityID = &pattern.CategoryID
 				insight.RelatedEntityType = &[]string{"category"}[0]
 
@@ -247,6 +331,7 @@ func (a *aiInsightUsecase) GenerateSavingsRecommendations(ctx context.Context, u
 				}
 				recommendationJSON, _ := json.Marshal(recommendationData)
 				insight.RelatedData = recommendationJSON
+				insight.SetFingerprint("30d")
 
 				validUntil := time.Now().AddDate(0, 0, 14) // Valid for 14 days
 				insight.ValidUntil = &validUntil
@@ -256,12 +341,141 @@ func (a *aiInsightUsecase) GenerateSavingsRecommendations(ctx context.Context, u
 		}
 	}
 
-	// Save insights to database
-	for _, insight := range insights {
-		err := a.insightRepo.Create(ctx, insight)
-		if err != nil {
+	// Save insights to database (regenerated insights update the existing row)
+	if err := saveInsights(ctx, a.insightRepo, userID, []entity.InsightType{entity.InsightTypeSavingsRecommendation}, insights); err != nil {
+		return nil, err
+	}
+
+	return insights, nil
+}
+
+func (a *aiInsightUsecase) DetectSubscriptions(ctx context.Context, userID uuid.UUID) ([]*entity.Insight, error) {
+	prefs, err := loadInsightPreferences(ctx, a.feedbackRepo, a.categoryRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowDetected := a.allowsType(ctx, userID, prefs, entity.InsightTypeSubscriptionDetected)
+	allowIncrease := a.allowsType(ctx, userID, prefs, entity.InsightTypeSubscriptionPriceIncrease)
+
+	// Only the types generated in this run take part in resolving stale insights
+	var insightTypes []entity.InsightType
+	if allowDetected {
+		insightTypes = append(insightTypes, entity.InsightTypeSubscriptionDetected)
+	}
+	if allowIncrease {
+		insightTypes = append(insightTypes, entity.InsightTypeSubscriptionPriceIncrease)
+	}
+	if len(insightTypes) == 0 {
+		return nil, nil
+	}
+
+	// Look back a little over a year so yearly subscriptions show up at least twice
+	startDate := time.Now().AddDate(0, 0, -400)
+	expenseType := entity.TransactionTypeExpense
+	transactions, err := a.transactionRepo.GetByFilter(ctx, repository.TransactionFilter{
+		UserID:    userID,
+		Type:      &expenseType,
+		StartDate: &startDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	isActive := true
+	rules, err := a.recurringRepo.GetByFilter(ctx, repository.RecurringTransactionFilter{
+		UserID:   userID,
+		IsActive: &isActive,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, increases := detectSubscriptions(transactions, rules)
+
+	audience := loadAudience(ctx, a.userRepo, userID)
+	var insights []*entity.Insight
+
+	for _, candidate := range candidates {
+		if !allowDetected || prefs.isCategoryMuted(candidate.CategoryID) {
 			continue
 		}
+
+		message := i18n.Message{
+			Key: "subscription_detected",
+			Params: map[string]interface{}{
+				"note":        candidate.Note,
+				"occurrences": candidate.Occurrences,
+				"frequency":   string(candidate.Frequency),
+				"amount":      candidate.Amount.StringFixed(2),
+			},
+		}
+
+		insight := newLocalizedInsight(userID, entity.InsightTypeSubscriptionDetected, entity.InsightPriorityMedium, audience, message)
+		insight.RelatedEntityID = &candidate.CategoryID
+		insight.RelatedEntityType = &[]string{"category"}[0]
+
+		// The proposed rule mirrors the create recurring transaction request body
+		proposal := map[string]interface{}{
+			"subscription": candidate,
+			"proposed_recurring_transaction": map[string]interface{}{
+				"category_id": candidate.CategoryID,
+				"account_id":  candidate.AccountID,
+				"amount":      candidate.Amount.String(),
+				"type":        entity.TransactionTypeExpense,
+				"note":        candidate.Note,
+				"frequency":   candidate.Frequency,
+				"start_date":  candidate.NextExpectedDate.Format("2006-01-02"),
+			},
+		}
+		proposalJSON, _ := json.Marshal(proposal)
+		insight.RelatedData = proposalJSON
+		insight.SetFingerprint(normalizeMerchantNote(candidate.Note))
+
+		validUntil := time.Now().AddDate(0, 0, 30) // Valid for 30 days
+		insight.ValidUntil = &validUntil
+
+		insights = append(insights, insight)
+	}
+
+	for _, increase := range increases {
+		if !allowIncrease || prefs.isCategoryMuted(increase.CategoryID) {
+			continue
+		}
+
+		message := i18n.Message{
+			Key: "subscription_price_increase",
+			Params: map[string]interface{}{
+				"note":                increase.Note,
+				"new_amount":          increase.NewAmount.StringFixed(2),
+				"previous_amount":     increase.PreviousAmount.StringFixed(2),
+				"percentage_increase": increase.PercentageIncrease,
+			},
+		}
+
+		insight := newLocalizedInsight(userID, entity.InsightTypeSubscriptionPriceIncrease, entity.InsightPriorityHigh, audience, message)
+		if increase.RecurringTransactionID != nil {
+			insight.RelatedEntityID = increase.RecurringTransactionID
+			insight.RelatedEntityType = &[]string{"recurring_transaction"}[0]
+		} else {
+			insight.RelatedEntityID = &increase.CategoryID
+			insight.RelatedEntityType = &[]string{"category"}[0]
+		}
+
+		increaseJSON, _ := json.Marshal(increase)
+		insight.RelatedData = increaseJSON
+		// Same price stays the same insight, a further increase is a new one
+		insight.SetFingerprint(normalizeMerchantNote(increase.Note) + ":" + increase.NewAmount.String())
+
+		validUntil := time.Now().AddDate(0, 0, 14) // Valid for 14 days
+		insight.ValidUntil = &validUntil
+
+		insights = append(insights, insight)
+	}
+
+	// Save insights to database (regenerated insights update the existing row)
+	if err := saveInsights(ctx, a.insightRepo, userID, insightTypes, insights); err != nil {
+		return nil, err
 	}
 
 	return insights, nil
@@ -284,12 +498,159 @@ func (a *aiInsightUsecase) ProcessWeeklyInsights(ctx context.Context, userID uui
 		return fmt.Errorf("failed to generate savings recommendations: %w", err)
 	}
 
+	_, err = a.DetectSubscriptions(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to detect subscriptions: %w", err)
+	}
+
+	_, err = a.AnalyzeSpendingPatternTrends(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to analyze spending pattern trends: %w", err)
+	}
+
+	if err := a.checkCashFlowWarnings(ctx, userID); err != nil {
+		return fmt.Errorf("failed to check cash flow warnings: %w", err)
+	}
+
 	return nil
 }
 
-func (a *aiInsightUsecase) ProcessAllUsersInsights(ctx context.Context) error {
-	// This method would typically be called by a scheduled job
-	// For now, we'll just return nil as we don't have a user repository
-	// In a real implementation, you'd fetch all active users and process insights for each
-	return nil
+// checkCashFlowWarnings ให้ job สร้าง (และ resolve) คำเตือนยอดติดลบล่วงหน้าเองโดยผู้ใช้ไม่ต้องเรียก
+// ข้ามเฉพาะเมื่อผู้ใช้ mute ประเภทนี้ ไม่ใช้ cooldown ของ insight ที่ได้คะแนนต่ำเพราะเป็นคำเตือนความสำคัญสูง
+func (a *aiInsightUsecase) checkCashFlowWarnings(ctx context.Context, userID uuid.UUID) error {
+	if a.forecast == nil {
+		return nil
+	}
+
+	prefs, err := loadInsightPreferences(ctx, a.feedbackRepo, a.categoryRepo, userID)
+	if err != nil {
+		return err
+	}
+	if prefs.isTypeMuted(entity.InsightTypeCashflowWarning) {
+		return nil
+	}
+
+	_, err = a.forecast.CheckCashFlowWarnings(ctx, userID, DefaultForecastDays)
+	return err
+}
+
+// ProcessAllUsersInsights รัน ProcessWeeklyInsights ให้ผู้ใช้ที่ active ทุกคนแบบขนาน (จำกัดจำนวน worker)
+// แต่ละคนมี timeout ของตัวเอง และความผิดพลาดของคนหนึ่งไม่กระทบคนอื่น
+func (a *aiInsightUsecase) ProcessAllUsersInsights(ctx context.Context) (*entity.InsightProcessingReport, error) {
+	report := &entity.InsightProcessingReport{
+		StartedAt:      time.Now(),
+		SucceededUsers: make([]uuid.UUID, 0),
+		FailedUsers:    make([]*entity.InsightProcessingFailure, 0),
+	}
+
+	userIDs := make(chan uuid.UUID)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < a.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for userID := range userIDs {
+				err := a.processUserInsights(ctx, userID)
+
+				if err != nil {
+					log.Printf("insight job: failed to process user %s: %v", userID, err)
+				}
+
+				mu.Lock()
+				if err != nil {
+					report.FailedUsers = append(report.FailedUsers, &entity.InsightProcessingFailure{
+						UserID: userID,
+						Error:  err.Error(),
+					})
+				} else {
+					report.SucceededUsers = append(report.SucceededUsers, userID)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	// Page through users so memory stays flat no matter how many users there are
+	var listErr error
+	offset := 0
+	for listErr == nil {
+		page, err := a.userRepo.GetActiveUserIDs(ctx, insightUserPageSize, offset)
+		if err != nil {
+			listErr = fmt.Errorf("failed to list active users: %w", err)
+			break
+		}
+
+		for _, userID := range page {
+			select {
+			case userIDs <- userID:
+				report.TotalUsers++
+			case <-ctx.Done():
+				listErr = ctx.Err()
+			}
+			if listErr != nil {
+				break
+			}
+		}
+
+		if len(page) < insightUserPageSize {
+			break
+		}
+		offset += len(page)
+	}
+
+	close(userIDs)
+	wg.Wait()
+	report.FinishedAt = time.Now()
+
+	if listErr != nil {
+		log.Printf("insight job: stopped after %d user(s): %v", report.TotalUsers, listErr)
+		return report, listErr
+	}
+
+	return report, nil
+}
+
+// processUserInsights แยก timeout และ panic ของผู้ใช้แต่ละคนออกจากกัน
+func (a *aiInsightUsecase) processUserInsights(ctx context.Context, userID uuid.UUID) (err error) {
+	if a.userTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.userTimeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while processing insights: %v", r)
+		}
+	}()
+
+	return a.ProcessWeeklyInsights(ctx, userID)
+}
+
+func (a *aiInsightUsecase) GetSpendingAnomalies(ctx context.Context, userID uuid.UUID, months int) ([]*entity.SpendingAnomaly, error) {
+	if months <= 0 {
+		months = 6
+	}
+
+	anomalies, err := a.insightRepo.GetSpendingAnomalies(ctx, userID, months)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spending anomalies: %w", err)
+	}
+
+	return anomalies, nil
+}
+
+func (a *aiInsightUsecase) GetSpendingPatterns(ctx context.Context, userID uuid.UUID, days int) ([]*entity.SpendingPattern, error) {
+	if days <= 0 {
+		days = 30
+	}
+
+	patterns, err := a.insightRepo.GetSpendingPatterns(ctx, userID, days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spending patterns: %w", err)
+	}
+
+	return patterns, nil
 }
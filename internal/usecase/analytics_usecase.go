@@ -0,0 +1,480 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+	"savvy-backend/internal/i18n"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultTrailingPeriods = 3
+	maxTrailingPeriods     = 12
+	defaultTopMovers       = 5
+	maxTimeSeriesBuckets   = 1000
+	maxHeatmapDays         = 366 * 2
+)
+
+// ComparisonOptions กำหนดช่วงเวลาและช่วงฐานของการเปรียบเทียบ
+// BaselineRange ใช้เฉพาะเมื่อ Baseline เป็น custom
+type ComparisonOptions struct {
+	Type          entity.TransactionType
+	Current       entity.DateRange
+	Baseline      entity.ComparisonBaseline
+	BaselineRange *entity.DateRange
+	Periods       int
+	Top           int
+}
+
+type AnalyticsUsecase interface {
+	ComparePeriods(ctx context.Context, userID uuid.UUID, options ComparisonOptions) (*entity.PeriodComparison, error)
+	GetTimeSeries(ctx context.Context, query repository.TimeSeriesQuery) (*entity.TimeSeries, error)
+	GetSpendingCalendar(ctx context.Context, userID uuid.UUID, year, month int) (*entity.SpendingCalendar, error)
+	GetSpendingHeatmap(ctx context.Context, userID uuid.UUID, dateRange entity.DateRange) (*entity.SpendingHeatmap, error)
+	GetSankeyFlow(ctx context.Context, userID uuid.UUID, dateRange entity.DateRange) (*entity.SankeyFlow, error)
+}
+
+type analyticsUsecase struct {
+	analyticsRepo repository.AnalyticsRepository
+	userRepo      repository.UserRepository
+}
+
+func NewAnalyticsUsecase(analyticsRepo repository.AnalyticsRepository, userRepo repository.UserRepository) AnalyticsUsecase {
+	return &analyticsUsecase{
+		analyticsRepo: analyticsRepo,
+		userRepo:      userRepo,
+	}
+}
+
+// ComparePeriods เปรียบเทียบยอดรายหมวดหมู่ของช่วงปัจจุบันกับช่วงฐาน
+// ช่วงที่เป็นเดือนเต็มจะเลื่อนตามเดือนปฏิทิน (เช่น ก.พ. เทียบ ม.ค.) ช่วงอื่นเลื่อนตามจำนวนวัน
+func (a *analyticsUsecase) ComparePeriods(ctx context.Context, userID uuid.UUID, options ComparisonOptions) (*entity.PeriodComparison, error) {
+	if options.Current.EndDate.Before(options.Current.StartDate) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+	if options.Type == "" {
+		options.Type = entity.TransactionTypeExpense
+	}
+	if options.Baseline == "" {
+		options.Baseline = entity.ComparisonBaselinePreviousPeriod
+	}
+	if options.Top <= 0 {
+		options.Top = defaultTopMovers
+	}
+
+	periods := 1
+	var baselineRange entity.DateRange
+	switch options.Baseline {
+	case entity.ComparisonBaselinePreviousPeriod:
+		baselineRange = shiftRange(options.Current, 1)
+	case entity.ComparisonBaselinePreviousYear:
+		baselineRange = entity.DateRange{
+			StartDate: options.Current.StartDate.AddDate(-1, 0, 0),
+			EndDate:   options.Current.EndDate.AddDate(-1, 0, 0),
+		}
+		if options.Current.IsWholeMonths() {
+			baselineRange.EndDate = baselineRange.StartDate.AddDate(0, options.Current.Months(), -1)
+		}
+	case entity.ComparisonBaselineTrailingAverage:
+		periods = options.Periods
+		if periods <= 0 {
+			periods = defaultTrailingPeriods
+		}
+		if periods > maxTrailingPeriods {
+			return nil, fmt.Errorf("periods cannot exceed %d", maxTrailingPeriods)
+		}
+		// ช่วงก่อนหน้าทั้ง N ช่วงต่อกันพอดี จึงรวมยอดครั้งเดียวแล้วหารด้วย N
+		baselineRange = entity.DateRange{
+			StartDate: shiftRange(options.Current, periods).StartDate,
+			EndDate:   options.Current.StartDate.AddDate(0, 0, -1),
+		}
+	case entity.ComparisonBaselineCustom:
+		if options.BaselineRange == nil {
+			return nil, fmt.Errorf("baseline date range is required for custom comparison")
+		}
+		if options.BaselineRange.EndDate.Before(options.BaselineRange.StartDate) {
+			return nil, fmt.Errorf("baseline end date must not be before baseline start date")
+		}
+		baselineRange = *options.BaselineRange
+	default:
+		return nil, fmt.Errorf("invalid baseline, expected previous_period, previous_year, trailing_average or custom")
+	}
+
+	currentTotals, err := a.analyticsRepo.GetCategoryTotals(ctx, userID, options.Type, options.Current.StartDate, options.Current.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category totals: %w", err)
+	}
+	baselineTotals, err := a.analyticsRepo.GetCategoryTotals(ctx, userID, options.Type, baselineRange.StartDate, baselineRange.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get baseline category totals: %w", err)
+	}
+
+	comparison := &entity.PeriodComparison{
+		Type:            options.Type,
+		Baseline:        options.Baseline,
+		BaselinePeriods: periods,
+		CurrentRange:    options.Current,
+		BaselineRange:   baselineRange,
+		Categories:      make([]*entity.CategoryDelta, 0),
+		TopIncreases:    make([]*entity.CategoryDelta, 0),
+		TopDecreases:    make([]*entity.CategoryDelta, 0),
+	}
+
+	deltas := make(map[uuid.UUID]*entity.CategoryDelta)
+	deltaFor := func(total *entity.CategoryTotal) *entity.CategoryDelta {
+		delta, ok := deltas[total.CategoryID]
+		if !ok {
+			delta = &entity.CategoryDelta{CategoryID: total.CategoryID, CategoryName: total.CategoryName}
+			deltas[total.CategoryID] = delta
+			comparison.Categories = append(comparison.Categories, delta)
+		}
+		return delta
+	}
+
+	for _, total := range currentTotals {
+		deltaFor(total).Current = total.Total
+		comparison.CurrentTotal = comparison.CurrentTotal.Add(total.Total)
+	}
+	divisor := decimal.NewFromInt(int64(periods))
+	for _, total := range baselineTotals {
+		baseline := total.Total.Div(divisor).Round(2)
+		deltaFor(total).Baseline = baseline
+		comparison.BaselineTotal = comparison.BaselineTotal.Add(baseline)
+	}
+
+	for _, delta := range comparison.Categories {
+		delta.Change = delta.Current.Sub(delta.Baseline)
+		delta.ChangePercentage = percentageChange(delta.Current, delta.Baseline)
+	}
+	comparison.Change = comparison.CurrentTotal.Sub(comparison.BaselineTotal)
+	comparison.ChangePercentage = percentageChange(comparison.CurrentTotal, comparison.BaselineTotal)
+
+	// เรียงตามขนาดการเปลี่ยนแปลง (absolute) มากไปน้อย
+	sort.Slice(comparison.Categories, func(i, j int) bool {
+		return comparison.Categories[i].Change.Abs().GreaterThan(comparison.Categories[j].Change.Abs())
+	})
+	for _, delta := range comparison.Categories {
+		if delta.Change.IsPositive() && len(comparison.TopIncreases) < options.Top {
+			comparison.TopIncreases = append(comparison.TopIncreases, delta)
+		}
+		if delta.Change.IsNegative() && len(comparison.TopDecreases) < options.Top {
+			comparison.TopDecreases = append(comparison.TopDecreases, delta)
+		}
+	}
+
+	return comparison, nil
+}
+
+// GetTimeSeries รวมยอดตามช่วงเวลาและกลุ่มใน SQL แล้วเติม bucket ที่ไม่มีรายการเป็น 0 เพื่อให้ทุกเส้นมีจุดเท่ากัน
+// ถ้าไม่ได้ระบุ type และไม่ได้แบ่งกลุ่มตาม type จะรวมเฉพาะรายจ่าย เพราะรวมรายรับกับรายจ่ายเข้าด้วยกันไม่มีความหมาย
+func (a *analyticsUsecase) GetTimeSeries(ctx context.Context, query repository.TimeSeriesQuery) (*entity.TimeSeries, error) {
+	if query.Granularity == "" {
+		query.Granularity = entity.GranularityMonth
+	}
+	if !query.Granularity.IsValid() {
+		return nil, fmt.Errorf("invalid granularity, expected day, week, month, quarter or year")
+	}
+	if query.GroupBy == "" {
+		query.GroupBy = entity.GroupByNone
+	}
+	switch query.GroupBy {
+	case entity.GroupByNone, entity.GroupByCategory, entity.GroupByAccount, entity.GroupByType:
+	default:
+		return nil, fmt.Errorf("invalid group_by, expected none, category, account or type")
+	}
+
+	if query.Filter.StartDate == nil || query.Filter.EndDate == nil {
+		return nil, fmt.Errorf("start date and end date are required")
+	}
+	if query.Filter.EndDate.Before(*query.Filter.StartDate) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+	if query.Filter.Type == nil && query.GroupBy != entity.GroupByType {
+		expenseType := entity.TransactionTypeExpense
+		query.Filter.Type = &expenseType
+	}
+	query.Filter.Limit = 0
+	query.Filter.Offset = 0
+
+	var periods []time.Time
+	for period := query.Granularity.Truncate(*query.Filter.StartDate); !period.After(*query.Filter.EndDate); period = query.Granularity.Next(period) {
+		periods = append(periods, period)
+		if len(periods) > maxTimeSeriesBuckets {
+			return nil, fmt.Errorf("date range is too long for %s granularity (max %d buckets)", query.Granularity, maxTimeSeriesBuckets)
+		}
+	}
+
+	buckets, err := a.analyticsRepo.GetTimeSeries(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time series: %w", err)
+	}
+
+	series := &entity.TimeSeries{
+		Granularity: query.Granularity,
+		GroupBy:     query.GroupBy,
+		Type:        query.Filter.Type,
+		StartDate:   *query.Filter.StartDate,
+		EndDate:     *query.Filter.EndDate,
+		Buckets:     periods,
+		Groups:      make([]*entity.TimeSeriesGroup, 0),
+	}
+
+	periodIndex := make(map[string]int, len(periods))
+	for i, period := range periods {
+		periodIndex[period.Format("2006-01-02")] = i
+	}
+
+	groups := make(map[string]*entity.TimeSeriesGroup)
+	for _, bucket := range buckets {
+		group, ok := groups[bucket.GroupKey]
+		if !ok {
+			group = &entity.TimeSeriesGroup{
+				Key:    bucket.GroupKey,
+				Label:  bucket.GroupLabel,
+				Points: make([]*entity.TimeSeriesPoint, len(periods)),
+			}
+			for i, period := range periods {
+				group.Points[i] = &entity.TimeSeriesPoint{PeriodStart: period}
+			}
+			groups[bucket.GroupKey] = group
+			series.Groups = append(series.Groups, group)
+		}
+
+		i, ok := periodIndex[bucket.PeriodStart.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		group.Points[i].Total = bucket.Total
+		group.Points[i].Count = bucket.Count
+		group.Total = group.Total.Add(bucket.Total)
+	}
+
+	sort.Slice(series.Groups, func(i, j int) bool {
+		return series.Groups[i].Total.GreaterThan(series.Groups[j].Total)
+	})
+
+	return series, nil
+}
+
+// GetSpendingCalendar คืนรายจ่ายรายวันของเดือนที่เลือก ครบทุกวันของเดือนสำหรับแสดงเป็นปฏิทิน
+func (a *analyticsUsecase) GetSpendingCalendar(ctx context.Context, userID uuid.UUID, year, month int) (*entity.SpendingCalendar, error) {
+	if month < 1 || month > 12 {
+		return nil, fmt.Errorf("invalid month, expected 1-12")
+	}
+
+	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+
+	totals, err := a.analyticsRepo.GetDailyExpenseTotals(ctx, userID, monthStart, monthEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily expense totals: %w", err)
+	}
+
+	byDate := make(map[string]*entity.CalendarDay, len(totals))
+	for _, day := range totals {
+		byDate[day.Date.Format("2006-01-02")] = day
+	}
+
+	calendar := &entity.SpendingCalendar{
+		Year:  year,
+		Month: month,
+		Days:  make([]*entity.CalendarDay, 0, monthEnd.Day()),
+	}
+	for date := monthStart; !date.After(monthEnd); date = date.AddDate(0, 0, 1) {
+		day, ok := byDate[date.Format("2006-01-02")]
+		if !ok {
+			day = &entity.CalendarDay{}
+		}
+		day.Date = date
+
+		calendar.Total = calendar.Total.Add(day.Total)
+		calendar.Count += day.Count
+		if day.Total.GreaterThan(calendar.MaxDaily) {
+			calendar.MaxDaily = day.Total
+		}
+		calendar.Days = append(calendar.Days, day)
+	}
+
+	return calendar, nil
+}
+
+// GetSpendingHeatmap คืนรายจ่ายแยกตามวันในสัปดาห์ × ชั่วโมง ครบ 7 × 24 ช่อง (เรียงจันทร์ 00:00 ถึงอาทิตย์ 23:00)
+func (a *analyticsUsecase) GetSpendingHeatmap(ctx context.Context, userID uuid.UUID, dateRange entity.DateRange) (*entity.SpendingHeatmap, error) {
+	if dateRange.StartDate.IsZero() || dateRange.EndDate.IsZero() {
+		return nil, fmt.Errorf("start date and end date are required")
+	}
+	if dateRange.EndDate.Before(dateRange.StartDate) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+	if dateRange.Days() > maxHeatmapDays {
+		return nil, fmt.Errorf("date range is too long for heatmap (max %d days)", maxHeatmapDays)
+	}
+
+	rows, err := a.analyticsRepo.GetExpenseHeatmap(ctx, userID, dateRange.StartDate, dateRange.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spending heatmap: %w", err)
+	}
+
+	heatmap := &entity.SpendingHeatmap{
+		StartDate: dateRange.StartDate,
+		EndDate:   dateRange.EndDate,
+		Cells:     make([]*entity.HeatmapCell, 0, 7*24),
+	}
+	for weekday := 1; weekday <= 7; weekday++ {
+		for hour := 0; hour < 24; hour++ {
+			heatmap.Cells = append(heatmap.Cells, &entity.HeatmapCell{Weekday: weekday, Hour: hour})
+		}
+	}
+
+	for _, row := range rows {
+		heatmap.Total = heatmap.Total.Add(row.Total)
+		heatmap.Count += row.Count
+
+		if row.Hour < 0 {
+			heatmap.UntimedTotal = heatmap.UntimedTotal.Add(row.Total)
+			heatmap.UntimedCount += row.Count
+			continue
+		}
+		if row.Weekday < 1 || row.Weekday > 7 || row.Hour > 23 {
+			continue
+		}
+
+		cell := heatmap.Cells[(row.Weekday-1)*24+row.Hour]
+		cell.Total = row.Total
+		cell.Count = row.Count
+		if cell.Total.GreaterThan(heatmap.MaxCell) {
+			heatmap.MaxCell = cell.Total
+		}
+	}
+
+	if heatmap.MaxCell.IsPositive() {
+		for _, cell := range heatmap.Cells {
+			cell.Intensity, _ = cell.Total.Div(heatmap.MaxCell).Round(4).Float64()
+		}
+	}
+
+	return heatmap, nil
+}
+
+// GetSankeyFlow สร้างโหนดและลิงก์ของกราฟ Sankey จากยอดรวมบัญชี × หมวดหมู่
+// แต่ละบัญชีสมดุลเสมอ: รายรับ + ยอดที่ดึงจาก balance = รายจ่าย + ยอดที่ไหลไป savings
+func (a *analyticsUsecase) GetSankeyFlow(ctx context.Context, userID uuid.UUID, dateRange entity.DateRange) (*entity.SankeyFlow, error) {
+	if dateRange.StartDate.IsZero() || dateRange.EndDate.IsZero() {
+		return nil, fmt.Errorf("start date and end date are required")
+	}
+	if dateRange.EndDate.Before(dateRange.StartDate) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+
+	totals, err := a.analyticsRepo.GetAccountCategoryTotals(ctx, userID, dateRange.StartDate, dateRange.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account category totals: %w", err)
+	}
+
+	flow := &entity.SankeyFlow{
+		StartDate: dateRange.StartDate,
+		EndDate:   dateRange.EndDate,
+		Nodes:     make([]*entity.SankeyNode, 0),
+		Links:     make([]*entity.SankeyLink, 0),
+	}
+
+	nodes := make(map[string]*entity.SankeyNode)
+	node := func(id, label string, kind entity.SankeyNodeKind, colorHex *string) *entity.SankeyNode {
+		n, ok := nodes[id]
+		if !ok {
+			n = &entity.SankeyNode{ID: id, Label: label, Kind: kind, ColorHex: colorHex}
+			nodes[id] = n
+			flow.Nodes = append(flow.Nodes, n)
+		}
+		return n
+	}
+
+	type accountFlow struct {
+		node    *entity.SankeyNode
+		income  decimal.Decimal
+		expense decimal.Decimal
+	}
+	accounts := make(map[uuid.UUID]*accountFlow)
+	var accountOrder []uuid.UUID
+
+	// totals เรียงตามยอดจากมากไปน้อย โหนดและลิงก์จึงเรียงตามขนาดด้วย
+	for _, total := range totals {
+		if !total.Total.IsPositive() {
+			continue
+		}
+
+		account, ok := accounts[total.AccountID]
+		if !ok {
+			account = &accountFlow{node: node("account:"+total.AccountID.String(), total.AccountName, entity.SankeyNodeAccount, nil)}
+			accounts[total.AccountID] = account
+			accountOrder = append(accountOrder, total.AccountID)
+		}
+
+		switch total.Type {
+		case entity.TransactionTypeIncome:
+			category := node("income:"+total.CategoryID.String(), total.CategoryName, entity.SankeyNodeIncomeCategory, total.ColorHex)
+			category.Value = category.Value.Add(total.Total)
+			flow.Links = append(flow.Links, &entity.SankeyLink{Source: category.ID, Target: account.node.ID, Value: total.Total})
+			account.income = account.income.Add(total.Total)
+			flow.TotalIncome = flow.TotalIncome.Add(total.Total)
+		case entity.TransactionTypeExpense:
+			category := node("expense:"+total.CategoryID.String(), total.CategoryName, entity.SankeyNodeExpenseCategory, total.ColorHex)
+			category.Value = category.Value.Add(total.Total)
+			flow.Links = append(flow.Links, &entity.SankeyLink{Source: account.node.ID, Target: category.ID, Value: total.Total})
+			account.expense = account.expense.Add(total.Total)
+			flow.TotalExpense = flow.TotalExpense.Add(total.Total)
+		}
+	}
+
+	// โหนดที่ไม่ได้มาจากข้อมูลของผู้ใช้ใช้ป้ายกำกับตามภาษาของผู้ใช้
+	locale := loadAudience(ctx, a.userRepo, userID).Locale
+	for _, accountID := range accountOrder {
+		account := accounts[accountID]
+		diff := account.income.Sub(account.expense)
+
+		switch {
+		case diff.IsPositive():
+			savings := node("savings", i18n.Label(locale, "savings"), entity.SankeyNodeSavings, nil)
+			savings.Value = savings.Value.Add(diff)
+			flow.Links = append(flow.Links, &entity.SankeyLink{Source: account.node.ID, Target: savings.ID, Value: diff})
+		case diff.IsNegative():
+			balance := node("balance", i18n.Label(locale, "existing_balance"), entity.SankeyNodeBalance, nil)
+			balance.Value = balance.Value.Add(diff.Neg())
+			flow.Links = append(flow.Links, &entity.SankeyLink{Source: balance.ID, Target: account.node.ID, Value: diff.Neg()})
+		}
+
+		account.node.Value = decimal.Max(account.income, account.expense)
+	}
+
+	flow.NetSavings = flow.TotalIncome.Sub(flow.TotalExpense)
+
+	return flow, nil
+}
+
+// shiftRange เลื่อนช่วงย้อนหลัง n ช่วง (ตามเดือนถ้าเป็นเดือนเต็ม ไม่เช่นนั้นตามจำนวนวัน)
+func shiftRange(r entity.DateRange, n int) entity.DateRange {
+	if r.IsWholeMonths() {
+		months := r.Months()
+		start := r.StartDate.AddDate(0, -months*n, 0)
+		return entity.DateRange{StartDate: start, EndDate: start.AddDate(0, months, -1)}
+	}
+
+	days := r.Days()
+	start := r.StartDate.AddDate(0, 0, -days*n)
+	return entity.DateRange{StartDate: start, EndDate: start.AddDate(0, 0, days-1)}
+}
+
+func percentageChange(current, baseline decimal.Decimal) *float64 {
+	if baseline.IsZero() {
+		return nil
+	}
+	percentage, _ := current.Sub(baseline).Div(baseline.Abs()).Mul(decimal.NewFromInt(100)).Round(2).Float64()
+	return &percentage
+}
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestShiftRange(t *testing.T) {
+	dateRange := func(startYear int, startMonth time.Month, startDay, endYear int, endMonth time.Month, endDay int) entity.DateRange {
+		return entity.DateRange{StartDate: testDate(startYear, startMonth, startDay), EndDate: testDate(endYear, endMonth, endDay)}
+	}
+
+	tests := []struct {
+		name  string
+		input entity.DateRange
+		n     int
+		want  entity.DateRange
+	}{
+		{name: "previous month", input: dateRange(2026, time.October, 1, 2026, time.October, 31), n: 1, want: dateRange(2026, time.September, 1, 2026, time.September, 30)},
+		{name: "into february", input: dateRange(2026, time.March, 1, 2026, time.March, 31), n: 1, want: dateRange(2026, time.February, 1, 2026, time.February, 28)},
+		{name: "into leap february", input: dateRange(2028, time.March, 1, 2028, time.March, 31), n: 1, want: dateRange(2028, time.February, 1, 2028, time.February, 29)},
+		{name: "two months back", input: dateRange(2026, time.March, 1, 2026, time.March, 31), n: 2, want: dateRange(2026, time.January, 1, 2026, time.January, 31)},
+		{name: "same month last year", input: dateRange(2026, time.October, 1, 2026, time.October, 31), n: 12, want: dateRange(2025, time.October, 1, 2025, time.October, 31)},
+		{name: "previous quarter", input: dateRange(2026, time.July, 1, 2026, time.September, 30), n: 1, want: dateRange(2026, time.April, 1, 2026, time.June, 30)},
+		{name: "whole months across year", input: dateRange(2025, time.December, 1, 2026, time.February, 28), n: 1, want: dateRange(2025, time.September, 1, 2025, time.November, 30)},
+		{name: "previous week", input: dateRange(2026, time.October, 10, 2026, time.October, 16), n: 1, want: dateRange(2026, time.October, 3, 2026, time.October, 9)},
+		{name: "two weeks back across month", input: dateRange(2026, time.October, 10, 2026, time.October, 16), n: 2, want: dateRange(2026, time.September, 26, 2026, time.October, 2)},
+		{name: "partial month shifts by days", input: dateRange(2026, time.October, 15, 2026, time.October, 31), n: 1, want: dateRange(2026, time.September, 28, 2026, time.October, 14)},
+		{name: "single day", input: dateRange(2026, time.January, 1, 2026, time.January, 1), n: 1, want: dateRange(2025, time.December, 31, 2025, time.December, 31)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shiftRange(tt.input, tt.n)
+			if !got.StartDate.Equal(tt.want.StartDate) || !got.EndDate.Equal(tt.want.EndDate) {
+				t.Fatalf("shiftRange() = %s - %s, want %s - %s",
+					got.StartDate.Format("2006-01-02"), got.EndDate.Format("2006-01-02"),
+					tt.want.StartDate.Format("2006-01-02"), tt.want.EndDate.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestPercentageChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		baseline string
+		want     *float64
+	}{
+		{name: "zero baseline", current: "100", baseline: "0", want: nil},
+		{name: "increase", current: "150", baseline: "100", want: floatPtr(50)},
+		{name: "decrease", current: "50", baseline: "100", want: floatPtr(-50)},
+		{name: "unchanged", current: "100", baseline: "100", want: floatPtr(0)},
+		{name: "dropped to zero", current: "0", baseline: "200", want: floatPtr(-100)},
+		{name: "rounded to two decimals", current: "1", baseline: "3", want: floatPtr(-66.67)},
+		{name: "negative baseline uses its magnitude", current: "-50", baseline: "-100", want: floatPtr(50)},
+		{name: "negative to positive", current: "100", baseline: "-100", want: floatPtr(200)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := percentageChange(decimal.RequireFromString(tt.current), decimal.RequireFromString(tt.baseline))
+			switch {
+			case tt.want == nil && got != nil:
+				t.Fatalf("percentageChange() = %v, want nil", *got)
+			case tt.want != nil && got == nil:
+				t.Fatalf("percentageChange() = nil, want %v", *tt.want)
+			case tt.want != nil && *got != *tt.want:
+				t.Fatalf("percentageChange() = %v, want %v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
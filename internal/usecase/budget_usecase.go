@@ -3,10 +3,13 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"log"
 	"time"
 
 	"savvy-backend/internal/domain/entity"
 	"savvy-backend/internal/domain/repository"
+	"savvy-backend/internal/domain/service"
+	"savvy-backend/internal/i18n"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -24,20 +27,32 @@ type BudgetUsecase interface {
 }
 
 type budgetUsecase struct {
-	budgetRepo   repository.BudgetRepository
-	categoryRepo repository.CategoryRepository
-	insightRepo  repository.InsightRepository
+	budgetRepo    repository.BudgetRepository
+	categoryRepo  repository.CategoryRepository
+	insightRepo   repository.InsightRepository
+	recurringRepo repository.RecurringTransactionRepository
+	userRepo      repository.UserRepository
+	narrator      service.InsightNarrator
+	responseCache service.ResponseCache
 }
 
 func NewBudgetUsecase(
 	budgetRepo repository.BudgetRepository,
 	categoryRepo repository.CategoryRepository,
 	insightRepo repository.InsightRepository,
+	recurringRepo repository.RecurringTransactionRepository,
+	userRepo repository.UserRepository,
+	narrator service.InsightNarrator,
+	responseCache service.ResponseCache,
 ) BudgetUsecase {
 	return &budgetUsecase{
-		budgetRepo:   budgetRepo,
-		categoryRepo: categoryRepo,
-		insightRepo:  insightRepo,
+		budgetRepo:    budgetRepo,
+		categoryRepo:  categoryRepo,
+		insightRepo:   insightRepo,
+		recurringRepo: recurringRepo,
+		userRepo:      userRepo,
+		narrator:      narrator,
+		responseCache: responseCache,
 	}
 }
 
@@ -51,6 +66,9 @@ func (b *budgetUsecase) CreateBudget(ctx context.Context, userID, categoryID uui
 	if category.UserID != nil && *category.UserID != userID {
 		return nil, fmt.Errorf("category does not belong to user")
 	}
+	if category.IsArchived {
+		return nil, fmt.Errorf("category is archived")
+	}
 
 	// Check if budget already exists for this category
 	existing, _ := b.budgetRepo.GetByUserIDAndCategoryID(ctx, userID, categoryID)
@@ -65,6 +83,7 @@ func (b *budgetUsecase) CreateBudget(ctx context.Context, userID, categoryID uui
 	if err != nil {
 		return nil, fmt.Errorf("failed to create budget: %w", err)
 	}
+	invalidateUserCache(ctx, b.responseCache, userID)
 
 	return budget, nil
 }
@@ -103,7 +122,12 @@ func (b *budgetUsecase) UpdateBudget(ctx context.Context, userID uuid.UUID, budg
 	}
 
 	budget.UserID = userID // Ensure user ID is preserved
-	return b.budgetRepo.Update(ctx, budget)
+	if err := b.budgetRepo.Update(ctx, budget); err != nil {
+		return err
+	}
+
+	invalidateUserCache(ctx, b.responseCache, userID)
+	return nil
 }
 
 func (b *budgetUsecase) DeleteBudget(ctx context.Context, userID, budgetID uuid.UUID) error {
@@ -116,16 +140,86 @@ func (b *budgetUsecase) DeleteBudget(ctx context.Context, userID, budgetID uuid.
 		return fmt.Errorf("budget does not belong to user")
 	}
 
-	return b.budgetRepo.Delete(ctx, budgetID)
+	if err := b.budgetRepo.Delete(ctx, budgetID); err != nil {
+		return err
+	}
+
+	invalidateUserCache(ctx, b.responseCache, userID)
+	return nil
 }
 
 func (b *budgetUsecase) GetBudgetProgress(ctx context.Context, userID uuid.UUID, year, month int) ([]*entity.BudgetProgress, error) {
-	return b.budgetRepo.GetBudgetProgress(ctx, userID, year, month)
+	progresses, err := b.budgetRepo.GetBudgetProgress(ctx, userID, year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.applyRecurringProjection(ctx, userID, year, month, progresses); err != nil {
+		return nil, err
+	}
+
+	return progresses, nil
 }
 
 func (b *budgetUsecase) GetCurrentMonthBudgetProgress(ctx context.Context, userID uuid.UUID) ([]*entity.BudgetProgress, error) {
 	now := time.Now()
-	return b.budgetRepo.GetBudgetProgress(ctx, userID, now.Year(), int(now.Month()))
+	return b.GetBudgetProgress(ctx, userID, now.Year(), int(now.Month()))
+}
+
+// applyRecurringProjection บวกยอดรายจ่ายประจำที่จะเกิดขึ้นจนถึงสิ้นเดือนเข้าไปในยอดที่คาดว่าจะใช้
+// รายการที่ยอดไม่คงที่ใช้ยอดประมาณ (ค่าเฉลี่ยหรือกึ่งกลางช่วง) และนับรวมรายการของหมวดหมู่ย่อย
+func (b *budgetUsecase) applyRecurringProjection(ctx context.Context, userID uuid.UUID, year, month int, progresses []*entity.BudgetProgress) error {
+	if len(progresses) == 0 {
+		return nil
+	}
+
+	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	monthEnd := monthStart.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	if monthEnd.Before(time.Now()) {
+		return nil // เดือนที่ผ่านไปแล้วไม่มีรายการที่ต้องคาดการณ์
+	}
+
+	isActive := true
+	expenseType := entity.TransactionTypeExpense
+	rules, err := b.recurringRepo.GetByFilter(ctx, repository.RecurringTransactionFilter{
+		UserID:   userID,
+		Type:     &expenseType,
+		IsActive: &isActive,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get recurring transactions: %w", err)
+	}
+
+	upcoming := make(map[uuid.UUID]decimal.Decimal)
+	for _, rule := range rules {
+		// Occurrences start at NextExecutionDate, so anything in range has not been spent yet
+		dates := rule.OccurrencesBetween(monthStart, monthEnd)
+		if len(dates) == 0 {
+			continue
+		}
+
+		estimate, err := estimateRecurringAmount(ctx, b.recurringRepo, rule)
+		if err != nil {
+			return err
+		}
+		upcoming[rule.CategoryID] = upcoming[rule.CategoryID].Add(estimate.Amount.Mul(decimal.NewFromInt(int64(len(dates)))))
+	}
+
+	for _, progress := range progresses {
+		// งบของหมวดหมู่หลักรวมรายการประจำของหมวดหมู่ย่อยด้วย เหมือนยอดที่ใช้ไปแล้ว
+		descendantIDs, err := b.categoryRepo.GetDescendantIDs(ctx, progress.CategoryID)
+		if err != nil {
+			return fmt.Errorf("failed to get sub-categories: %w", err)
+		}
+		progress.UpcomingRecurringAmount = upcoming[progress.CategoryID]
+		for _, descendantID := range descendantIDs {
+			progress.UpcomingRecurringAmount = progress.UpcomingRecurringAmount.Add(upcoming[descendantID])
+		}
+		progress.ProjectedAmount = progress.SpentAmount.Add(progress.UpcomingRecurringAmount)
+		progress.IsProjectedOverBudget = progress.ProjectedAmount.GreaterThan(progress.BudgetAmount)
+	}
+
+	return nil
 }
 
 func (b *budgetUsecase) CheckBudgetAlerts(ctx context.Context, userID uuid.UUID) ([]*entity.Insight, error) {
@@ -135,26 +229,24 @@ func (b *budgetUsecase) CheckBudgetAlerts(ctx context.Context, userID uuid.UUID)
 		return nil, err
 	}
 
+	audience := loadAudience(ctx, b.userRepo, userID)
 	var insights []*entity.Insight
 
 	for _, progress := range progresses {
 		// Create alert for budgets at 80% usage
 		if progress.ProgressPercentage >= 80 && progress.ProgressPercentage < 100 {
-			title := fmt.Sprintf("งบประมาณ %s ใกล้หมดแล้ว", progress.CategoryName)
-
-			spentAmount, _ := progress.SpentAmount.Float64()
-			budgetAmount, _ := progress.BudgetAmount.Float64()
+			narration, err := b.narrator.NarrateBudgetAlert(ctx, audience, progress)
+			if err != nil {
+				log.Printf("insight narrator failed for user %s: %v", userID, err)
+				narration = narrate(audience, i18n.BudgetAlertMessage(progress))
+			}
 
-			message := fmt.Sprintf("คุณใช้งบประมาณหมวดหมู่ %s ไปแล้ว %.1f%% (%.2f จาก %.2f บาท)",
-				progress.CategoryName,
-				progress.ProgressPercentage,
-				spentAmount,
-				budgetAmount)
-
-			insight := entity.NewAdvancedInsight(userID, entity.InsightTypeBudgetAlert, entity.InsightPriorityMedium, title, message)
+			insight := newNarratedInsight(userID, entity.InsightTypeBudgetAlert, entity.InsightPriorityMedium, audience, narration)
 			insight.RelatedEntityID = &progress.BudgetID
 			insight.RelatedEntityType = &[]string{"budget"}[0]
 
+			insight.SetFingerprint(now.Format("2006-01") + ":near")
+
 			validUntil := time.Now().AddDate(0, 0, 7) // Valid for 7 days
 			insight.ValidUntil = &validUntil
 
@@ -163,19 +255,18 @@ func (b *budgetUsecase) CheckBudgetAlerts(ctx context.Context, userID uuid.UUID)
 
 		// Create alert for over-budget categories
 		if progress.IsOverBudget {
-			title := fmt.Sprintf("เกินงบประมาณ %s", progress.CategoryName)
-
-			overAmount, _ := progress.SpentAmount.Sub(progress.BudgetAmount).Float64()
+			narration, err := b.narrator.NarrateBudgetAlert(ctx, audience, progress)
+			if err != nil {
+				log.Printf("insight narrator failed for user %s: %v", userID, err)
+				narration = narrate(audience, i18n.BudgetAlertMessage(progress))
+			}
 
-			message := fmt.Sprintf("คุณใช้จ่ายหมวดหมู่ %s เกินงบประมาณแล้ว %.2f บาท (%.1f%%)",
-				progress.CategoryName,
-				overAmount,
-				progress.ProgressPercentage)
-
-			insight := entity.NewAdvancedInsight(userID, entity.InsightTypeBudgetAlert, entity.InsightPriorityHigh, title, message)
+			insight := newNarratedInsight(userID, entity.InsightTypeBudgetAlert, entity.InsightPriorityHigh, audience, narration)
 			insight.RelatedEntityID = &progress.BudgetID
 			insight.RelatedEntityType = &[]string{"budget"}[0]
 
+			insight.SetFingerprint(now.Format("2006-01") + ":over")
+
 			validUntil := time.Now().AddDate(0, 0, 3) // Valid for 3 days
 			insight.ValidUntil = &validUntil
 
@@ -183,13 +274,9 @@ func (b *budgetUsecase) CheckBudgetAlerts(ctx context.Context, userID uuid.UUID)
 		}
 	}
 
-	// Save insights to database
-	for _, insight := range insights {
-		err := b.insightRepo.Create(ctx, insight)
-		if err != nil {
-			// Log error but don't fail the entire operation
-			continue
-		}
+	// Save insights to database (budgets back under the threshold get resolved)
+	if err := saveInsights(ctx, b.insightRepo, userID, []entity.InsightType{entity.InsightTypeBudgetAlert}, insights); err != nil {
+		return nil, err
 	}
 
 	return insights, nil
@@ -3,44 +3,87 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"savvy-backend/internal/domain/entity"
 	"savvy-backend/internal/domain/repository"
+	"savvy-backend/internal/domain/service"
 
 	"github.com/google/uuid"
 )
 
+// ErrCategoryInUse คือ error เมื่อลบหมวดหมู่ที่ยังมีรายการ รายการประจำ หรืองบอ้างถึงอยู่
+var ErrCategoryInUse = repository.ErrCategoryInUse
+
 type CategoryUsecase interface {
-	CreateCategory(ctx context.Context, userID uuid.UUID, name string, categoryType entity.CategoryType, iconName, colorHex *string) (*entity.Category, error)
+	CreateCategory(ctx context.Context, userID uuid.UUID, name string, categoryType entity.CategoryType, iconName, colorHex *string, parentID *uuid.UUID) (*entity.Category, error)
+	GetCategory(ctx context.Context, userID, categoryID uuid.UUID) (*entity.Category, error)
 	GetCategoriesByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Category, error)
 	GetSystemCategories(ctx context.Context) ([]*entity.Category, error)
 	GetAllAvailableCategories(ctx context.Context, userID uuid.UUID) ([]*entity.Category, error)
 	GetUserCategories(ctx context.Context, userID uuid.UUID) ([]*entity.Category, error)
+	GetCategoryTree(ctx context.Context, userID uuid.UUID) ([]*entity.CategoryNode, error)
 	GetCategoryUsageStats(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]int64, error)
+	GetCategoryUsage(ctx context.Context, userID uuid.UUID) ([]*entity.CategoryUsage, error)
 	UpdateCategory(ctx context.Context, userID uuid.UUID, category *entity.Category) error
 	ArchiveCategory(ctx context.Context, userID, categoryID uuid.UUID) error
+	UnarchiveCategory(ctx context.Context, userID, categoryID uuid.UUID) error
+	DeleteCategory(ctx context.Context, userID, categoryID uuid.UUID, reassignTo *uuid.UUID) (*entity.CategoryMergeResult, error)
+	MergeCategory(ctx context.Context, userID, sourceID, targetID uuid.UUID) (*entity.CategoryMergeResult, error)
 	InitializeDefaultCategories(ctx context.Context) error
+	GetCategoryOverrides(ctx context.Context, userID uuid.UUID) ([]*entity.CategoryOverride, error)
+	SetCategoryOverride(ctx context.Context, userID, categoryID uuid.UUID, name, iconName, colorHex *string, isHidden bool) (*entity.CategoryOverride, error)
+	ResetCategoryOverride(ctx context.Context, userID, categoryID uuid.UUID) error
 }
 
 type categoryUsecase struct {
-	categoryRepo repository.CategoryRepository
+	categoryRepo  repository.CategoryRepository
+	responseCache service.ResponseCache
 }
 
-func NewCategoryUsecase(categoryRepo repository.CategoryRepository) CategoryUsecase {
+func NewCategoryUsecase(categoryRepo repository.CategoryRepository, responseCache service.ResponseCache) CategoryUsecase {
 	return &categoryUsecase{
-		categoryRepo: categoryRepo,
+		categoryRepo:  categoryRepo,
+		responseCache: responseCache,
 	}
 }
 
-func (c *categoryUsecase) CreateCategory(ctx context.Context, userID uuid.UUID, name string, categoryType entity.CategoryType, iconName, colorHex *string) (*entity.Category, error) {
+func (c *categoryUsecase) CreateCategory(ctx context.Context, userID uuid.UUID, name string, categoryType entity.CategoryType, iconName, colorHex *string, parentID *uuid.UUID) (*entity.Category, error) {
 	category := entity.NewCategory(&userID, name, categoryType)
 	category.IconName = iconName
 	category.ColorHex = colorHex
+	category.ParentID = parentID
+
+	if err := c.validateParent(ctx, userID, category); err != nil {
+		return nil, err
+	}
 
 	err := c.categoryRepo.Create(ctx, category)
 	if err != nil {
 		return nil, err
 	}
+	invalidateUserCache(ctx, c.responseCache, userID)
+
+	return category, nil
+}
+
+// GetCategory คืนหมวดหมู่ของระบบหรือของผู้ใช้ รวมหมวดหมู่ที่ archive แล้ว
+func (c *categoryUsecase) GetCategory(ctx context.Context, userID, categoryID uuid.UUID) (*entity.Category, error) {
+	category, err := c.categoryRepo.GetByID(ctx, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("category not found: %w", err)
+	}
+	if category.UserID != nil && *category.UserID != userID {
+		return nil, errors.New("category not found")
+	}
+
+	if category.UserID == nil {
+		override, err := c.categoryRepo.GetOverride(ctx, userID, categoryID)
+		if err == nil {
+			category = override.Apply(category)
+		}
+	}
 
 	return category, nil
 }
@@ -65,18 +108,217 @@ func (c *categoryUsecase) GetAllAvailableCategories(ctx context.Context, userID
 		return nil, err
 	}
 
+	overrides, err := c.categoryRepo.GetOverrides(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Combine both lists
-	allCategories := append(systemCategories, userCategories...)
-	return allCategories, nil
+	allCategories := append(applyCategoryOverrides(systemCategories, overrides), userCategories...)
+	return withoutHiddenCategories(allCategories), nil
+}
+
+// loadCategoryNames โหลดชื่อหมวดหมู่ทั้งหมดที่ผู้ใช้เห็น (ระบบพร้อม override และของผู้ใช้ รวมที่ archive แล้ว)
+// ครั้งเดียว แทนการดึงทีละหมวดหมู่ (GetByID) ของแต่ละแถว
+func loadCategoryNames(ctx context.Context, categoryRepo repository.CategoryRepository, userID uuid.UUID) (map[uuid.UUID]string, error) {
+	isSystem := true
+	systemCategories, err := categoryRepo.GetByFilter(ctx, repository.CategoryFilter{IsSystem: &isSystem})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system categories: %w", err)
+	}
+
+	userCategories, err := categoryRepo.GetByFilter(ctx, repository.CategoryFilter{UserID: &userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user categories: %w", err)
+	}
+
+	overrides, err := categoryRepo.GetOverrides(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category overrides: %w", err)
+	}
+
+	names := make(map[uuid.UUID]string, len(systemCategories)+len(userCategories))
+	for _, category := range append(applyCategoryOverrides(systemCategories, overrides), userCategories...) {
+		names[category.ID] = category.Name
+	}
+	return names, nil
+}
+
+// applyCategoryOverrides ใส่ค่าจาก override ของผู้ใช้ให้หมวดหมู่ระบบ
+func applyCategoryOverrides(categories []*entity.Category, overrides []*entity.CategoryOverride) []*entity.Category {
+	if len(overrides) == 0 {
+		return categories
+	}
+
+	byCategory := make(map[uuid.UUID]*entity.CategoryOverride, len(overrides))
+	for _, override := range overrides {
+		byCategory[override.CategoryID] = override
+	}
+
+	applied := make([]*entity.Category, 0, len(categories))
+	for _, category := range categories {
+		if override, ok := byCategory[category.ID]; ok {
+			category = override.Apply(category)
+		}
+		applied = append(applied, category)
+	}
+	return applied
+}
+
+// withoutHiddenCategories ตัดหมวดหมู่ที่ผู้ใช้ซ่อนออก รวมหมวดหมู่ย่อยทุกระดับของหมวดหมู่ที่ซ่อน
+func withoutHiddenCategories(categories []*entity.Category) []*entity.Category {
+	byID := make(map[uuid.UUID]*entity.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	isHidden := func(category *entity.Category) bool {
+		// จำกัดจำนวนรอบตามจำนวนหมวดหมู่ กันวงวนของ parent_id
+		for i := 0; category != nil && i <= len(categories); i++ {
+			if category.IsHidden {
+				return true
+			}
+			if category.ParentID == nil {
+				return false
+			}
+			category = byID[*category.ParentID]
+		}
+		return false
+	}
+
+	visible := make([]*entity.Category, 0, len(categories))
+	for _, category := range categories {
+		if !isHidden(category) {
+			visible = append(visible, category)
+		}
+	}
+	return visible
 }
 
 func (c *categoryUsecase) UpdateCategory(ctx context.Context, userID uuid.UUID, category *entity.Category) error {
-	// Verify ownership (only user categories can be updated)
+	// Verify ownership against the stored category (only user categories can be updated)
+	existing, err := c.categoryRepo.GetByID(ctx, category.ID)
+	if err != nil {
+		return fmt.Errorf("category not found: %w", err)
+	}
+	if existing.UserID == nil || *existing.UserID != userID {
+		return errors.New("category does not belong to user or is a system category")
+	}
+	// รายการเดิมผูกกับประเภทของหมวดหมู่ จึงเปลี่ยนประเภทไม่ได้
+	if category.Type != existing.Type {
+		return errors.New("category type cannot be changed")
+	}
+	category.UserID = existing.UserID
+
+	if err := c.validateParent(ctx, userID, category); err != nil {
+		return err
+	}
+
+	if err := c.categoryRepo.Update(ctx, category); err != nil {
+		return err
+	}
+
+	invalidateUserCache(ctx, c.responseCache, userID)
+	return nil
+}
+
+func (c *categoryUsecase) UnarchiveCategory(ctx context.Context, userID, categoryID uuid.UUID) error {
+	category, err := c.categoryRepo.GetByID(ctx, categoryID)
+	if err != nil {
+		return fmt.Errorf("category not found: %w", err)
+	}
+
 	if category.UserID == nil || *category.UserID != userID {
 		return errors.New("category does not belong to user or is a system category")
 	}
 
-	return c.categoryRepo.Update(ctx, category)
+	if err := c.categoryRepo.Unarchive(ctx, categoryID); err != nil {
+		return err
+	}
+
+	invalidateUserCache(ctx, c.responseCache, userID)
+	return nil
+}
+
+// DeleteCategory ลบหมวดหมู่ของผู้ใช้ ถ้ายังมีข้อมูลอ้างถึงจะปฏิเสธด้วย ErrCategoryInUse
+// เว้นแต่ระบุ reassignTo ซึ่งจะรวมหมวดหมู่เข้ากับ reassignTo ก่อนแล้วจึงลบ (หมวดหมู่ย่อยย้ายไปอยู่ใต้ reassignTo)
+// ถ้าไม่ระบุ reassignTo หมวดหมู่ย่อยจะกลายเป็นหมวดหมู่หลัก
+func (c *categoryUsecase) DeleteCategory(ctx context.Context, userID, categoryID uuid.UUID, reassignTo *uuid.UUID) (*entity.CategoryMergeResult, error) {
+	category, err := c.categoryRepo.GetByID(ctx, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("category not found: %w", err)
+	}
+
+	if category.UserID == nil || *category.UserID != userID {
+		return nil, errors.New("category does not belong to user or is a system category")
+	}
+
+	// การรวมและการลบต้องสำเร็จพร้อมกัน ไม่เช่นนั้นหมวดหมู่จะค้างอยู่ในสถานะ archive โดยไม่ถูกลบ
+	if reassignTo != nil {
+		if err := c.validateMerge(ctx, userID, categoryID, *reassignTo); err != nil {
+			return nil, err
+		}
+
+		result, err := c.categoryRepo.MergeAndDelete(ctx, userID, categoryID, *reassignTo)
+		if err != nil {
+			return nil, err
+		}
+
+		invalidateUserCache(ctx, c.responseCache, userID)
+		return result, nil
+	}
+
+	usage, err := c.categoryRepo.GetUsageByID(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if usage.InUse() {
+		return nil, fmt.Errorf("%w: %d transaction(s), %d recurring transaction(s), %d budget(s)",
+			ErrCategoryInUse, usage.TransactionCount, usage.RecurringCount, usage.BudgetCount)
+	}
+
+	if err := c.categoryRepo.Delete(ctx, categoryID); err != nil {
+		return nil, err
+	}
+
+	invalidateUserCache(ctx, c.responseCache, userID)
+	return nil, nil
+}
+
+// validateParent ตรวจว่า parent ของหมวดหมู่เป็นหมวดหมู่ของระบบหรือของผู้ใช้ ประเภทเดียวกัน และไม่ทำให้เกิดวงวน
+func (c *categoryUsecase) validateParent(ctx context.Context, userID uuid.UUID, category *entity.Category) error {
+	if category.ParentID == nil {
+		return nil
+	}
+	if *category.ParentID == category.ID {
+		return errors.New("category cannot be its own parent")
+	}
+
+	parent, err := c.categoryRepo.GetByID(ctx, *category.ParentID)
+	if err != nil {
+		return fmt.Errorf("parent category not found: %w", err)
+	}
+	if parent.UserID != nil && *parent.UserID != userID {
+		return errors.New("parent category not found")
+	}
+	if parent.IsArchived {
+		return errors.New("parent category is archived")
+	}
+	if parent.Type != category.Type {
+		return errors.New("parent category must have the same type")
+	}
+
+	descendantIDs, err := c.categoryRepo.GetDescendantIDs(ctx, category.ID)
+	if err != nil {
+		return err
+	}
+	for _, descendantID := range descendantIDs {
+		if descendantID == parent.ID {
+			return errors.New("parent category cannot be one of its sub-categories")
+		}
+	}
+
+	return nil
 }
 
 func (c *categoryUsecase) ArchiveCategory(ctx context.Context, userID, categoryID uuid.UUID) error {
@@ -89,63 +331,163 @@ func (c *categoryUsecase) ArchiveCategory(ctx context.Context, userID, categoryI
 		return errors.New("category does not belong to user or is a system category")
 	}
 
-	return c.categoryRepo.Archive(ctx, categoryID)
+	if err := c.categoryRepo.Archive(ctx, categoryID); err != nil {
+		return err
+	}
+
+	invalidateUserCache(ctx, c.responseCache, userID)
+	return nil
+}
+
+// MergeCategory รวมหมวดหมู่ของผู้ใช้ sourceID เข้ากับ targetID (หมวดหมู่ของระบบหรือของผู้ใช้ ประเภทเดียวกัน)
+// แล้ว archive sourceID
+func (c *categoryUsecase) MergeCategory(ctx context.Context, userID, sourceID, targetID uuid.UUID) (*entity.CategoryMergeResult, error) {
+	if err := c.validateMerge(ctx, userID, sourceID, targetID); err != nil {
+		return nil, err
+	}
+
+	result, err := c.categoryRepo.Merge(ctx, userID, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	invalidateUserCache(ctx, c.responseCache, userID)
+	return result, nil
+}
+
+// validateMerge ตรวจว่ารวม sourceID เข้า targetID ได้: ต้นทางเป็นของผู้ใช้ ปลายทางยังไม่ archive ประเภทเดียวกัน และไม่เกิดวงวน
+func (c *categoryUsecase) validateMerge(ctx context.Context, userID, sourceID, targetID uuid.UUID) error {
+	if sourceID == targetID {
+		return errors.New("cannot merge a category into itself")
+	}
+
+	source, err := c.categoryRepo.GetByID(ctx, sourceID)
+	if err != nil {
+		return fmt.Errorf("category not found: %w", err)
+	}
+	if source.UserID == nil || *source.UserID != userID {
+		return errors.New("category does not belong to user or is a system category")
+	}
+
+	target, err := c.categoryRepo.GetByID(ctx, targetID)
+	if err != nil {
+		return fmt.Errorf("target category not found: %w", err)
+	}
+	if target.UserID != nil && *target.UserID != userID {
+		return errors.New("target category not found")
+	}
+	if target.IsArchived {
+		return errors.New("target category is archived")
+	}
+	if target.Type != source.Type {
+		return errors.New("target category must have the same type")
+	}
+
+	// หมวดหมู่ย่อยของต้นทางจะย้ายไปอยู่ใต้ปลายทาง ถ้าปลายทางเป็นหมวดหมู่ย่อยของต้นทางจะเกิดวงวน
+	descendantIDs, err := c.categoryRepo.GetDescendantIDs(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	for _, descendantID := range descendantIDs {
+		if descendantID == targetID {
+			return errors.New("cannot merge a category into one of its sub-categories")
+		}
+	}
+
+	return nil
+}
+
+type defaultCategory struct {
+	name     string
+	iconName string
+	colorHex string
+	children []defaultCategory
 }
 
 func (c *categoryUsecase) InitializeDefaultCategories(ctx context.Context) error {
-	// Default expense categories
-	expenseCategories := []struct {
-		name     string
-		iconName string
-		colorHex string
-	}{
-		{"อาหาร", "🍽️", "#FF6B6B"},
-		{"เดินทาง", "🚗", "#4ECDC4"},
-		{"ช้อปปิ้ง", "🛍️", "#45B7D1"},
-		{"บันเทิง", "🎬", "#96CEB4"},
-		{"สุขภาพ", "🏥", "#FECA57"},
-		{"การศึกษา", "📚", "#FF9FF3"},
-		{"บิล/ค่าใช้จ่าย", "💡", "#54A0FF"},
-		{"อื่นๆ", "📝", "#5F27CD"},
+	// Default expense categories (หมวดหมู่ย่อยใช้สีเดียวกับหมวดหมู่หลัก)
+	expenseCategories := []defaultCategory{
+		{"อาหาร", "🍽️", "#FF6B6B", []defaultCategory{
+			{name: "กาแฟ/เครื่องดื่ม", iconName: "☕"},
+			{name: "ซื้อของเข้าบ้าน", iconName: "🛒"},
+			{name: "ร้านอาหาร", iconName: "🍜"},
+		}},
+		{"เดินทาง", "🚗", "#4ECDC4", []defaultCategory{
+			{name: "น้ำมัน", iconName: "⛽"},
+			{name: "ขนส่งสาธารณะ", iconName: "🚇"},
+			{name: "แท็กซี่/เรียกรถ", iconName: "🚕"},
+		}},
+		{"ช้อปปิ้ง", "🛍️", "#45B7D1", []defaultCategory{
+			{name: "เสื้อผ้า", iconName: "👕"},
+			{name: "อุปกรณ์อิเล็กทรอนิกส์", iconName: "💻"},
+			{name: "ของใช้ในบ้าน", iconName: "🏠"},
+		}},
+		{"บันเทิง", "🎬", "#96CEB4", []defaultCategory{
+			{name: "ภาพยนตร์/คอนเสิร์ต", iconName: "🎟️"},
+			{name: "สตรีมมิ่ง", iconName: "📺"},
+			{name: "ท่องเที่ยว", iconName: "✈️"},
+		}},
+		{"สุขภาพ", "🏥", "#FECA57", []defaultCategory{
+			{name: "ค่ารักษาพยาบาล", iconName: "🩺"},
+			{name: "ยา", iconName: "💊"},
+			{name: "ฟิตเนส", iconName: "🏋️"},
+		}},
+		{"การศึกษา", "📚", "#FF9FF3", []defaultCategory{
+			{name: "ค่าเล่าเรียน", iconName: "🎓"},
+			{name: "หนังสือ", iconName: "📖"},
+			{name: "คอร์สออนไลน์", iconName: "🧑‍💻"},
+		}},
+		{"บิล/ค่าใช้จ่าย", "💡", "#54A0FF", []defaultCategory{
+			{name: "ค่าไฟ", iconName: "🔌"},
+			{name: "ค่าน้ำ", iconName: "🚰"},
+			{name: "อินเทอร์เน็ต/โทรศัพท์", iconName: "📶"},
+			{name: "ค่าเช่า", iconName: "🏘️"},
+		}},
+		{"อื่นๆ", "📝", "#5F27CD", nil},
 	}
 
 	// Default income categories
-	incomeCategories := []struct {
-		name     string
-		iconName string
-		colorHex string
-	}{
-		{"เงินเดือน", "💰", "#00D2D3"},
-		{"โบนัส", "🎁", "#FF9F43"},
-		{"ลงทุน", "📈", "#5f27cd"},
-		{"ธุรกิจ", "🏢", "#00d2d3"},
-		{"อื่นๆ", "💵", "#2ed573"},
+	incomeCategories := []defaultCategory{
+		{"เงินเดือน", "💰", "#00D2D3", nil},
+		{"โบนัส", "🎁", "#FF9F43", nil},
+		{"ลงทุน", "📈", "#5f27cd", []defaultCategory{
+			{name: "เงินปันผล", iconName: "💹"},
+			{name: "ดอกเบี้ย", iconName: "🏦"},
+		}},
+		{"ธุรกิจ", "🏢", "#00d2d3", nil},
+		{"อื่นๆ", "💵", "#2ed573", nil},
 	}
 
 	// Create expense categories
 	for _, cat := range expenseCategories {
-		iconName := cat.iconName
-		colorHex := cat.colorHex
-		category := entity.NewCategory(nil, cat.name, entity.CategoryTypeExpense)
-		category.IconName = &iconName
-		category.ColorHex = &colorHex
-
-		err := c.categoryRepo.Create(ctx, category)
-		if err != nil {
+		if err := c.createDefaultCategory(ctx, cat, entity.CategoryTypeExpense, nil, cat.colorHex); err != nil {
 			return err
 		}
 	}
 
 	// Create income categories
 	for _, cat := range incomeCategories {
-		iconName := cat.iconName
-		colorHex := cat.colorHex
-		category := entity.NewCategory(nil, cat.name, entity.CategoryTypeIncome)
-		category.IconName = &iconName
-		category.ColorHex = &colorHex
+		if err := c.createDefaultCategory(ctx, cat, entity.CategoryTypeIncome, nil, cat.colorHex); err != nil {
+			return err
+		}
+	}
 
-		err := c.categoryRepo.Create(ctx, category)
-		if err != nil {
+	return nil
+}
+
+func (c *categoryUsecase) createDefaultCategory(ctx context.Context, cat defaultCategory, categoryType entity.CategoryType, parentID *uuid.UUID, colorHex string) error {
+	iconName := cat.iconName
+	category := entity.NewCategory(nil, cat.name, categoryType)
+	category.ParentID = parentID
+	category.IconName = &iconName
+	category.ColorHex = &colorHex
+
+	if err := c.categoryRepo.Create(ctx, category); err != nil {
+		return err
+	}
+
+	for _, child := range cat.children {
+		if err := c.createDefaultCategory(ctx, child, categoryType, &category.ID, colorHex); err != nil {
 			return err
 		}
 	}
@@ -157,6 +499,73 @@ func (c *categoryUsecase) GetUserCategories(ctx context.Context, userID uuid.UUI
 	return c.GetAllAvailableCategories(ctx, userID)
 }
 
+// GetCategoryTree คืนหมวดหมู่ของระบบและของผู้ใช้จัดเป็นต้นไม้
+func (c *categoryUsecase) GetCategoryTree(ctx context.Context, userID uuid.UUID) ([]*entity.CategoryNode, error) {
+	categories, err := c.GetAllAvailableCategories(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.BuildCategoryTree(categories), nil
+}
+
 func (c *categoryUsecase) GetCategoryUsageStats(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]int64, error) {
 	return c.categoryRepo.GetCategoryUsageStats(ctx, userID)
 }
+
+func (c *categoryUsecase) GetCategoryUsage(ctx context.Context, userID uuid.UUID) ([]*entity.CategoryUsage, error) {
+	return c.categoryRepo.GetUsage(ctx, userID)
+}
+
+func (c *categoryUsecase) GetCategoryOverrides(ctx context.Context, userID uuid.UUID) ([]*entity.CategoryOverride, error) {
+	return c.categoryRepo.GetOverrides(ctx, userID)
+}
+
+// SetCategoryOverride ตั้งชื่อ ไอคอน สี หรือซ่อนหมวดหมู่ระบบเฉพาะผู้ใช้ แทนที่ override เดิมทั้งหมด
+// ค่าที่เป็น nil ใช้ค่าของหมวดหมู่ระบบ หมวดหมู่ของผู้ใช้เองให้แก้ผ่าน UpdateCategory
+func (c *categoryUsecase) SetCategoryOverride(ctx context.Context, userID, categoryID uuid.UUID, name, iconName, colorHex *string, isHidden bool) (*entity.CategoryOverride, error) {
+	category, err := c.categoryRepo.GetByID(ctx, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("category not found: %w", err)
+	}
+	if category.UserID != nil {
+		if *category.UserID != userID {
+			return nil, errors.New("category not found")
+		}
+		return nil, errors.New("only system categories can be overridden, update the category instead")
+	}
+
+	if name != nil {
+		trimmed := strings.TrimSpace(*name)
+		if trimmed == "" {
+			return nil, errors.New("name cannot be empty")
+		}
+		name = &trimmed
+	}
+	if name == nil && iconName == nil && colorHex == nil && !isHidden {
+		return nil, errors.New("override must change name, icon, color or visibility")
+	}
+
+	override := entity.NewCategoryOverride(userID, categoryID)
+	override.Name = name
+	override.IconName = iconName
+	override.ColorHex = colorHex
+	override.IsHidden = isHidden
+
+	if err := c.categoryRepo.UpsertOverride(ctx, override); err != nil {
+		return nil, err
+	}
+
+	invalidateUserCache(ctx, c.responseCache, userID)
+	return override, nil
+}
+
+// ResetCategoryOverride ลบ override ให้หมวดหมู่ระบบกลับไปใช้ค่าเดิมและแสดงอีกครั้ง
+func (c *categoryUsecase) ResetCategoryOverride(ctx context.Context, userID, categoryID uuid.UUID) error {
+	if err := c.categoryRepo.DeleteOverride(ctx, userID, categoryID); err != nil {
+		return err
+	}
+
+	invalidateUserCache(ctx, c.responseCache, userID)
+	return nil
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"savvy-backend/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+func TestWithoutHiddenCategories(t *testing.T) {
+	category := func(name string, parent *entity.Category, hidden bool) *entity.Category {
+		c := &entity.Category{ID: uuid.New(), Name: name, IsHidden: hidden}
+		if parent != nil {
+			c.ParentID = &parent.ID
+		}
+		return c
+	}
+
+	food := category("Food", nil, false)
+	coffee := category("Coffee", food, false)
+	hiddenFood := category("Food", nil, true)
+	hiddenChild := category("Coffee", hiddenFood, false)
+	hiddenGrandchild := category("Espresso", hiddenChild, false)
+	travel := category("Travel", nil, false)
+	hiddenTaxi := category("Taxi", travel, true)
+	orphanParentID := uuid.New()
+	orphan := &entity.Category{ID: uuid.New(), Name: "Orphan", ParentID: &orphanParentID}
+
+	// วงวนของ parent_id ต้องไม่ทำให้วนไม่รู้จบ
+	loopA := &entity.Category{ID: uuid.New(), Name: "LoopA"}
+	loopB := &entity.Category{ID: uuid.New(), Name: "LoopB", ParentID: &loopA.ID}
+	loopA.ParentID = &loopB.ID
+
+	tests := []struct {
+		name       string
+		categories []*entity.Category
+		want       string
+	}{
+		{name: "nothing hidden", categories: []*entity.Category{food, coffee, travel}, want: "Food,Coffee,Travel"},
+		{name: "hidden parent hides all descendants", categories: []*entity.Category{hiddenFood, hiddenChild, hiddenGrandchild, travel}, want: "Travel"},
+		{name: "hidden child keeps parent", categories: []*entity.Category{travel, hiddenTaxi}, want: "Travel"},
+		{name: "descendants listed first", categories: []*entity.Category{hiddenGrandchild, hiddenChild, hiddenFood}, want: ""},
+		{name: "missing parent stays visible", categories: []*entity.Category{orphan}, want: "Orphan"},
+		{name: "parent loop", categories: []*entity.Category{loopA, loopB}, want: "LoopA,LoopB"},
+		{name: "empty", categories: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			visible := withoutHiddenCategories(tt.categories)
+			names := make([]string, 0, len(visible))
+			for _, category := range visible {
+				names = append(names, category.Name)
+			}
+			if got := strings.Join(names, ","); got != tt.want {
+				t.Fatalf("withoutHiddenCategories() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
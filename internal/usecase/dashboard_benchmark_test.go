@@ -0,0 +1,206 @@
+package usecase_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+	"savvy-backend/internal/infrastructure/database"
+	"savvy-backend/internal/usecase"
+)
+
+// benchDatabaseEnv ชี้ไปยังฐานข้อมูลสำหรับทดสอบโดยเฉพาะ (ต้อง migrate แล้ว) ไม่อ่านค่าจาก .env
+// เพื่อไม่ให้ benchmark สร้างข้อมูลจำนวนมากลงในฐานข้อมูลที่ใช้งานจริง
+//
+//	BENCH_DATABASE_DSN="host=localhost dbname=savvy_bench sslmode=disable" go test -run '^$' -bench Dashboard ./internal/usecase
+const benchDatabaseEnv = "BENCH_DATABASE_DSN"
+
+const benchTransactionCount = 100000
+
+// BenchmarkDashboard วัดเวลาของ query หน้า dashboard และ analytics กับผู้ใช้ที่มีรายการ 100k รายการ
+// ผู้ใช้ทดสอบถูกสร้างครั้งเดียวและลบทิ้งเมื่อจบ benchmark
+func BenchmarkDashboard(b *testing.B) {
+	db := openBenchDatabase(b)
+	ctx := context.Background()
+	userID := seedBenchUser(b, ctx, db, benchTransactionCount)
+
+	transactionRepo := database.NewTransactionRepository(db)
+	categoryRepo := database.NewCategoryRepository(db)
+	accountRepo := database.NewAccountRepository(db)
+	budgetRepo := database.NewBudgetRepository(db)
+	analyticsRepo := database.NewAnalyticsRepository(db)
+	userRepo := database.NewUserRepository(db)
+
+	dashboard := usecase.NewDashboardUsecase(transactionRepo, categoryRepo, accountRepo, budgetRepo, analyticsRepo)
+	analytics := usecase.NewAnalyticsUsecase(analyticsRepo, userRepo)
+
+	now := time.Now()
+	yearStart := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := time.Date(now.Year(), time.December, 31, 0, 0, 0, 0, time.UTC)
+
+	operations := []struct {
+		name string
+		run  func() error
+	}{
+		{"MonthlySummary", func() error {
+			_, err := dashboard.GetCurrentMonthlySummary(ctx, userID)
+			return err
+		}},
+		{"YearlySummary", func() error {
+			_, err := dashboard.GetYearlySummary(ctx, userID, now.Year())
+			return err
+		}},
+		{"RecentTransactions", func() error {
+			_, err := dashboard.GetRecentTransactions(ctx, userID, 10)
+			return err
+		}},
+		{"SpendingByCategory", func() error {
+			_, err := dashboard.GetSpendingByCategory(ctx, userID, now.Year(), int(now.Month()), 0)
+			return err
+		}},
+		{"FinancialHealth", func() error {
+			_, err := dashboard.GetFinancialHealth(ctx, userID)
+			return err
+		}},
+		{"TimeSeriesByCategory", func() error {
+			_, err := analytics.GetTimeSeries(ctx, repository.TimeSeriesQuery{
+				Filter:      repository.TransactionFilter{UserID: userID, StartDate: &yearStart, EndDate: &yearEnd},
+				Granularity: entity.GranularityMonth,
+				GroupBy:     entity.GroupByCategory,
+			})
+			return err
+		}},
+	}
+
+	for _, op := range operations {
+		b.Run(op.name, func(b *testing.B) {
+			// รอบแรกเป็น warm-up ให้ plan และ buffer cache พร้อมก่อนจับเวลา
+			if err := op.run(); err != nil {
+				b.Fatalf("%s: %v", op.name, err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := op.run(); err != nil {
+					b.Fatalf("%s: %v", op.name, err)
+				}
+			}
+		})
+	}
+}
+
+func openBenchDatabase(b *testing.B) *sql.DB {
+	b.Helper()
+
+	dsn := os.Getenv(benchDatabaseEnv)
+	if dsn == "" {
+		b.Skipf("%s is not set; skipping database benchmark", benchDatabaseEnv)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		b.Fatalf("failed to open benchmark database: %v", err)
+	}
+	b.Cleanup(func() { db.Close() })
+
+	if err := db.Ping(); err != nil {
+		b.Fatalf("failed to ping benchmark database: %v", err)
+	}
+
+	return db
+}
+
+// seedBenchUser สร้างผู้ใช้ บัญชี หมวดหมู่ และรายการสุ่มย้อนหลัง 3 ปีด้วย generate_series ใน transaction เดียว
+// การลบผู้ใช้จะลบข้อมูลทั้งหมดที่สร้างขึ้นตาม ON DELETE CASCADE
+func seedBenchUser(b *testing.B, ctx context.Context, db *sql.DB, transactionCount int) uuid.UUID {
+	b.Helper()
+
+	userID := uuid.New()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		b.Fatalf("failed to begin seed transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO users (id, email, password_hash, display_name)
+		VALUES ($1, $2, 'benchmark', 'Dashboard Benchmark')
+	`, userID, fmt.Sprintf("bench-%s@example.invalid", userID)); err != nil {
+		b.Fatalf("failed to create user: %v", err)
+	}
+
+	accountIDs := []string{uuid.NewString(), uuid.NewString()}
+	for i, accountID := range accountIDs {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO accounts (id, user_id, name, type, initial_balance)
+			VALUES ($1, $2, $3, 'bank', 50000)
+		`, accountID, userID, fmt.Sprintf("Bench Account %d", i+1)); err != nil {
+			b.Fatalf("failed to create account: %v", err)
+		}
+	}
+
+	var expenseCategoryIDs []string
+	for i := 0; i < 12; i++ {
+		categoryID := uuid.NewString()
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO categories (id, user_id, name, type) VALUES ($1, $2, $3, 'expense')
+		`, categoryID, userID, fmt.Sprintf("Bench Expense %d", i+1)); err != nil {
+			b.Fatalf("failed to create category: %v", err)
+		}
+		expenseCategoryIDs = append(expenseCategoryIDs, categoryID)
+	}
+
+	incomeCategoryID := uuid.NewString()
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO categories (id, user_id, name, type) VALUES ($1, $2, 'Bench Income', 'income')
+	`, incomeCategoryID, userID); err != nil {
+		b.Fatalf("failed to create category: %v", err)
+	}
+
+	// ทุกรายการที่ 20 เป็นรายรับ ที่เหลือเป็นรายจ่ายกระจายตามหมวดหมู่และบัญชี
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO transactions (user_id, category_id, account_id, amount, type, note, transaction_date)
+		SELECT $1,
+			   CASE WHEN g % 20 = 0 THEN $2::uuid ELSE ($3::uuid[])[1 + g % array_length($3::uuid[], 1)] END,
+			   ($4::uuid[])[1 + g % array_length($4::uuid[], 1)],
+			   CASE WHEN g % 20 = 0 THEN ROUND((15000 + random() * 20000)::numeric, 2)
+					ELSE ROUND((20 + random() * 1500)::numeric, 2) END,
+			   CASE WHEN g % 20 = 0 THEN 'income'::transaction_type ELSE 'expense'::transaction_type END,
+			   'bench transaction ' || g,
+			   CURRENT_DATE - (random() * 1095)::int
+		FROM generate_series(1, $5) AS g
+	`, userID, incomeCategoryID, pq.Array(expenseCategoryIDs), pq.Array(accountIDs), transactionCount); err != nil {
+		b.Fatalf("failed to create transactions: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		b.Fatalf("failed to commit seed data: %v", err)
+	}
+
+	b.Cleanup(func() {
+		if _, err := db.ExecContext(context.Background(), `DELETE FROM users WHERE id = $1`, userID); err != nil {
+			b.Errorf("failed to delete benchmark user %s: %v", userID, err)
+		}
+	})
+
+	// รายการถูกสร้างด้วย SQL โดยตรงจึงต้องสร้างตารางสรุปรายวันเอง
+	if _, err := database.NewAnalyticsRepository(db).RebuildDailyCategoryTotals(ctx, &userID); err != nil {
+		b.Fatalf("failed to build daily category totals: %v", err)
+	}
+
+	// อัปเดตสถิติหลัง commit เพื่อให้ planner เลือก index ตามข้อมูลจริง
+	if _, err := db.ExecContext(ctx, `ANALYZE transactions, daily_category_totals`); err != nil {
+		b.Fatalf("failed to analyze transactions: %v", err)
+	}
+
+	return userID
+}
@@ -14,9 +14,11 @@ import (
 
 type DashboardUsecase interface {
 	GetMonthlySummary(ctx context.Context, userID uuid.UUID, year, month int) (*MonthlySummary, error)
+	GetYearlySummary(ctx context.Context, userID uuid.UUID, year int) ([]*MonthlySummary, error)
 	GetCurrentMonthlySummary(ctx context.Context, userID uuid.UUID) (*MonthlySummary, error)
 	GetRecentTransactions(ctx context.Context, userID uuid.UUID, limit int) ([]*TransactionWithDetails, error)
-	GetSpendingByCategory(ctx context.Context, userID uuid.UUID, year, month int) ([]*CategorySpending, error)
+	GetSpendingByCategory(ctx context.Context, userID uuid.UUID, year, month, depth int) ([]*CategorySpending, error)
+	GetFinancialHealth(ctx context.Context, userID uuid.UUID) (*entity.FinancialHealthReport, error)
 }
 
 type MonthlySummary struct {
@@ -47,17 +49,23 @@ type dashboardUsecase struct {
 	transactionRepo repository.TransactionRepository
 	categoryRepo    repository.CategoryRepository
 	accountRepo     repository.AccountRepository
+	budgetRepo      repository.BudgetRepository
+	analyticsRepo   repository.AnalyticsRepository
 }
 
 func NewDashboardUsecase(
 	transactionRepo repository.TransactionRepository,
 	categoryRepo repository.CategoryRepository,
 	accountRepo repository.AccountRepository,
+	budgetRepo repository.BudgetRepository,
+	analyticsRepo repository.AnalyticsRepository,
 ) DashboardUsecase {
 	return &dashboardUsecase{
 		transactionRepo: transactionRepo,
 		categoryRepo:    categoryRepo,
 		accountRepo:     accountRepo,
+		budgetRepo:      budgetRepo,
+		analyticsRepo:   analyticsRepo,
 	}
 }
 
@@ -65,39 +73,58 @@ func (d *dashboardUsecase) GetMonthlySummary(ctx context.Context, userID uuid.UU
 	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
 	endDate := utils.EndOfMonth(startDate)
 
-	// Get all transactions for the month
-	filter := repository.TransactionFilter{
-		UserID:    userID,
-		StartDate: &startDate,
-		EndDate:   &endDate,
+	totals, err := d.analyticsRepo.GetMonthlyTotals(ctx, userID, startDate, startDate.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &MonthlySummary{
+		Year:      year,
+		Month:     month,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+	if len(totals) > 0 {
+		summary.TotalIncome = totals[0].Income
+		summary.TotalExpense = totals[0].Expense
 	}
+	summary.Balance = summary.TotalIncome.Sub(summary.TotalExpense)
 
-	transactions, err := d.transactionRepo.GetByFilter(ctx, filter)
+	return summary, nil
+}
+
+// GetYearlySummary สรุปรายรับ/รายจ่ายครบ 12 เดือนของปีด้วย query เดียว เดือนที่ไม่มีรายการจะเป็น 0
+func (d *dashboardUsecase) GetYearlySummary(ctx context.Context, userID uuid.UUID, year int) ([]*MonthlySummary, error) {
+	yearStart := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	totals, err := d.analyticsRepo.GetMonthlyTotals(ctx, userID, yearStart, yearStart.AddDate(1, 0, 0))
 	if err != nil {
 		return nil, err
 	}
 
-	var totalIncome, totalExpense decimal.Decimal
+	totalByMonth := make(map[time.Month]*entity.MonthlyTotal)
+	for _, total := range totals {
+		totalByMonth[total.Month.Month()] = total
+	}
 
-	for _, transaction := range transactions {
-		if transaction.Type == entity.TransactionTypeIncome {
-			totalIncome = totalIncome.Add(transaction.Amount)
-		} else if transaction.Type == entity.TransactionTypeExpense {
-			totalExpense = totalExpense.Add(transaction.Amount)
+	summaries := make([]*MonthlySummary, 0, 12)
+	for month := time.January; month <= time.December; month++ {
+		startDate := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		summary := &MonthlySummary{
+			Year:      year,
+			Month:     int(month),
+			StartDate: startDate,
+			EndDate:   utils.EndOfMonth(startDate),
 		}
+		if total, ok := totalByMonth[month]; ok {
+			summary.TotalIncome = total.Income
+			summary.TotalExpense = total.Expense
+		}
+		summary.Balance = summary.TotalIncome.Sub(summary.TotalExpense)
+		summaries = append(summaries, summary)
 	}
 
-	balance := totalIncome.Sub(totalExpense)
-
-	return &MonthlySummary{
-		Year:         year,
-		Month:        month,
-		TotalIncome:  totalIncome,
-		TotalExpense: totalExpense,
-		Balance:      balance,
-		StartDate:    startDate,
-		EndDate:      endDate,
-	}, nil
+	return summaries, nil
 }
 
 func (d *dashboardUsecase) GetCurrentMonthlySummary(ctx context.Context, userID uuid.UUID) (*MonthlySummary, error) {
@@ -110,87 +137,49 @@ func (d *dashboardUsecase) GetRecentTransactions(ctx context.Context, userID uui
 		limit = 10 // Default limit
 	}
 
-	filter := repository.TransactionFilter{
-		UserID: userID,
-		Limit:  limit,
-	}
-
-	transactions, err := d.transactionRepo.GetByFilter(ctx, filter)
+	details, err := d.transactionRepo.GetRecentWithDetails(ctx, userID, limit)
 	if err != nil {
 		return nil, err
 	}
 
 	var result []*TransactionWithDetails
-
-	for _, transaction := range transactions {
-		// Get category details
-		category, err := d.categoryRepo.GetByID(ctx, transaction.CategoryID)
-		if err != nil {
-			continue // Skip if category not found
-		}
-
-		// Get account details
-		account, err := d.accountRepo.GetByID(ctx, transaction.AccountID)
-		if err != nil {
-			continue // Skip if account not found
-		}
-
+	for _, detail := range details {
 		result = append(result, &TransactionWithDetails{
-			Transaction:  transaction,
-			CategoryName: category.Name,
-			AccountName:  account.Name,
+			Transaction:  detail.Transaction,
+			CategoryName: detail.CategoryName,
+			AccountName:  detail.AccountName,
 		})
 	}
 
 	return result, nil
 }
 
-func (d *dashboardUsecase) GetSpendingByCategory(ctx context.Context, userID uuid.UUID, year, month int) ([]*CategorySpending, error) {
+// GetSpendingByCategory รวมรายจ่ายของเดือนตามหมวดหมู่ depth > 0 จะรวมหมวดหมู่ย่อยขึ้นไปที่ระดับนั้น
+// (1 = หมวดหมู่หลักเท่านั้น) ส่วน depth = 0 แสดงทุกหมวดหมู่แยกกันตามที่บันทึก
+func (d *dashboardUsecase) GetSpendingByCategory(ctx context.Context, userID uuid.UUID, year, month, depth int) ([]*CategorySpending, error) {
 	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
 	endDate := utils.EndOfMonth(startDate)
 
-	// Get expense transactions for the month
-	expenseType := entity.TransactionTypeExpense
-	filter := repository.TransactionFilter{
-		UserID:    userID,
-		Type:      &expenseType,
-		StartDate: &startDate,
-		EndDate:   &endDate,
+	// รวมยอดรายจ่ายตามหมวดหมู่ใน SQL แทนการโหลดทุกรายการของเดือน
+	var totals []*entity.CategoryTotal
+	var err error
+	if depth > 0 {
+		totals, err = d.analyticsRepo.GetCategoryTotalsAtDepth(ctx, userID, entity.TransactionTypeExpense, startDate, endDate, depth)
+	} else {
+		totals, err = d.analyticsRepo.GetCategoryTotals(ctx, userID, entity.TransactionTypeExpense, startDate, endDate)
 	}
-
-	transactions, err := d.transactionRepo.GetByFilter(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	// Group by category
-	categoryTotals := make(map[uuid.UUID]decimal.Decimal)
-	categoryNames := make(map[uuid.UUID]string)
-	categoryIcons := make(map[uuid.UUID]*string)
-	categoryColors := make(map[uuid.UUID]*string)
-
-	for _, transaction := range transactions {
-		categoryTotals[transaction.CategoryID] = categoryTotals[transaction.CategoryID].Add(transaction.Amount)
-
-		// Get category details if not already cached
-		if _, exists := categoryNames[transaction.CategoryID]; !exists {
-			category, err := d.categoryRepo.GetByID(ctx, transaction.CategoryID)
-			if err == nil {
-				categoryNames[transaction.CategoryID] = category.Name
-				categoryIcons[transaction.CategoryID] = category.IconName
-				categoryColors[transaction.CategoryID] = category.ColorHex
-			}
-		}
-	}
-
 	var result []*CategorySpending
-	for categoryID, amount := range categoryTotals {
+	for _, total := range totals {
 		result = append(result, &CategorySpending{
-			CategoryID:   categoryID,
-			CategoryName: categoryNames[categoryID],
-			Amount:       amount,
-			IconName:     categoryIcons[categoryID],
-			ColorHex:     categoryColors[categoryID],
+			CategoryID:   total.CategoryID,
+			CategoryName: total.CategoryName,
+			Amount:       total.Total,
+			IconName:     total.IconName,
+			ColorHex:     total.ColorHex,
 		})
 	}
 
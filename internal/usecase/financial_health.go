@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	healthHistoryMonths = 12
+	// ค่าเฉลี่ยรายจ่ายที่ใช้คำนวณเงินสำรองฉุกเฉินย้อนหลังกี่เดือน
+	emergencyFundExpenseMonths = 3
+
+	// เกณฑ์ที่ถือว่าได้คะแนนเต็มในแต่ละตัวชี้วัด
+	targetSavingsRate        = 0.20
+	targetEmergencyFundMonth = 6.0
+	targetFixedExpenseRatio  = 0.50
+)
+
+// GetFinancialHealth คำนวณตัวชี้วัดสุขภาพการเงินรายเดือนย้อนหลัง 12 เดือน (รวมเดือนปัจจุบัน)
+func (d *dashboardUsecase) GetFinancialHealth(ctx context.Context, userID uuid.UUID) (*entity.FinancialHealthReport, error) {
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	firstMonth := currentMonth.AddDate(0, -(healthHistoryMonths - 1), 0)
+	// ดึงย้อนไปอีกเล็กน้อยเพื่อหาค่าเฉลี่ยรายจ่ายของเดือนแรกๆ
+	lookbackStart := firstMonth.AddDate(0, -(emergencyFundExpenseMonths - 1), 0)
+
+	flows, err := d.transactionRepo.GetMonthlyCashFlow(ctx, userID, lookbackStart, currentMonth.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monthly cash flow: %w", err)
+	}
+	flowByMonth := make(map[string]*entity.MonthlyCashFlow)
+	for _, flow := range flows {
+		flowByMonth[flow.Month.Format("2006-01")] = flow
+	}
+
+	accounts, err := d.accountRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+
+	// ยอดคงเหลือสิ้นเดือนของทั้ง 12 เดือนดึงมาใน query เดียว แทนการ query ทีละเดือน
+	var savingsAccounts []uuid.UUID
+	for _, account := range accounts {
+		if account.Type == entity.AccountTypeSavings {
+			savingsAccounts = append(savingsAccounts, account.ID)
+		}
+	}
+	var monthEndBalances map[string]map[uuid.UUID]decimal.Decimal
+	if len(savingsAccounts) > 0 {
+		monthEndBalances, err = d.accountRepo.GetMonthEndBalances(ctx, userID, firstMonth, currentMonth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account balances: %w", err)
+		}
+	}
+
+	report := &entity.FinancialHealthReport{
+		History:     make([]*entity.FinancialHealthMonth, 0, healthHistoryMonths),
+		GeneratedAt: now,
+	}
+
+	for i := 0; i < healthHistoryMonths; i++ {
+		monthStart := firstMonth.AddDate(0, i, 0)
+		health := &entity.FinancialHealthMonth{
+			Year:  monthStart.Year(),
+			Month: int(monthStart.Month()),
+		}
+
+		if flow, ok := flowByMonth[monthStart.Format("2006-01")]; ok {
+			health.Income = flow.Income
+			health.Expense = flow.Expense
+			health.FixedExpense = flow.RecurringExpense
+			health.VariableExpense = flow.Expense.Sub(flow.RecurringExpense)
+		}
+
+		if health.Income.IsPositive() {
+			health.SavingsRate = ratio(health.Income.Sub(health.Expense), health.Income)
+		}
+		if health.Expense.IsPositive() {
+			health.FixedExpenseRatio = ratio(health.FixedExpense, health.Expense)
+		}
+
+		fillEmergencyFund(savingsAccounts, monthEndBalances, flowByMonth, monthStart, health)
+		if err := d.fillBudgetAdherence(ctx, userID, monthStart, health); err != nil {
+			return nil, err
+		}
+
+		health.Score = healthScore(health)
+		report.History = append(report.History, health)
+	}
+
+	report.Current = report.History[len(report.History)-1]
+	return report, nil
+}
+
+// fillEmergencyFund ใช้ยอดบัญชีออมทรัพย์ ณ สิ้นเดือน หารด้วยรายจ่ายเฉลี่ย 3 เดือนล่าสุด
+func fillEmergencyFund(savingsAccounts []uuid.UUID, monthEndBalances map[string]map[uuid.UUID]decimal.Decimal, flowByMonth map[string]*entity.MonthlyCashFlow, monthStart time.Time, health *entity.FinancialHealthMonth) {
+	if len(savingsAccounts) == 0 {
+		return
+	}
+
+	balances := monthEndBalances[monthStart.Format("2006-01")]
+	for _, accountID := range savingsAccounts {
+		health.SavingsBalance = health.SavingsBalance.Add(balances[accountID])
+	}
+
+	var totalExpense decimal.Decimal
+	for i := 0; i < emergencyFundExpenseMonths; i++ {
+		if flow, ok := flowByMonth[monthStart.AddDate(0, -i, 0).Format("2006-01")]; ok {
+			totalExpense = totalExpense.Add(flow.Expense)
+		}
+	}
+	averageExpense := totalExpense.Div(decimal.NewFromInt(emergencyFundExpenseMonths))
+	if averageExpense.IsPositive() {
+		health.EmergencyFundMonths = ratio(health.SavingsBalance, averageExpense)
+	}
+}
+
+// fillBudgetAdherence นับสัดส่วนงบประมาณที่ไม่เกินวงเงินในเดือนนั้น
+func (d *dashboardUsecase) fillBudgetAdherence(ctx context.Context, userID uuid.UUID, monthStart time.Time, health *entity.FinancialHealthMonth) error {
+	progresses, err := d.budgetRepo.GetBudgetProgress(ctx, userID, monthStart.Year(), int(monthStart.Month()))
+	if err != nil {
+		return fmt.Errorf("failed to get budget progress: %w", err)
+	}
+
+	health.BudgetsTracked = len(progresses)
+	for _, progress := range progresses {
+		if !progress.IsOverBudget {
+			health.BudgetsWithinLimit++
+		}
+	}
+	if health.BudgetsTracked > 0 {
+		adherence := round2(float64(health.BudgetsWithinLimit) / float64(health.BudgetsTracked))
+		health.BudgetAdherence = &adherence
+	}
+
+	return nil
+}
+
+// healthScore ให้คะแนน 0-100 จากตัวชี้วัดที่มีข้อมูล โดยแต่ละตัวมีน้ำหนักเท่ากัน
+// ออมได้ 20% ของรายรับ, มีเงินสำรอง 6 เดือน, ค่าใช้จ่ายคงที่ไม่เกิน 50% และไม่เกินงบทุกหมวด = คะแนนเต็ม
+func healthScore(health *entity.FinancialHealthMonth) *int {
+	var components []float64
+
+	if health.SavingsRate != nil {
+		components = append(components, clamp01(*health.SavingsRate/targetSavingsRate))
+	}
+	if health.EmergencyFundMonths != nil {
+		components = append(components, clamp01(*health.EmergencyFundMonths/targetEmergencyFundMonth))
+	}
+	if health.FixedExpenseRatio != nil {
+		// ต่ำกว่าเป้าได้เต็ม แล้วลดลงเป็นเส้นตรงจนเหลือ 0 เมื่อรายจ่ายทั้งหมดเป็นค่าใช้จ่ายคงที่
+		components = append(components, clamp01((1-*health.FixedExpenseRatio)/(1-targetFixedExpenseRatio)))
+	}
+	if health.BudgetAdherence != nil {
+		components = append(components, *health.BudgetAdherence)
+	}
+
+	if len(components) == 0 {
+		return nil
+	}
+
+	var total float64
+	for _, component := range components {
+		total += component
+	}
+	score := int(math.Round(total / float64(len(components)) * 100))
+	return &score
+}
+
+func ratio(numerator, denominator decimal.Decimal) *float64 {
+	value, _ := numerator.Div(denominator).Float64()
+	value = round2(value)
+	return &value
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func clamp01(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
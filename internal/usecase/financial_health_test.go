@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestHealthScore(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	score := func(v int) *int { return &v }
+
+	tests := []struct {
+		name   string
+		health entity.FinancialHealthMonth
+		want   *int
+	}{
+		{name: "no indicators", health: entity.FinancialHealthMonth{}, want: nil},
+		{
+			name:   "all targets met",
+			health: entity.FinancialHealthMonth{SavingsRate: f(0.2), EmergencyFundMonths: f(6), FixedExpenseRatio: f(0.5), BudgetAdherence: f(1)},
+			want:   score(100),
+		},
+		{
+			name:   "beyond targets is capped",
+			health: entity.FinancialHealthMonth{SavingsRate: f(0.6), EmergencyFundMonths: f(24), FixedExpenseRatio: f(0.1)},
+			want:   score(100),
+		},
+		{
+			name:   "half of every target",
+			health: entity.FinancialHealthMonth{SavingsRate: f(0.1), EmergencyFundMonths: f(3), FixedExpenseRatio: f(0.75), BudgetAdherence: f(0.5)},
+			want:   score(50),
+		},
+		{name: "negative savings rate scores zero", health: entity.FinancialHealthMonth{SavingsRate: f(-0.4)}, want: score(0)},
+		{name: "only fixed expenses", health: entity.FinancialHealthMonth{FixedExpenseRatio: f(1)}, want: score(0)},
+		{name: "no budgets kept", health: entity.FinancialHealthMonth{BudgetAdherence: f(0)}, want: score(0)},
+		{
+			// (0.75 + 1/6) / 2 = 0.4583
+			name:   "missing indicators are excluded and result is rounded",
+			health: entity.FinancialHealthMonth{SavingsRate: f(0.15), EmergencyFundMonths: f(1)},
+			want:   score(46),
+		},
+		{
+			name:   "negative emergency fund scores zero",
+			health: entity.FinancialHealthMonth{EmergencyFundMonths: f(-2), BudgetAdherence: f(1)},
+			want:   score(50),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := healthScore(&tt.health)
+			switch {
+			case tt.want == nil && got != nil:
+				t.Fatalf("healthScore() = %d, want nil", *got)
+			case tt.want != nil && got == nil:
+				t.Fatalf("healthScore() = nil, want %d", *tt.want)
+			case tt.want != nil && *got != *tt.want:
+				t.Fatalf("healthScore() = %d, want %d", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestFillEmergencyFund(t *testing.T) {
+	d := decimal.RequireFromString
+	savings, other := uuid.New(), uuid.New()
+	march := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	monthEndBalances := map[string]map[uuid.UUID]decimal.Decimal{
+		"2026-03": {savings: d("30000"), other: d("99999")},
+	}
+	flowByMonth := map[string]*entity.MonthlyCashFlow{
+		"2026-01": {Expense: d("4000")},
+		"2026-02": {Expense: d("6000")},
+		"2026-03": {Expense: d("5000")},
+	}
+
+	tests := []struct {
+		name        string
+		accounts    []uuid.UUID
+		balances    map[string]map[uuid.UUID]decimal.Decimal
+		flows       map[string]*entity.MonthlyCashFlow
+		wantBalance string
+		wantMonths  *float64
+	}{
+		{name: "no savings accounts", balances: monthEndBalances, flows: flowByMonth, wantBalance: "0"},
+		{name: "only savings accounts are counted", accounts: []uuid.UUID{savings}, balances: monthEndBalances, flows: flowByMonth, wantBalance: "30000", wantMonths: floatPtr(6)},
+		{name: "month without balances", accounts: []uuid.UUID{savings}, flows: flowByMonth, wantBalance: "0", wantMonths: floatPtr(0)},
+		{name: "no expenses", accounts: []uuid.UUID{savings}, balances: monthEndBalances, wantBalance: "30000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := &entity.FinancialHealthMonth{}
+			fillEmergencyFund(tt.accounts, tt.balances, tt.flows, march, health)
+
+			if !health.SavingsBalance.Equal(d(tt.wantBalance)) {
+				t.Errorf("SavingsBalance = %s, want %s", health.SavingsBalance, tt.wantBalance)
+			}
+			switch {
+			case tt.wantMonths == nil && health.EmergencyFundMonths != nil:
+				t.Errorf("EmergencyFundMonths = %v, want nil", *health.EmergencyFundMonths)
+			case tt.wantMonths != nil && (health.EmergencyFundMonths == nil || *health.EmergencyFundMonths != *tt.wantMonths):
+				t.Errorf("EmergencyFundMonths = %v, want %v", health.EmergencyFundMonths, *tt.wantMonths)
+			}
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+	"savvy-backend/internal/i18n"
+	"savvy-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	DefaultForecastDays = 90
+	MaxForecastDays     = 365
+	// ย้อนดูประวัติกี่วันเพื่อหาอัตราการใช้จ่ายต่อวันที่ไม่ได้มาจากรายการประจำ
+	forecastLookbackDays = 90
+)
+
+type ForecastUsecase interface {
+	GetCashFlowForecast(ctx context.Context, userID uuid.UUID, days int) (*entity.CashFlowForecast, error)
+	CheckCashFlowWarnings(ctx context.Context, userID uuid.UUID, days int) ([]*entity.Insight, error)
+}
+
+type forecastUsecase struct {
+	accountRepo     repository.AccountRepository
+	transactionRepo repository.TransactionRepository
+	recurringRepo   repository.RecurringTransactionRepository
+	categoryRepo    repository.CategoryRepository
+	insightRepo     repository.InsightRepository
+	userRepo        repository.UserRepository
+}
+
+func NewForecastUsecase(
+	accountRepo repository.AccountRepository,
+	transactionRepo repository.TransactionRepository,
+	recurringRepo repository.RecurringTransactionRepository,
+	categoryRepo repository.CategoryRepository,
+	insightRepo repository.InsightRepository,
+	userRepo repository.UserRepository,
+) ForecastUsecase {
+	return &forecastUsecase{
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		recurringRepo:   recurringRepo,
+		categoryRepo:    categoryRepo,
+		insightRepo:     insightRepo,
+		userRepo:        userRepo,
+	}
+}
+
+// GetCashFlowForecast คาดการณ์ยอดคงเหลือรายวันของแต่ละบัญชีและยอดรวม
+// จากยอดปัจจุบัน + รายการประจำที่จะเกิดขึ้น - อัตราการใช้จ่ายทั่วไปต่อวันของแต่ละหมวดหมู่
+func (f *forecastUsecase) GetCashFlowForecast(ctx context.Context, userID uuid.UUID, days int) (*entity.CashFlowForecast, error) {
+	if days <= 0 {
+		days = DefaultForecastDays
+	}
+	if days > MaxForecastDays {
+		return nil, fmt.Errorf("forecast cannot exceed %d days", MaxForecastDays)
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	lastDay := today.AddDate(0, 0, days-1)
+	endOfRange := lastDay.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	accounts, err := f.accountRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+
+	balances, err := f.accountRepo.GetCurrentBalances(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account balances: %w", err)
+	}
+
+	rates, err := f.discretionaryRates(ctx, userID, today)
+	if err != nil {
+		return nil, err
+	}
+
+	dailyDiscretionary := make(map[uuid.UUID]decimal.Decimal)
+	for _, rate := range rates {
+		dailyDiscretionary[rate.AccountID] = dailyDiscretionary[rate.AccountID].Add(rate.DailyAmount)
+	}
+
+	// scheduledIncome[accountID][dayIndex], scheduledExpense[accountID][dayIndex]
+	scheduledIncome, scheduledExpense, err := f.scheduledRecurring(ctx, userID, accounts, today, endOfRange, days)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast := &entity.CashFlowForecast{
+		StartDate:          today,
+		EndDate:            lastDay,
+		Days:               days,
+		LookbackDays:       forecastLookbackDays,
+		Accounts:           make([]*entity.AccountForecast, 0, len(accounts)),
+		Overall:            make([]*entity.ForecastDay, days),
+		NegativeDates:      make([]time.Time, 0),
+		DiscretionaryRates: rates,
+	}
+
+	for i := 0; i < days; i++ {
+		forecast.Overall[i] = &entity.ForecastDay{Date: today.AddDate(0, 0, i)}
+	}
+
+	negativeDays := make(map[int]bool)
+	for _, account := range accounts {
+		balance := balances[account.ID]
+		accountForecast := &entity.AccountForecast{
+			AccountID:       account.ID,
+			AccountName:     account.Name,
+			AccountType:     account.Type,
+			StartingBalance: balance,
+			LowestBalance:   balance,
+			Days:            make([]*entity.ForecastDay, days),
+		}
+
+		for i := 0; i < days; i++ {
+			day := &entity.ForecastDay{
+				Date:               forecast.Overall[i].Date,
+				ScheduledIncome:    scheduledIncome[account.ID][i],
+				ScheduledExpense:   scheduledExpense[account.ID][i],
+				DiscretionarySpend: dailyDiscretionary[account.ID],
+			}
+			balance = balance.Add(day.ScheduledIncome).Sub(day.ScheduledExpense).Sub(day.DiscretionarySpend)
+			day.Balance = balance
+
+			// บัญชีหนี้สิน (บัตรเครดิต, เงินกู้) ติดลบเป็นเรื่องปกติ (ยอดหนี้) จึงไม่นับเป็นสัญญาณเตือน
+			if !account.Type.IsLiability() && balance.IsNegative() {
+				day.IsNegative = true
+				negativeDays[i] = true
+				if accountForecast.FirstNegativeDate == nil {
+					date := day.Date
+					accountForecast.FirstNegativeDate = &date
+				}
+			}
+			if balance.LessThan(accountForecast.LowestBalance) {
+				accountForecast.LowestBalance = balance
+			}
+
+			overall := forecast.Overall[i]
+			overall.ScheduledIncome = overall.ScheduledIncome.Add(day.ScheduledIncome)
+			overall.ScheduledExpense = overall.ScheduledExpense.Add(day.ScheduledExpense)
+			overall.DiscretionarySpend = overall.DiscretionarySpend.Add(day.DiscretionarySpend)
+			overall.Balance = overall.Balance.Add(balance)
+
+			accountForecast.Days[i] = day
+		}
+
+		accountForecast.EndingBalance = balance
+		forecast.Accounts = append(forecast.Accounts, accountForecast)
+	}
+
+	for i, overall := range forecast.Overall {
+		if overall.Balance.IsNegative() {
+			overall.IsNegative = true
+			negativeDays[i] = true
+		}
+	}
+
+	for i := 0; i < days; i++ {
+		if negativeDays[i] {
+			forecast.NegativeDates = append(forecast.NegativeDates, forecast.Overall[i].Date)
+		}
+	}
+
+	return forecast, nil
+}
+
+// CheckCashFlowWarnings สร้าง insight ความสำคัญสูงสำหรับทุกบัญชีที่คาดว่ายอดจะติดลบ
+func (f *forecastUsecase) CheckCashFlowWarnings(ctx context.Context, userID uuid.UUID, days int) ([]*entity.Insight, error) {
+	forecast, err := f.GetCashFlowForecast(ctx, userID, days)
+	if err != nil {
+		return nil, err
+	}
+
+	audience := loadAudience(ctx, f.userRepo, userID)
+	var insights []*entity.Insight
+	for _, account := range forecast.Accounts {
+		if account.FirstNegativeDate == nil {
+			continue
+		}
+
+		insights = append(insights, cashFlowWarning(userID, audience, forecast, account))
+	}
+
+	// บัญชีที่ไม่ติดลบแล้วจะถูก resolve อัตโนมัติ
+	if err := saveInsights(ctx, f.insightRepo, userID, []entity.InsightType{entity.InsightTypeCashflowWarning}, insights); err != nil {
+		return nil, err
+	}
+
+	return insights, nil
+}
+
+// cashFlowWarning สร้างคำเตือนของบัญชีที่คาดว่ายอดจะติดลบ (account.FirstNegativeDate ต้องไม่เป็น nil)
+func cashFlowWarning(userID uuid.UUID, audience i18n.Audience, forecast *entity.CashFlowForecast, account *entity.AccountForecast) *entity.Insight {
+	daysUntil := utils.DaysBetween(forecast.StartDate, *account.FirstNegativeDate)
+	message := i18n.Message{
+		Key: "cashflow_warning",
+		Params: map[string]interface{}{
+			"account":        account.AccountName,
+			"date":           account.FirstNegativeDate.Format("2006-01-02"),
+			"days":           daysUntil,
+			"lowest_balance": account.LowestBalance.StringFixed(2),
+		},
+	}
+
+	insight := newLocalizedInsight(userID, entity.InsightTypeCashflowWarning, entity.InsightPriorityHigh, audience, message)
+	insight.RelatedEntityID = &account.AccountID
+	insight.RelatedEntityType = &[]string{"account"}[0]
+
+	relatedData := map[string]interface{}{
+		"account_id":          account.AccountID,
+		"first_negative_date": account.FirstNegativeDate.Format("2006-01-02"),
+		"lowest_balance":      account.LowestBalance,
+		"starting_balance":    account.StartingBalance,
+		"forecast_days":       forecast.Days,
+	}
+	if data, err := json.Marshal(relatedData); err == nil {
+		insight.RelatedData = data
+	}
+
+	insight.SetFingerprint("forecast")
+
+	// หมดอายุเมื่อจบวันที่ยอดติดลบ คำเตือนของวันนี้จึงยังแสดงได้ตลอดทั้งวัน
+	validUntil := account.FirstNegativeDate.AddDate(0, 0, 1)
+	insight.ValidUntil = &validUntil
+
+	return insight
+}
+
+// discretionaryRates หาอัตราการใช้จ่ายต่อวันของแต่ละบัญชี/หมวดหมู่ โดยไม่นับรายการที่สร้างจากรายการประจำ
+// (รายการเหล่านั้นถูกนับแยกจากกฎ recurring แล้ว)
+func (f *forecastUsecase) discretionaryRates(ctx context.Context, userID uuid.UUID, today time.Time) ([]*entity.DiscretionaryRate, error) {
+	spending, err := f.transactionRepo.GetDiscretionarySpending(ctx, userID, today.AddDate(0, 0, -forecastLookbackDays), today)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get discretionary spending: %w", err)
+	}
+
+	lookback := decimal.NewFromInt(forecastLookbackDays)
+	rates := make([]*entity.DiscretionaryRate, 0, len(spending))
+	if len(spending) == 0 {
+		return rates, nil
+	}
+
+	categoryNames, err := loadCategoryNames(ctx, f.categoryRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, spend := range spending {
+		rates = append(rates, &entity.DiscretionaryRate{
+			AccountID:    spend.AccountID,
+			CategoryID:   spend.CategoryID,
+			CategoryName: categoryNames[spend.CategoryID],
+			DailyAmount:  spend.Total.Div(lookback).Round(2),
+		})
+	}
+
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].DailyAmount.GreaterThan(rates[j].DailyAmount)
+	})
+
+	return rates, nil
+}
+
+// scheduledRecurring กระจายรายการประจำที่ active ลงในแต่ละวันของช่วงคาดการณ์
+// รายการที่เลยกำหนดแต่ยังไม่ได้รันจะถูกนับเป็นวันแรกเพียงครั้งเดียว (ดู occurrenceDayIndexes)
+func (f *forecastUsecase) scheduledRecurring(ctx context.Context, userID uuid.UUID, accounts []*entity.Account, today, endOfRange time.Time, days int) (map[uuid.UUID][]decimal.Decimal, map[uuid.UUID][]decimal.Decimal, error) {
+	isActive := true
+	rules, err := f.recurringRepo.GetByFilter(ctx, repository.RecurringTransactionFilter{
+		UserID:   userID,
+		IsActive: &isActive,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get recurring transactions: %w", err)
+	}
+
+	income := make(map[uuid.UUID][]decimal.Decimal)
+	expense := make(map[uuid.UUID][]decimal.Decimal)
+
+	for _, rule := range rules {
+		dates := rule.OccurrencesBetween(rule.NextExecutionDate, endOfRange)
+		if len(dates) == 0 {
+			continue
+		}
+
+		estimate, err := estimateRecurringAmount(ctx, f.recurringRepo, rule)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		target := expense
+		if rule.Type == entity.TransactionTypeIncome {
+			target = income
+		}
+		if target[rule.AccountID] == nil {
+			target[rule.AccountID] = make([]decimal.Decimal, days)
+		}
+
+		for _, index := range occurrenceDayIndexes(dates, today, days) {
+			target[rule.AccountID][index] = target[rule.AccountID][index].Add(estimate.Amount)
+		}
+	}
+
+	// Accounts without recurring rules still need a zero-filled slice
+	for _, account := range accounts {
+		if income[account.ID] == nil {
+			income[account.ID] = make([]decimal.Decimal, days)
+		}
+		if expense[account.ID] == nil {
+			expense[account.ID] = make([]decimal.Decimal, days)
+		}
+	}
+
+	return income, expense, nil
+}
+
+// occurrenceDayIndexes แปลงวันที่ของรายการประจำเป็น index ของวันในช่วงคาดการณ์ ตัดวันที่เลยช่วงทิ้ง
+// กฎที่ค้างหลายรอบนับเฉพาะรอบที่ค้างอยู่รอบเดียวในวันแรก เพราะกฎที่ถูกลืมไว้นานมักไม่ได้จ่ายย้อนหลังทุกรอบ
+// และถ้านับทุกรอบจะทำให้ยอดวันแรกลดลงก้อนใหญ่จนเตือนยอดติดลบเกินจริง
+func occurrenceDayIndexes(dates []time.Time, today time.Time, days int) []int {
+	indexes := make([]int, 0, len(dates))
+	overdue := false
+	for _, date := range dates {
+		// วันที่ของรายการประจำเป็น UTC ส่วน today เป็นเวลาท้องถิ่น จึงเทียบเฉพาะวันที่ในปฏิทิน
+		index := utils.DaysBetween(today, date)
+		if index < 0 {
+			if overdue {
+				continue
+			}
+			overdue = true
+			index = 0
+		}
+		if index >= days {
+			continue
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes
+}
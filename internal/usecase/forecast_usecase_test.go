@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/i18n"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestCashFlowWarningValidUntil(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	forecast := &entity.CashFlowForecast{StartDate: today, Days: DefaultForecastDays}
+
+	tests := []struct {
+		name         string
+		negativeDate time.Time
+	}{
+		{name: "negative today", negativeDate: today},
+		{name: "negative tomorrow", negativeDate: today.AddDate(0, 0, 1)},
+		{name: "negative next month", negativeDate: today.AddDate(0, 1, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			negativeDate := tt.negativeDate
+			account := &entity.AccountForecast{
+				AccountID:         uuid.New(),
+				AccountName:       "Wallet",
+				LowestBalance:     decimal.NewFromInt(-500),
+				FirstNegativeDate: &negativeDate,
+			}
+
+			insight := cashFlowWarning(uuid.New(), i18n.NewAudience(i18n.LocaleEnglish, "THB"), forecast, account)
+
+			// คำเตือนต้องยังไม่หมดอายุ (ไม่ถูก purge) จนกว่าจะจบวันที่ยอดติดลบ
+			if insight.ValidUntil == nil || !insight.ValidUntil.Equal(negativeDate.AddDate(0, 0, 1)) {
+				t.Fatalf("ValidUntil = %v, want %v", insight.ValidUntil, negativeDate.AddDate(0, 0, 1))
+			}
+			if !insight.ValidUntil.After(now) {
+				t.Fatalf("ValidUntil = %v is already expired at %v", insight.ValidUntil, now)
+			}
+			if insight.Fingerprint == nil || insight.RelatedEntityID == nil || *insight.RelatedEntityID != account.AccountID {
+				t.Fatalf("insight = %+v, want fingerprint and related account", insight)
+			}
+		})
+	}
+}
+
+func TestOccurrenceDayIndexes(t *testing.T) {
+	today := testDate(2026, time.October, 18)
+
+	tests := []struct {
+		name  string
+		dates []time.Time
+		days  int
+		want  []int
+	}{
+		{name: "no occurrences", days: 30, want: []int{}},
+		{
+			name:  "upcoming within range",
+			dates: []time.Time{today, today.AddDate(0, 0, 7), today.AddDate(0, 0, 14)},
+			days:  30,
+			want:  []int{0, 7, 14},
+		},
+		{
+			name:  "beyond range dropped",
+			dates: []time.Time{today.AddDate(0, 0, 10), today.AddDate(0, 0, 40)},
+			days:  30,
+			want:  []int{10},
+		},
+		{
+			name:  "one overdue occurrence lands on the first day",
+			dates: []time.Time{today.AddDate(0, 0, -3), today.AddDate(0, 1, -3)},
+			days:  60,
+			want:  []int{0, 28},
+		},
+		{
+			// ค้างมา 3 เดือน นับเฉพาะรอบที่ค้างรอบเดียว ไม่รวม 3 รอบไว้ในวันแรก
+			name:  "several overdue periods count once",
+			dates: []time.Time{today.AddDate(0, -3, 0), today.AddDate(0, -2, 0), today.AddDate(0, -1, 0), today.AddDate(0, 1, 0)},
+			days:  60,
+			want:  []int{0, 31},
+		},
+		{
+			name:  "overdue plus due today",
+			dates: []time.Time{today.AddDate(0, 0, -7), today},
+			days:  30,
+			want:  []int{0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := occurrenceDayIndexes(tt.dates, today, tt.days)
+			if len(got) != len(tt.want) {
+				t.Fatalf("occurrenceDayIndexes() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("occurrenceDayIndexes() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+	"savvy-backend/internal/domain/service"
+	"savvy-backend/internal/i18n"
+
+	"github.com/google/uuid"
+)
+
+// loadAudience อ่านภาษาและสกุลเงินที่ผู้ใช้ตั้งไว้ ถ้าอ่านไม่ได้จะใช้ค่าเริ่มต้นเพื่อไม่ให้การสร้าง insight ล้มเหลว
+func loadAudience(ctx context.Context, userRepo repository.UserRepository, userID uuid.UUID) i18n.Audience {
+	user, err := userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return i18n.DefaultAudience()
+	}
+	return i18n.NewAudience(user.Locale, user.CurrencyPreference)
+}
+
+// newLocalizedInsight สร้าง insight จากข้อความใน catalog ตามภาษาของผู้ใช้ และเก็บ key + params ไว้แปลใหม่ภายหลัง
+func newLocalizedInsight(userID uuid.UUID, insightType entity.InsightType, priority entity.InsightPriority, audience i18n.Audience, message i18n.Message) *entity.Insight {
+	rendered := i18n.Render(audience, message)
+
+	insight := entity.NewAdvancedInsight(userID, insightType, priority, rendered.Title, rendered.Content)
+	if rendered.Action != "" {
+		insight.ActionText = &rendered.Action
+	}
+	attachMessage(insight, audience, message)
+
+	return insight
+}
+
+// newNarratedInsight สร้าง insight จากข้อความที่ narrator เขียน
+func newNarratedInsight(userID uuid.UUID, insightType entity.InsightType, priority entity.InsightPriority, audience i18n.Audience, narration *service.Narration) *entity.Insight {
+	insight := entity.NewAdvancedInsight(userID, insightType, priority, narration.Title, narration.Content)
+	if narration.Message.Key != "" {
+		attachMessage(insight, audience, narration.Message)
+	}
+
+	return insight
+}
+
+func attachMessage(insight *entity.Insight, audience i18n.Audience, message i18n.Message) {
+	params, err := json.Marshal(message.Params)
+	if err != nil {
+		return
+	}
+
+	insight.MessageKey = &message.Key
+	insight.MessageParams = params
+	insight.Locale = &audience.Locale
+}
+
+// renderInsight แปล title/content/action ใหม่เมื่อ insight ถูกสร้างด้วยภาษาอื่น
+// insight เก่าที่ไม่มี message key จะแสดงข้อความเดิม
+func renderInsight(insight *entity.Insight, audience i18n.Audience) {
+	if insight.MessageKey == nil {
+		return
+	}
+	if insight.Locale != nil && *insight.Locale == audience.Locale {
+		return
+	}
+
+	message := i18n.Message{Key: *insight.MessageKey}
+	if len(insight.MessageParams) > 0 {
+		if err := json.Unmarshal(insight.MessageParams, &message.Params); err != nil {
+			return
+		}
+	}
+
+	rendered := i18n.Render(audience, message)
+	insight.Title = rendered.Title
+	insight.Content = rendered.Content
+	if rendered.Action != "" {
+		insight.ActionText = &rendered.Action
+	}
+	insight.Locale = &audience.Locale
+}
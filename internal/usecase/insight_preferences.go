@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// ดูคะแนนย้อนหลัง 90 วัน ประเภทที่ได้ "ไม่มีประโยชน์" อย่างน้อย 3 ครั้ง และมากกว่า "มีประโยชน์" 2 เท่า
+	// จะถูกสร้างใหม่ได้ไม่เกินหนึ่งครั้งต่อ 30 วัน
+	insightFeedbackWindow    = 90 * 24 * time.Hour
+	minNotHelpfulToThrottle  = 3
+	lowRatedInsightCooldown  = 30 * 24 * time.Hour
+	notHelpfulToHelpfulRatio = 2
+)
+
+// insightPreferences คือ mute และคะแนน feedback ของผู้ใช้ที่ generator ใช้ตัดสินใจว่าจะสร้าง insight หรือไม่
+type insightPreferences struct {
+	mutedTypes      map[entity.InsightType]bool
+	mutedCategories map[uuid.UUID]bool
+	feedback        map[entity.InsightType]*entity.InsightTypeFeedback
+}
+
+// loadInsightPreferences โหลด mute และคะแนนของผู้ใช้ การ mute หมวดหมู่หลักครอบคลุมหมวดหมู่ย่อยทุกระดับด้วย
+func loadInsightPreferences(ctx context.Context, feedbackRepo repository.InsightFeedbackRepository, categoryRepo repository.CategoryRepository, userID uuid.UUID) (*insightPreferences, error) {
+	prefs := &insightPreferences{
+		mutedTypes:      make(map[entity.InsightType]bool),
+		mutedCategories: make(map[uuid.UUID]bool),
+	}
+
+	mutes, err := feedbackRepo.GetMutes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, mute := range mutes {
+		switch {
+		case mute.Scope == entity.InsightMuteScopeCategory && mute.CategoryID != nil:
+			prefs.mutedCategories[*mute.CategoryID] = true
+			descendantIDs, err := categoryRepo.GetDescendantIDs(ctx, *mute.CategoryID)
+			if err != nil {
+				return nil, err
+			}
+			for _, descendantID := range descendantIDs {
+				prefs.mutedCategories[descendantID] = true
+			}
+		case mute.Scope == entity.InsightMuteScopeInsightType && mute.InsightType != nil:
+			prefs.mutedTypes[*mute.InsightType] = true
+		}
+	}
+
+	prefs.feedback, err = feedbackRepo.GetFeedbackSummary(ctx, userID, time.Now().Add(-insightFeedbackWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	return prefs, nil
+}
+
+func (p *insightPreferences) isCategoryMuted(categoryID uuid.UUID) bool {
+	return p.mutedCategories[categoryID]
+}
+
+func (p *insightPreferences) isTypeMuted(insightType entity.InsightType) bool {
+	return p.mutedTypes[insightType]
+}
+
+func (p *insightPreferences) isLowRated(insightType entity.InsightType) bool {
+	feedback, ok := p.feedback[insightType]
+	if !ok {
+		return false
+	}
+	return feedback.NotHelpful >= minNotHelpfulToThrottle && feedback.NotHelpful > notHelpfulToHelpfulRatio*feedback.Helpful
+}
+
+// allowsType บอกว่ารอบนี้ควรสร้าง insight ประเภทนี้หรือไม่ (ถูก mute หรือยังอยู่ในช่วงพักของประเภทที่คะแนนต่ำ)
+func (a *aiInsightUsecase) allowsType(ctx context.Context, userID uuid.UUID, prefs *insightPreferences, insightType entity.InsightType) bool {
+	if prefs.isTypeMuted(insightType) {
+		return false
+	}
+	if !prefs.isLowRated(insightType) {
+		return true
+	}
+
+	lastGeneratedAt, err := a.insightRepo.GetLastGeneratedAt(ctx, userID, insightType)
+	if err != nil || lastGeneratedAt == nil {
+		return err == nil
+	}
+	return time.Since(*lastGeneratedAt) >= lowRatedInsightCooldown
+}
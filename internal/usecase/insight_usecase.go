@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+	"savvy-backend/internal/i18n"
+
+	"github.com/google/uuid"
+)
+
+// ระยะเวลา snooze สูงสุด เพื่อไม่ให้ insight หายไปจาก inbox ถาวรโดยไม่ตั้งใจ
+const maxInsightSnooze = 90 * 24 * time.Hour
+
+// InsightUsecase จัดการ inbox ของ insight ที่ถูกสร้างไว้แล้ว (การสร้าง insight อยู่ใน AIInsightUsecase)
+type InsightUsecase interface {
+	ListInsights(ctx context.Context, filter repository.InsightFilter) ([]*entity.Insight, int, error)
+	GetInsightByID(ctx context.Context, userID, insightID uuid.UUID) (*entity.Insight, error)
+	LocalizeInsights(ctx context.Context, userID uuid.UUID, locale string, insights []*entity.Insight)
+	GetUnreadCount(ctx context.Context, userID uuid.UUID) (*entity.InsightUnreadCount, error)
+	MarkAsRead(ctx context.Context, userID, insightID uuid.UUID) error
+	MarkAsUnread(ctx context.Context, userID, insightID uuid.UUID) error
+	MarkAllAsRead(ctx context.Context, userID uuid.UUID) error
+	DismissInsight(ctx context.Context, userID, insightID uuid.UUID) error
+	SnoozeInsight(ctx context.Context, userID, insightID uuid.UUID, until time.Time) error
+	PurgeExpiredInsights(ctx context.Context) (int64, error)
+	RateInsight(ctx context.Context, userID, insightID uuid.UUID, rating entity.InsightRating, comment *string) (*entity.InsightFeedback, error)
+	GetMutes(ctx context.Context, userID uuid.UUID) ([]*entity.InsightMute, error)
+	MuteCategory(ctx context.Context, userID, categoryID uuid.UUID) (*entity.InsightMute, error)
+	MuteInsightType(ctx context.Context, userID uuid.UUID, insightType entity.InsightType) (*entity.InsightMute, error)
+	Unmute(ctx context.Context, userID, muteID uuid.UUID) error
+}
+
+type insightUsecase struct {
+	insightRepo  repository.InsightRepository
+	feedbackRepo repository.InsightFeedbackRepository
+	categoryRepo repository.CategoryRepository
+	userRepo     repository.UserRepository
+}
+
+func NewInsightUsecase(
+	insightRepo repository.InsightRepository,
+	feedbackRepo repository.InsightFeedbackRepository,
+	categoryRepo repository.CategoryRepository,
+	userRepo repository.UserRepository,
+) InsightUsecase {
+	return &insightUsecase{
+		insightRepo:  insightRepo,
+		feedbackRepo: feedbackRepo,
+		categoryRepo: categoryRepo,
+		userRepo:     userRepo,
+	}
+}
+
+func (i *insightUsecase) ListInsights(ctx context.Context, filter repository.InsightFilter) ([]*entity.Insight, int, error) {
+	insights, err := i.insightRepo.GetByFilter(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get insights: %w", err)
+	}
+
+	total, err := i.insightRepo.CountByFilter(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count insights: %w", err)
+	}
+
+	return insights, total, nil
+}
+
+func (i *insightUsecase) GetInsightByID(ctx context.Context, userID, insightID uuid.UUID) (*entity.Insight, error) {
+	insight, err := i.insightRepo.GetByID(ctx, insightID)
+	if err != nil {
+		return nil, fmt.Errorf("insight not found")
+	}
+
+	if insight.UserID != userID {
+		return nil, fmt.Errorf("insight does not belong to user")
+	}
+
+	return insight, nil
+}
+
+// LocalizeInsights แปลข้อความของ insight เป็นภาษาที่ขอ (ค่าว่าง = ภาษาที่ผู้ใช้ตั้งไว้)
+// จำนวนเงินแสดงตามสกุลเงินที่ผู้ใช้ตั้งไว้เสมอ
+func (i *insightUsecase) LocalizeInsights(ctx context.Context, userID uuid.UUID, locale string, insights []*entity.Insight) {
+	audience := loadAudience(ctx, i.userRepo, userID)
+	if normalized := i18n.NormalizeLocale(locale); normalized != "" {
+		audience.Locale = normalized
+	}
+
+	for _, insight := range insights {
+		renderInsight(insight, audience)
+	}
+}
+
+func (i *insightUsecase) GetUnreadCount(ctx context.Context, userID uuid.UUID) (*entity.InsightUnreadCount, error) {
+	byType, err := i.insightRepo.GetUnreadCountByType(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread insights: %w", err)
+	}
+
+	count := &entity.InsightUnreadCount{ByType: byType}
+	for _, n := range byType {
+		count.Total += n
+	}
+
+	return count, nil
+}
+
+func (i *insightUsecase) MarkAsRead(ctx context.Context, userID, insightID uuid.UUID) error {
+	if _, err := i.GetInsightByID(ctx, userID, insightID); err != nil {
+		return err
+	}
+	return i.insightRepo.MarkAsRead(ctx, insightID)
+}
+
+func (i *insightUsecase) MarkAsUnread(ctx context.Context, userID, insightID uuid.UUID) error {
+	if _, err := i.GetInsightByID(ctx, userID, insightID); err != nil {
+		return err
+	}
+	return i.insightRepo.MarkAsUnread(ctx, insightID)
+}
+
+func (i *insightUsecase) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
+	return i.insightRepo.MarkAllAsRead(ctx, userID)
+}
+
+func (i *insightUsecase) DismissInsight(ctx context.Context, userID, insightID uuid.UUID) error {
+	if _, err := i.GetInsightByID(ctx, userID, insightID); err != nil {
+		return err
+	}
+	return i.insightRepo.Dismiss(ctx, insightID, time.Now())
+}
+
+func (i *insightUsecase) SnoozeInsight(ctx context.Context, userID, insightID uuid.UUID, until time.Time) error {
+	now := time.Now()
+	if !until.After(now) {
+		return fmt.Errorf("snooze time must be in the future")
+	}
+	if until.Sub(now) > maxInsightSnooze {
+		return fmt.Errorf("snooze cannot exceed %d days", int(maxInsightSnooze.Hours()/24))
+	}
+
+	if _, err := i.GetInsightByID(ctx, userID, insightID); err != nil {
+		return err
+	}
+	return i.insightRepo.Snooze(ctx, insightID, until)
+}
+
+// PurgeExpiredInsights ลบ insight ที่เลย valid_until แล้ว (เรียกจาก scheduled job)
+func (i *insightUsecase) PurgeExpiredInsights(ctx context.Context) (int64, error) {
+	deleted, err := i.insightRepo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired insights: %w", err)
+	}
+	return deleted, nil
+}
+
+// RateInsight บันทึกว่า insight มีประโยชน์หรือไม่ ประเภทที่ได้คะแนนต่ำจะถูกสร้างน้อยลง
+func (i *insightUsecase) RateInsight(ctx context.Context, userID, insightID uuid.UUID, rating entity.InsightRating, comment *string) (*entity.InsightFeedback, error) {
+	if rating != entity.InsightRatingHelpful && rating != entity.InsightRatingNotHelpful {
+		return nil, fmt.Errorf("invalid rating, expected helpful or not_helpful")
+	}
+
+	insight, err := i.GetInsightByID(ctx, userID, insightID)
+	if err != nil {
+		return nil, err
+	}
+
+	feedback := entity.NewInsightFeedback(insight, rating, comment)
+	if err := i.feedbackRepo.SaveFeedback(ctx, feedback); err != nil {
+		return nil, fmt.Errorf("failed to save feedback: %w", err)
+	}
+
+	return feedback, nil
+}
+
+func (i *insightUsecase) GetMutes(ctx context.Context, userID uuid.UUID) ([]*entity.InsightMute, error) {
+	mutes, err := i.feedbackRepo.GetMutes(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mutes: %w", err)
+	}
+	return mutes, nil
+}
+
+func (i *insightUsecase) MuteCategory(ctx context.Context, userID, categoryID uuid.UUID) (*entity.InsightMute, error) {
+	category, err := i.categoryRepo.GetByID(ctx, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("category not found")
+	}
+	if category.UserID != nil && *category.UserID != userID {
+		return nil, fmt.Errorf("category does not belong to user")
+	}
+
+	mute := &entity.InsightMute{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Scope:      entity.InsightMuteScopeCategory,
+		CategoryID: &categoryID,
+		CreatedAt:  time.Now(),
+	}
+	if err := i.feedbackRepo.CreateMute(ctx, mute); err != nil {
+		if errors.Is(err, repository.ErrMuteExists) {
+			return nil, fmt.Errorf("category is already muted")
+		}
+		return nil, fmt.Errorf("failed to mute category: %w", err)
+	}
+
+	return mute, nil
+}
+
+func (i *insightUsecase) MuteInsightType(ctx context.Context, userID uuid.UUID, insightType entity.InsightType) (*entity.InsightMute, error) {
+	if insightType == "" {
+		return nil, fmt.Errorf("insight type is required")
+	}
+	if !insightType.IsValid() {
+		return nil, fmt.Errorf("unknown insight type: %s", insightType)
+	}
+
+	mute := &entity.InsightMute{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Scope:       entity.InsightMuteScopeInsightType,
+		InsightType: &insightType,
+		CreatedAt:   time.Now(),
+	}
+	if err := i.feedbackRepo.CreateMute(ctx, mute); err != nil {
+		if errors.Is(err, repository.ErrMuteExists) {
+			return nil, fmt.Errorf("insight type is already muted")
+		}
+		return nil, fmt.Errorf("failed to mute insight type: %w", err)
+	}
+
+	return mute, nil
+}
+
+func (i *insightUsecase) Unmute(ctx context.Context, userID, muteID uuid.UUID) error {
+	mute, err := i.feedbackRepo.GetMuteByID(ctx, muteID)
+	if err != nil {
+		return fmt.Errorf("mute not found")
+	}
+	if mute.UserID != userID {
+		return fmt.Errorf("mute does not belong to user")
+	}
+
+	return i.feedbackRepo.DeleteMute(ctx, muteID)
+}
+
+// saveInsights บันทึก insight ที่ generator สร้างขึ้นด้วย Upsert (ตาม fingerprint)
+// แล้วปิด (resolve) insight เดิมของประเภทเดียวกันที่ไม่ถูกสร้างซ้ำในรอบนี้ เพราะเงื่อนไขหายไปแล้ว
+func saveInsights(ctx context.Context, insightRepo repository.InsightRepository, userID uuid.UUID, insightTypes []entity.InsightType, insights []*entity.Insight) error {
+	activeFingerprints := make([]string, 0, len(insights))
+	for _, insight := range insights {
+		if insight.Fingerprint != nil {
+			activeFingerprints = append(activeFingerprints, *insight.Fingerprint)
+		}
+
+		if err := insightRepo.Upsert(ctx, insight); err != nil {
+			return fmt.Errorf("failed to save insight: %w", err)
+		}
+	}
+
+	if _, err := insightRepo.ResolveStale(ctx, userID, insightTypes, activeFingerprints, time.Now()); err != nil {
+		return fmt.Errorf("failed to resolve stale insights: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,286 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+	"savvy-backend/internal/domain/service"
+
+	"github.com/google/uuid"
+)
+
+type NetWorthUsecase interface {
+	GetNetWorth(ctx context.Context, userID uuid.UUID, asOf time.Time) (*entity.NetWorthSnapshot, error)
+	GetNetWorthHistory(ctx context.Context, userID uuid.UUID, from, to time.Time, granularity entity.NetWorthGranularity) (*entity.NetWorthHistory, error)
+	RecordSnapshot(ctx context.Context, userID uuid.UUID, date time.Time) (*entity.NetWorthSnapshot, error)
+	RecordAllSnapshots(ctx context.Context, date time.Time) (*entity.NetWorthSnapshotReport, error)
+}
+
+// จำนวนผู้ใช้ที่ดึงจากฐานข้อมูลต่อครั้งตอนบันทึก snapshot ของทุกคน
+const netWorthUserPageSize = 500
+
+// ลำดับประเภทบัญชีใน breakdown: สินทรัพย์ก่อน แล้วตามด้วยหนี้สิน
+var netWorthTypeOrder = []entity.AccountType{
+	entity.AccountTypeCash,
+	entity.AccountTypeBank,
+	entity.AccountTypeSavings,
+	entity.AccountTypeCredit,
+	entity.AccountTypeLoan,
+}
+
+type netWorthUsecase struct {
+	accountRepo   repository.AccountRepository
+	netWorthRepo  repository.NetWorthRepository
+	userRepo      repository.UserRepository
+	responseCache service.ResponseCache
+	concurrency   int
+}
+
+func NewNetWorthUsecase(
+	accountRepo repository.AccountRepository,
+	netWorthRepo repository.NetWorthRepository,
+	userRepo repository.UserRepository,
+	responseCache service.ResponseCache,
+	concurrency int,
+) NetWorthUsecase {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &netWorthUsecase{
+		accountRepo:   accountRepo,
+		netWorthRepo:  netWorthRepo,
+		userRepo:      userRepo,
+		responseCache: responseCache,
+		concurrency:   concurrency,
+	}
+}
+
+// GetNetWorth คำนวณมูลค่าสุทธิ ณ สิ้นวัน asOf จากยอดคงเหลือของทุกบัญชี
+// บัญชีที่สร้างหลังวันนั้นจะไม่ถูกนับ
+func (n *netWorthUsecase) GetNetWorth(ctx context.Context, userID uuid.UUID, asOf time.Time) (*entity.NetWorthSnapshot, error) {
+	snapshotDate := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+
+	accounts, err := n.accountRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+
+	balances, err := n.accountRepo.GetBalancesAsOf(ctx, userID, snapshotDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account balances: %w", err)
+	}
+
+	nextDay := snapshotDate.AddDate(0, 0, 1)
+	byType := make(map[entity.AccountType]*entity.NetWorthTypeBalance)
+	for _, account := range accounts {
+		if !account.CreatedAt.Before(nextDay) {
+			continue
+		}
+
+		typeBalance, ok := byType[account.Type]
+		if !ok {
+			typeBalance = &entity.NetWorthTypeBalance{
+				Type:        account.Type,
+				IsLiability: account.Type.IsLiability(),
+			}
+			byType[account.Type] = typeBalance
+		}
+		typeBalance.Balance = typeBalance.Balance.Add(balances[account.ID])
+		typeBalance.AccountCount++
+	}
+
+	snapshot := &entity.NetWorthSnapshot{
+		ID:           uuid.New(),
+		UserID:       userID,
+		SnapshotDate: snapshotDate,
+		Breakdown:    make([]*entity.NetWorthTypeBalance, 0, len(byType)),
+		CreatedAt:    time.Now(),
+	}
+
+	for _, accountType := range netWorthTypeOrder {
+		typeBalance, ok := byType[accountType]
+		if !ok {
+			continue
+		}
+		delete(byType, accountType)
+		snapshot.Breakdown = append(snapshot.Breakdown, typeBalance)
+	}
+	// ประเภทที่ไม่อยู่ในลำดับ (ถ้ามีเพิ่มในอนาคต) ต่อท้าย
+	for _, typeBalance := range byType {
+		snapshot.Breakdown = append(snapshot.Breakdown, typeBalance)
+	}
+
+	// ยอดของบัญชีหนี้สินติดลบเมื่อมียอดค้าง จึงกลับเครื่องหมายเป็นยอดหนี้สิน
+	for _, typeBalance := range snapshot.Breakdown {
+		if typeBalance.IsLiability {
+			snapshot.TotalLiabilities = snapshot.TotalLiabilities.Sub(typeBalance.Balance)
+		} else {
+			snapshot.TotalAssets = snapshot.TotalAssets.Add(typeBalance.Balance)
+		}
+	}
+	snapshot.NetWorth = snapshot.TotalAssets.Sub(snapshot.TotalLiabilities)
+
+	return snapshot, nil
+}
+
+func (n *netWorthUsecase) GetNetWorthHistory(ctx context.Context, userID uuid.UUID, from, to time.Time, granularity entity.NetWorthGranularity) (*entity.NetWorthHistory, error) {
+	if granularity == "" {
+		granularity = entity.NetWorthGranularityMonth
+	}
+	if granularity != entity.NetWorthGranularityDay && granularity != entity.NetWorthGranularityMonth {
+		return nil, fmt.Errorf("invalid granularity, expected day or month")
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("from must not be after to")
+	}
+
+	snapshots, err := n.netWorthRepo.GetByRange(ctx, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get net worth snapshots: %w", err)
+	}
+
+	if granularity == entity.NetWorthGranularityMonth {
+		snapshots = lastSnapshotPerMonth(snapshots)
+	}
+	if snapshots == nil {
+		snapshots = make([]*entity.NetWorthSnapshot, 0)
+	}
+
+	current, err := n.GetNetWorth(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	history := &entity.NetWorthHistory{
+		From:        from,
+		To:          to,
+		Granularity: granularity,
+		Current:     current,
+		Snapshots:   snapshots,
+	}
+	if len(snapshots) > 0 {
+		change := current.NetWorth.Sub(snapshots[0].NetWorth)
+		history.Change = &change
+	}
+
+	return history, nil
+}
+
+// lastSnapshotPerMonth เหลือ snapshot สุดท้ายของแต่ละเดือน (snapshots เรียงตามวันที่แล้ว)
+func lastSnapshotPerMonth(snapshots []*entity.NetWorthSnapshot) []*entity.NetWorthSnapshot {
+	var result []*entity.NetWorthSnapshot
+	for _, snapshot := range snapshots {
+		if len(result) > 0 {
+			last := result[len(result)-1]
+			if last.SnapshotDate.Year() == snapshot.SnapshotDate.Year() && last.SnapshotDate.Month() == snapshot.SnapshotDate.Month() {
+				result[len(result)-1] = snapshot
+				continue
+			}
+		}
+		result = append(result, snapshot)
+	}
+	return result
+}
+
+func (n *netWorthUsecase) RecordSnapshot(ctx context.Context, userID uuid.UUID, date time.Time) (*entity.NetWorthSnapshot, error) {
+	snapshot, err := n.GetNetWorth(ctx, userID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := n.netWorthRepo.Upsert(ctx, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to save net worth snapshot: %w", err)
+	}
+	invalidateUserCache(ctx, n.responseCache, userID)
+
+	return snapshot, nil
+}
+
+// RecordAllSnapshots บันทึก snapshot ของวันที่ date ให้ผู้ใช้ที่ active ทุกคนแบบขนาน (จำกัดจำนวน worker)
+// เรียกทุกวันสำหรับ snapshot รายวัน หรือทุกสิ้นเดือนสำหรับ snapshot รายเดือน เรียกซ้ำในวันเดียวกันจะแทนที่ของเดิม
+// วันที่ในอนาคตถูกปฏิเสธ เพราะจะกลายเป็นประวัติปลอมที่ไม่มีวันถูกแก้
+func (n *netWorthUsecase) RecordAllSnapshots(ctx context.Context, date time.Time) (*entity.NetWorthSnapshotReport, error) {
+	report := &entity.NetWorthSnapshotReport{
+		SnapshotDate: time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
+		StartedAt:    time.Now(),
+		FailedUsers:  make([]*entity.NetWorthSnapshotFailure, 0),
+	}
+
+	now := time.Now()
+	if report.SnapshotDate.After(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+		report.FinishedAt = time.Now()
+		return report, fmt.Errorf("snapshot date cannot be in the future")
+	}
+
+	userIDs := make(chan uuid.UUID)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < n.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for userID := range userIDs {
+				_, err := n.RecordSnapshot(ctx, userID, date)
+				if err != nil {
+					log.Printf("net worth job: failed to record snapshot for user %s: %v", userID, err)
+				}
+
+				mu.Lock()
+				if err != nil {
+					report.FailedUsers = append(report.FailedUsers, &entity.NetWorthSnapshotFailure{
+						UserID: userID,
+						Error:  err.Error(),
+					})
+				} else {
+					report.SucceededUsers++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var listErr error
+	offset := 0
+	for listErr == nil {
+		page, err := n.userRepo.GetActiveUserIDs(ctx, netWorthUserPageSize, offset)
+		if err != nil {
+			listErr = fmt.Errorf("failed to list active users: %w", err)
+			break
+		}
+
+		for _, userID := range page {
+			select {
+			case userIDs <- userID:
+				report.TotalUsers++
+			case <-ctx.Done():
+				listErr = ctx.Err()
+			}
+			if listErr != nil {
+				break
+			}
+		}
+
+		if len(page) < netWorthUserPageSize {
+			break
+		}
+		offset += len(page)
+	}
+
+	close(userIDs)
+	wg.Wait()
+	report.FinishedAt = time.Now()
+
+	if listErr != nil {
+		log.Printf("net worth job: stopped after %d user(s): %v", report.TotalUsers, listErr)
+		return report, listErr
+	}
+
+	return report, nil
+}
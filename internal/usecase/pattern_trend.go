@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	patternAnalysisWeeks    = 12
+	minPatternTransactions  = 3
+	minPatternActiveWeeks   = 3
+	patternTrendChangeLimit = 0.15 // เปลี่ยนเกิน ±15% ของค่าเฉลี่ยต่อสัปดาห์ถือว่ามีแนวโน้ม
+)
+
+type patternKey struct {
+	categoryID uuid.UUID
+	dayOfWeek  time.Weekday
+	timeOfDay  string
+}
+
+type patternAccumulator struct {
+	count        int
+	total        decimal.Decimal
+	weeklyTotals []float64
+}
+
+// AnalyzeSpendingPatternTrends วิเคราะห์รูปแบบ (หมวดหมู่ + วัน + ช่วงเวลา) ย้อนหลัง 12 สัปดาห์
+// จากเวลาที่ใช้จ่ายจริง หาแนวโน้มจากยอดรายสัปดาห์ แล้วบันทึกแทนผลรอบก่อน
+func (a *aiInsightUsecase) AnalyzeSpendingPatternTrends(ctx context.Context, userID uuid.UUID) ([]*entity.SpendingPatternTrend, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	startDate := today.AddDate(0, 0, -7*patternAnalysisWeeks+1)
+
+	expenseType := entity.TransactionTypeExpense
+	transactions, err := a.transactionRepo.GetByFilter(ctx, repository.TransactionFilter{
+		UserID:    userID,
+		Type:      &expenseType,
+		StartDate: &startDate,
+		EndDate:   &today,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	groups := make(map[patternKey]*patternAccumulator)
+	for _, transaction := range transactions {
+		// รายการที่ไม่มีเวลายังนับในรูปแบบของหมวดหมู่และวัน โดยช่วงเวลาว่าง
+		var timeOfDay string
+		if transaction.TransactionTime != nil {
+			clock, err := time.Parse("15:04", *transaction.TransactionTime)
+			if err != nil {
+				continue
+			}
+			timeOfDay = entity.TimeOfDaySlot(clock.Hour())
+		}
+
+		week := int(transaction.TransactionDate.Sub(startDate).Hours() / 24 / 7)
+		if week < 0 || week >= patternAnalysisWeeks {
+			continue
+		}
+
+		key := patternKey{
+			categoryID: transaction.CategoryID,
+			dayOfWeek:  transaction.TransactionDate.Weekday(),
+			timeOfDay:  timeOfDay,
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &patternAccumulator{weeklyTotals: make([]float64, patternAnalysisWeeks)}
+			groups[key] = group
+		}
+
+		amount, _ := transaction.Amount.Float64()
+		group.count++
+		group.total = group.total.Add(transaction.Amount)
+		group.weeklyTotals[week] += amount
+	}
+
+	categoryNames, err := loadCategoryNames(ctx, a.categoryRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var patterns []*entity.SpendingPatternTrend
+	for key, group := range groups {
+		activeWeeks := 0
+		for _, total := range group.weeklyTotals {
+			if total > 0 {
+				activeWeeks++
+			}
+		}
+		if group.count < minPatternTransactions || activeWeeks < minPatternActiveWeeks {
+			continue
+		}
+
+		trend, change := weeklyTrend(group.weeklyTotals)
+		categoryID := key.categoryID
+
+		patterns = append(patterns, &entity.SpendingPatternTrend{
+			ID:               uuid.New(),
+			UserID:           userID,
+			CategoryID:       &categoryID,
+			CategoryName:     categoryNames[key.categoryID],
+			PatternType:      entity.PatternTypeWeekdayTimeSlot,
+			Frequency:        "weekly",
+			DayOfWeek:        key.dayOfWeek.String(),
+			TimeOfDay:        key.timeOfDay,
+			FrequencyCount:   group.count,
+			AverageAmount:    group.total.Div(decimal.NewFromInt(int64(group.count))).Round(2),
+			Trend:            trend,
+			ChangePercentage: math.Round(change*10000) / 100,
+			ConfidenceScore:  math.Round(float64(activeWeeks)/patternAnalysisWeeks*100) / 100,
+			StartDate:        startDate,
+			EndDate:          today,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		})
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i].ConfidenceScore != patterns[j].ConfidenceScore {
+			return patterns[i].ConfidenceScore > patterns[j].ConfidenceScore
+		}
+		return patterns[i].FrequencyCount > patterns[j].FrequencyCount
+	})
+
+	if err := a.patternRepo.ReplaceForUser(ctx, userID, entity.PatternTypeWeekdayTimeSlot, patterns); err != nil {
+		return nil, fmt.Errorf("failed to save spending patterns: %w", err)
+	}
+
+	return patterns, nil
+}
+
+func (a *aiInsightUsecase) GetSpendingPatternTrends(ctx context.Context, userID uuid.UUID) ([]*entity.SpendingPatternTrend, error) {
+	patterns, err := a.patternRepo.GetByUser(ctx, userID, entity.PatternTypeWeekdayTimeSlot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spending pattern trends: %w", err)
+	}
+
+	return patterns, nil
+}
+
+// weeklyTrend ใช้ least squares กับยอดรายสัปดาห์ แล้ววัดการเปลี่ยนแปลงตลอดช่วงเทียบกับค่าเฉลี่ย
+func weeklyTrend(weeklyTotals []float64) (entity.PatternTrend, float64) {
+	n := float64(len(weeklyTotals))
+	if n < 2 {
+		return entity.PatternTrendStable, 0
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range weeklyTotals {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	mean := sumY / n
+	denominator := n*sumXX - sumX*sumX
+	if mean == 0 || denominator == 0 {
+		return entity.PatternTrendStable, 0
+	}
+
+	slope := (n*sumXY - sumX*sumY) / denominator
+	change := slope * (n - 1) / mean
+
+	switch {
+	case change > patternTrendChangeLimit:
+		return entity.PatternTrendIncreasing, change
+	case change < -patternTrendChangeLimit:
+		return entity.PatternTrendDecreasing, change
+	default:
+		return entity.PatternTrendStable, change
+	}
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"math"
+	"testing"
+
+	"savvy-backend/internal/domain/entity"
+)
+
+func TestWeeklyTrend(t *testing.T) {
+	tests := []struct {
+		name       string
+		totals     []float64
+		wantTrend  entity.PatternTrend
+		wantChange float64
+	}{
+		{name: "no data", totals: nil, wantTrend: entity.PatternTrendStable},
+		{name: "single week", totals: []float64{500}, wantTrend: entity.PatternTrendStable},
+		{name: "all zero", totals: []float64{0, 0, 0, 0}, wantTrend: entity.PatternTrendStable},
+		{name: "flat", totals: []float64{100, 100, 100, 100}, wantTrend: entity.PatternTrendStable},
+		{name: "small noise", totals: []float64{100, 105, 95, 100}, wantTrend: entity.PatternTrendStable, wantChange: -0.03},
+		// slope 50 ต่อสัปดาห์ ตลอด 3 ช่วง = 150 เทียบกับค่าเฉลี่ย 175
+		{name: "increasing", totals: []float64{100, 150, 200, 250}, wantTrend: entity.PatternTrendIncreasing, wantChange: 150.0 / 175},
+		{name: "decreasing", totals: []float64{250, 200, 150, 100}, wantTrend: entity.PatternTrendDecreasing, wantChange: -150.0 / 175},
+		{name: "two weeks", totals: []float64{100, 200}, wantTrend: entity.PatternTrendIncreasing, wantChange: 100.0 / 150},
+		{name: "exactly at limit stays stable", totals: []float64{37, 43}, wantTrend: entity.PatternTrendStable, wantChange: 0.15},
+		{name: "spike in the middle", totals: []float64{100, 400, 100}, wantTrend: entity.PatternTrendStable},
+		{name: "stopped spending", totals: []float64{300, 200, 0, 0}, wantTrend: entity.PatternTrendDecreasing, wantChange: -110.0 * 3 / 125},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trend, change := weeklyTrend(tt.totals)
+			if trend != tt.wantTrend || math.Abs(change-tt.wantChange) > 1e-9 {
+				t.Fatalf("weeklyTrend(%v) = %q, %v; want %q, %v", tt.totals, trend, change, tt.wantTrend, tt.wantChange)
+			}
+		})
+	}
+}
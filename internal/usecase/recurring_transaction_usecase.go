@@ -2,15 +2,24 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"savvy-backend/internal/domain/entity"
 	"savvy-backend/internal/domain/repository"
+	"savvy-backend/internal/domain/service"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
+// ErrCalendarFeedNotFound คือ error เมื่อ token ของ feed ปฏิทินไม่ตรงกับผู้ใช้คนใด
+var ErrCalendarFeedNotFound = repository.ErrCalendarFeedNotFound
+
 type RecurringTransactionUsecase interface {
 	CreateRecurringTransaction(ctx context.Context, userID uuid.UUID, recurring *entity.RecurringTransaction) (*entity.RecurringTransaction, error)
 	GetUserRecurringTransactions(ctx context.Context, userID uuid.UUID) ([]*entity.RecurringTransaction, error)
@@ -18,8 +27,13 @@ type RecurringTransactionUsecase interface {
 	UpdateRecurringTransaction(ctx context.Context, userID uuid.UUID, recurring *entity.RecurringTransaction) error
 	DeleteRecurringTransaction(ctx context.Context, userID, recurringID uuid.UUID) error
 	GetDueTransactions(ctx context.Context, userID uuid.UUID) ([]*entity.RecurringTransaction, error)
-	ExecuteRecurringTransaction(ctx context.Context, userID, recurringID uuid.UUID) (*entity.Transaction, error)
+	ExecuteRecurringTransaction(ctx context.Context, userID, recurringID uuid.UUID, confirmedAmount *decimal.Decimal) (*entity.Transaction, error)
+	EstimateAmount(ctx context.Context, userID, recurringID uuid.UUID) (*entity.RecurringAmountEstimate, error)
 	ProcessAllDueTransactions(ctx context.Context) error
+	GetUpcomingOccurrences(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*entity.RecurringOccurrence, error)
+	RotateCalendarFeedToken(ctx context.Context, userID uuid.UUID) (string, error)
+	RevokeCalendarFeedToken(ctx context.Context, userID uuid.UUID) error
+	GetCalendarFeedOccurrences(ctx context.Context, token string, from, to time.Time) ([]*entity.RecurringOccurrence, error)
 }
 
 type recurringTransactionUsecase struct {
@@ -27,6 +41,8 @@ type recurringTransactionUsecase struct {
 	transactionRepo repository.TransactionRepository
 	categoryRepo    repository.CategoryRepository
 	accountRepo     repository.AccountRepository
+	userRepo        repository.UserRepository
+	responseCache   service.ResponseCache
 }
 
 func NewRecurringTransactionUsecase(
@@ -34,12 +50,16 @@ func NewRecurringTransactionUsecase(
 	transactionRepo repository.TransactionRepository,
 	categoryRepo repository.CategoryRepository,
 	accountRepo repository.AccountRepository,
+	userRepo repository.UserRepository,
+	responseCache service.ResponseCache,
 ) RecurringTransactionUsecase {
 	return &recurringTransactionUsecase{
 		recurringRepo:   recurringRepo,
 		transactionRepo: transactionRepo,
 		categoryRepo:    categoryRepo,
 		accountRepo:     accountRepo,
+		userRepo:        userRepo,
+		responseCache:   responseCache,
 	}
 }
 
@@ -56,6 +76,9 @@ func (r *recurringTransactionUsecase) CreateRecurringTransaction(ctx context.Con
 	if category.UserID != nil && *category.UserID != userID {
 		return nil, fmt.Errorf("category does not belong to user")
 	}
+	if category.IsArchived {
+		return nil, fmt.Errorf("category is archived")
+	}
 
 	// Validate account belongs to user
 	account, err := r.accountRepo.GetByID(ctx, recurring.AccountID)
@@ -67,10 +90,16 @@ func (r *recurringTransactionUsecase) CreateRecurringTransaction(ctx context.Con
 		return nil, fmt.Errorf("account does not belong to user")
 	}
 
+	if err := validateRecurringAmountMode(recurring); err != nil {
+		return nil, err
+	}
+
 	err = r.recurringRepo.Create(ctx, recurring)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create recurring transaction: %w", err)
 	}
+	// กฎรายการประจำมีผลกับยอดคาดการณ์ใน budget progress
+	invalidateUserCache(ctx, r.responseCache, userID)
 
 	return recurring, nil
 }
@@ -106,8 +135,17 @@ func (r *recurringTransactionUsecase) UpdateRecurringTransaction(ctx context.Con
 		return fmt.Errorf("recurring transaction does not belong to user")
 	}
 
+	if err := validateRecurringAmountMode(recurring); err != nil {
+		return err
+	}
+
 	recurring.UserID = userID // Ensure user ID is preserved
-	return r.recurringRepo.Update(ctx, recurring)
+	if err := r.recurringRepo.Update(ctx, recurring); err != nil {
+		return err
+	}
+
+	invalidateUserCache(ctx, r.responseCache, userID)
+	return nil
 }
 
 func (r *recurringTransactionUsecase) DeleteRecurringTransaction(ctx context.Context, userID, recurringID uuid.UUID) error {
@@ -120,7 +158,12 @@ func (r *recurringTransactionUsecase) DeleteRecurringTransaction(ctx context.Con
 		return fmt.Errorf("recurring transaction does not belong to user")
 	}
 
-	return r.recurringRepo.Delete(ctx, recurringID)
+	if err := r.recurringRepo.Delete(ctx, recurringID); err != nil {
+		return err
+	}
+
+	invalidateUserCache(ctx, r.responseCache, userID)
+	return nil
 }
 
 func (r *recurringTransactionUsecase) GetDueTransactions(ctx context.Context, userID uuid.UUID) ([]*entity.RecurringTransaction, error) {
@@ -134,7 +177,8 @@ func (r *recurringTransactionUsecase) GetDueTransactions(ctx context.Context, us
 	return r.recurringRepo.GetByFilter(ctx, filter)
 }
 
-func (r *recurringTransactionUsecase) ExecuteRecurringTransaction(ctx context.Context, userID, recurringID uuid.UUID) (*entity.Transaction, error) {
+// ExecuteRecurringTransaction สร้างรายการจริงจากกฎ สำหรับกฎที่ยอดไม่คงที่ต้องส่งยอดจริง (confirmedAmount) มาด้วย
+func (r *recurringTransactionUsecase) ExecuteRecurringTransaction(ctx context.Context, userID, recurringID uuid.UUID, confirmedAmount *decimal.Decimal) (*entity.Transaction, error) {
 	recurring, err := r.GetRecurringTransactionByID(ctx, userID, recurringID)
 	if err != nil {
 		return nil, err
@@ -148,13 +192,28 @@ func (r *recurringTransactionUsecase) ExecuteRecurringTransaction(ctx context.Co
 		return nil, fmt.Errorf("transaction is not due yet")
 	}
 
+	estimate, err := estimateRecurringAmount(ctx, r.recurringRepo, recurring)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := recurring.Amount
+	if confirmedAmount != nil {
+		if !confirmedAmount.IsPositive() {
+			return nil, fmt.Errorf("confirmed amount must be greater than zero")
+		}
+		amount = *confirmedAmount
+	} else if recurring.IsVariableAmount() {
+		return nil, fmt.Errorf("amount confirmation is required for variable-amount recurring transactions")
+	}
+
 	// Create the actual transaction
 	transaction := &entity.Transaction{
 		ID:              uuid.New(),
 		UserID:          recurring.UserID,
 		CategoryID:      recurring.CategoryID,
 		AccountID:       recurring.AccountID,
-		Amount:          recurring.Amount,
+		Amount:          amount,
 		Type:            recurring.Type,
 		Note:            recurring.Note,
 		TransactionDate: time.Now(),
@@ -162,16 +221,14 @@ func (r *recurringTransactionUsecase) ExecuteRecurringTransaction(ctx context.Co
 		UpdatedAt:       time.Now(),
 	}
 
-	err = r.transactionRepo.Create(ctx, transaction)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
-	}
-
-	// Update recurring transaction
 	executedAt := time.Now()
-	err = r.recurringRepo.MarkAsExecuted(ctx, recurringID, executedAt)
-	if err != nil {
-		return nil, fmt.Errorf("failed to mark as executed: %w", err)
+	execution := &entity.RecurringExecution{
+		ID:                     uuid.New(),
+		RecurringTransactionID: recurring.ID,
+		TransactionID:          transaction.ID,
+		EstimatedAmount:        estimate.Amount,
+		ActualAmount:           amount,
+		ExecutedAt:             executedAt,
 	}
 
 	// Calculate next execution date
@@ -191,11 +248,17 @@ func (r *recurringTransactionUsecase) ExecuteRecurringTransaction(ctx context.Co
 	} else {
 		recurring.NextExecutionDate = nextDate
 	}
+	recurring.LastExecutionDate = &executedAt
+	if recurring.RemainingExecutions != nil {
+		remaining := *recurring.RemainingExecutions - 1
+		recurring.RemainingExecutions = &remaining
+	}
 
-	err = r.recurringRepo.Update(ctx, recurring)
-	if err != nil {
-		return nil, fmt.Errorf("failed to update recurring transaction: %w", err)
+	// สร้างรายการ อัปเดตกฎ และบันทึกประวัติใน transaction เดียว ป้องกันรายการซ้ำเมื่อขั้นใดขั้นหนึ่งล้มเหลว
+	if err := r.recurringRepo.Execute(ctx, recurring, transaction, execution); err != nil {
+		return nil, err
 	}
+	invalidateUserCache(ctx, r.responseCache, userID)
 
 	return transaction, nil
 }
@@ -210,8 +273,9 @@ func (r *recurringTransactionUsecase) ProcessAllDueTransactions(ctx context.Cont
 
 	var errors []error
 	for _, recurring := range dueTransactions {
-		if recurring.AutoExecute {
-			_, err := r.ExecuteRecurringTransaction(ctx, recurring.UserID, recurring.ID)
+		// Variable-amount rules always wait for the user to confirm the real amount
+		if recurring.AutoExecute && !recurring.IsVariableAmount() {
+			_, err := r.ExecuteRecurringTransaction(ctx, recurring.UserID, recurring.ID, nil)
 			if err != nil {
 				errors = append(errors, fmt.Errorf("failed to execute recurring transaction %s: %w", recurring.ID, err))
 			}
@@ -224,3 +288,158 @@ func (r *recurringTransactionUsecase) ProcessAllDueTransactions(ctx context.Cont
 
 	return nil
 }
+
+func (r *recurringTransactionUsecase) EstimateAmount(ctx context.Context, userID, recurringID uuid.UUID) (*entity.RecurringAmountEstimate, error) {
+	recurring, err := r.GetRecurringTransactionByID(ctx, userID, recurringID)
+	if err != nil {
+		return nil, err
+	}
+
+	return estimateRecurringAmount(ctx, r.recurringRepo, recurring)
+}
+
+func (r *recurringTransactionUsecase) GetUpcomingOccurrences(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*entity.RecurringOccurrence, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("end of range must not be before start")
+	}
+
+	isActive := true
+	rules, err := r.recurringRepo.GetByFilter(ctx, repository.RecurringTransactionFilter{
+		UserID:   userID,
+		IsActive: &isActive,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recurring transactions: %w", err)
+	}
+
+	occurrences := make([]*entity.RecurringOccurrence, 0)
+	if len(rules) == 0 {
+		return occurrences, nil
+	}
+
+	categoryNames, err := loadCategoryNames(ctx, r.categoryRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		dates := rule.OccurrencesBetween(from, to)
+		if len(dates) == 0 {
+			continue
+		}
+
+		estimate, err := estimateRecurringAmount(ctx, r.recurringRepo, rule)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, date := range dates {
+			occurrence := &entity.RecurringOccurrence{
+				RecurringTransactionID: rule.ID,
+				CategoryID:             rule.CategoryID,
+				CategoryName:           categoryNames[rule.CategoryID],
+				AccountID:              rule.AccountID,
+				Date:                   date,
+				Amount:                 estimate.Amount,
+				Type:                   rule.Type,
+				Frequency:              rule.Frequency,
+				Note:                   rule.Note,
+				IsEstimate:             estimate.IsEstimate,
+			}
+			if estimate.IsEstimate {
+				occurrence.MinAmount = &estimate.MinAmount
+				occurrence.MaxAmount = &estimate.MaxAmount
+			}
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Date.Before(occurrences[j].Date)
+	})
+
+	return occurrences, nil
+}
+
+func (r *recurringTransactionUsecase) RotateCalendarFeedToken(ctx context.Context, userID uuid.UUID) (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate calendar feed token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	err := r.userRepo.UpdateCalendarFeedToken(ctx, userID, token)
+	if err != nil {
+		return "", fmt.Errorf("failed to save calendar feed token: %w", err)
+	}
+
+	return token, nil
+}
+
+func (r *recurringTransactionUsecase) RevokeCalendarFeedToken(ctx context.Context, userID uuid.UUID) error {
+	if err := r.userRepo.ClearCalendarFeedToken(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke calendar feed token: %w", err)
+	}
+	return nil
+}
+
+func (r *recurringTransactionUsecase) GetCalendarFeedOccurrences(ctx context.Context, token string, from, to time.Time) ([]*entity.RecurringOccurrence, error) {
+	if token == "" {
+		return nil, ErrCalendarFeedNotFound
+	}
+
+	user, err := r.userRepo.GetByCalendarFeedToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, ErrCalendarFeedNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+
+	return r.GetUpcomingOccurrences(ctx, user.ID, from, to)
+}
+
+// estimateRecurringAmount ดึงยอดจริงล่าสุดมาประมาณยอดครั้งถัดไป ใช้ร่วมกันทั้งปฏิทินบิลและการคาดการณ์งบประมาณ
+func estimateRecurringAmount(ctx context.Context, recurringRepo repository.RecurringTransactionRepository, recurring *entity.RecurringTransaction) (*entity.RecurringAmountEstimate, error) {
+	var recent []decimal.Decimal
+	if recurring.AmountMode == entity.RecurringAmountModeAverage {
+		window := entity.DefaultEstimateWindow
+		if recurring.EstimateWindow != nil && *recurring.EstimateWindow > 0 {
+			window = *recurring.EstimateWindow
+		}
+
+		amounts, err := recurringRepo.GetRecentExecutionAmounts(ctx, recurring.ID, window)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get execution history: %w", err)
+		}
+		recent = amounts
+	}
+
+	estimate := recurring.EstimateAmount(recent)
+	return &estimate, nil
+}
+
+func validateRecurringAmountMode(recurring *entity.RecurringTransaction) error {
+	if recurring.AmountMode == "" {
+		recurring.AmountMode = entity.RecurringAmountModeFixed
+	}
+
+	switch recurring.AmountMode {
+	case entity.RecurringAmountModeFixed, entity.RecurringAmountModeAverage:
+	case entity.RecurringAmountModeRange:
+		if recurring.MinAmount == nil || recurring.MaxAmount == nil {
+			return fmt.Errorf("min_amount and max_amount are required for range amount mode")
+		}
+		if !recurring.MinAmount.IsPositive() || recurring.MaxAmount.LessThan(*recurring.MinAmount) {
+			return fmt.Errorf("invalid amount range")
+		}
+	default:
+		return fmt.Errorf("invalid amount mode: %s", recurring.AmountMode)
+	}
+
+	if recurring.IsVariableAmount() && recurring.AutoExecute {
+		return fmt.Errorf("variable-amount recurring transactions require manual approval")
+	}
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package usecase
+
+import (
+	"context"
+	"log"
+
+	"savvy-backend/internal/domain/service"
+
+	"github.com/google/uuid"
+)
+
+// invalidateUserCache ล้าง response cache ของผู้ใช้หลังแก้ไขข้อมูล ถ้าล้มเหลวจะแค่ log ไว้
+// เพราะข้อมูลถูกบันทึกไปแล้ว และ response เก่าจะหมดอายุเองตาม TTL
+func invalidateUserCache(ctx context.Context, cache service.ResponseCache, userID uuid.UUID) {
+	if err := cache.InvalidateUser(ctx, userID); err != nil {
+		log.Printf("failed to invalidate response cache for user %s: %v", userID, err)
+	}
+}
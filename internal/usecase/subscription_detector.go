@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"savvy-backend/internal/domain/entity"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// อัตราการเปลี่ยนแปลงของยอดที่ยังถือว่า "คงที่" เมื่อเทียบกับค่ามัธยฐาน
+	subscriptionAmountTolerance = 0.2
+	// ราคาต้องสูงขึ้นอย่างน้อยเท่านี้จึงจะแจ้งเตือนว่าขึ้นราคา
+	subscriptionPriceIncreaseThreshold = 0.05
+)
+
+type subscriptionInterval struct {
+	frequency      entity.RecurringFrequency
+	minDays        float64
+	maxDays        float64
+	minOccurrences int
+}
+
+// ช่วงห่าง (วัน) ที่ยอมรับได้ของแต่ละความถี่ รายปีต้องการแค่ 2 ครั้งเพราะย้อนดูประวัติได้ไม่ถึง 3 ปี
+var subscriptionIntervals = []subscriptionInterval{
+	{entity.RecurringFrequencyWeekly, 6, 8, 3},
+	{entity.RecurringFrequencyMonthly, 26, 35, 3},
+	{entity.RecurringFrequencyYearly, 350, 380, 2},
+}
+
+// detectSubscriptions หารายจ่ายที่มี note คล้ายกัน ยอดคงที่ และเกิดเป็นรอบสม่ำเสมอ
+// ซึ่งยังไม่มี RecurringTransaction รองรับ พร้อมตรวจการขึ้นราคาของ subscription ที่รู้จักแล้ว
+func detectSubscriptions(transactions []*entity.Transaction, rules []*entity.RecurringTransaction) ([]*entity.SubscriptionCandidate, []*entity.SubscriptionPriceIncrease) {
+	groups := make(map[string][]*entity.Transaction)
+	for _, transaction := range transactions {
+		if transaction.Type != entity.TransactionTypeExpense || transaction.Note == nil {
+			continue
+		}
+		key := normalizeMerchantNote(*transaction.Note)
+		if key == "" {
+			continue
+		}
+		groups[key] = append(groups[key], transaction)
+	}
+
+	rulesByNote := make(map[string]*entity.RecurringTransaction)
+	for _, rule := range rules {
+		if rule.Type != entity.TransactionTypeExpense || rule.Note == nil {
+			continue
+		}
+		if key := normalizeMerchantNote(*rule.Note); key != "" {
+			rulesByNote[key] = rule
+		}
+	}
+
+	var candidates []*entity.SubscriptionCandidate
+	var increases []*entity.SubscriptionPriceIncrease
+
+	for key, group := range groups {
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].TransactionDate.Before(group[j].TransactionDate)
+		})
+		latest := group[len(group)-1]
+
+		// subscription ที่มีกฎแล้ว: ตรวจเพียงว่ารายการล่าสุดแพงกว่ายอดในกฎหรือไม่
+		// กฎที่ยอดไม่คงที่ (average/range) ข้ามไป เพราะยอดเปลี่ยนทุกรอบอยู่แล้ว
+		if rule, known := rulesByNote[key]; known {
+			if rule.IsVariableAmount() {
+				continue
+			}
+			if increase := priceIncrease(rule.Amount, latest.Amount); increase > 0 {
+				ruleID := rule.ID
+				increases = append(increases, &entity.SubscriptionPriceIncrease{
+					RecurringTransactionID: &ruleID,
+					Note:                   *latest.Note,
+					CategoryID:             latest.CategoryID,
+					PreviousAmount:         rule.Amount,
+					NewAmount:              latest.Amount,
+					PercentageIncrease:     increase,
+					ChargedAt:              latest.TransactionDate,
+				})
+			}
+			continue
+		}
+
+		frequency, ok := classifyChargeInterval(group)
+		if !ok {
+			continue
+		}
+
+		amounts := make([]decimal.Decimal, len(group))
+		for i, transaction := range group {
+			amounts[i] = transaction.Amount
+		}
+		typical := medianDecimal(amounts)
+		if !amountsStable(amounts[:len(amounts)-1], typical) {
+			continue
+		}
+
+		if coveredByRule(latest, typical, frequency, rules) {
+			continue
+		}
+
+		candidates = append(candidates, &entity.SubscriptionCandidate{
+			Note:             *latest.Note,
+			CategoryID:       latest.CategoryID,
+			AccountID:        latest.AccountID,
+			Frequency:        frequency,
+			Amount:           typical,
+			LatestAmount:     latest.Amount,
+			Occurrences:      len(group),
+			LastChargedAt:    latest.TransactionDate,
+			NextExpectedDate: advanceSubscriptionDate(latest.TransactionDate, frequency),
+		})
+
+		// subscription ที่ยังไม่มีกฎ และรายการล่าสุดแพงขึ้นกว่าราคาปกติ
+		previous := group[len(group)-2]
+		if increase := priceIncrease(previous.Amount, latest.Amount); increase > 0 {
+			increases = append(increases, &entity.SubscriptionPriceIncrease{
+				Note:               *latest.Note,
+				CategoryID:         latest.CategoryID,
+				PreviousAmount:     previous.Amount,
+				NewAmount:          latest.Amount,
+				PercentageIncrease: increase,
+				ChargedAt:          latest.TransactionDate,
+			})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Amount.GreaterThan(candidates[j].Amount)
+	})
+
+	return candidates, increases
+}
+
+// normalizeMerchantNote ตัดตัวเลข เครื่องหมาย และช่องว่างซ้ำ เพื่อให้ "Netflix 08/2024" กับ "NETFLIX" เป็นกลุ่มเดียวกัน
+func normalizeMerchantNote(note string) string {
+	var b strings.Builder
+	lastSpace := true
+	for _, r := range strings.ToLower(note) {
+		switch {
+		case unicode.IsLetter(r) || unicode.Is(unicode.Mn, r):
+			b.WriteRune(r)
+			lastSpace = false
+		case !lastSpace:
+			b.WriteRune(' ')
+			lastSpace = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func classifyChargeInterval(group []*entity.Transaction) (entity.RecurringFrequency, bool) {
+	if len(group) < 2 {
+		return "", false
+	}
+
+	intervals := make([]float64, 0, len(group)-1)
+	for i := 1; i < len(group); i++ {
+		intervals = append(intervals, group[i].TransactionDate.Sub(group[i-1].TransactionDate).Hours()/24)
+	}
+
+	for _, candidate := range subscriptionIntervals {
+		if len(group) < candidate.minOccurrences {
+			continue
+		}
+		regular := true
+		for _, days := range intervals {
+			if days < candidate.minDays || days > candidate.maxDays {
+				regular = false
+				break
+			}
+		}
+		if regular {
+			return candidate.frequency, true
+		}
+	}
+
+	return "", false
+}
+
+func amountsStable(amounts []decimal.Decimal, typical decimal.Decimal) bool {
+	if typical.IsZero() {
+		return false
+	}
+	tolerance := typical.Mul(decimal.NewFromFloat(subscriptionAmountTolerance))
+	for _, amount := range amounts {
+		if amount.Sub(typical).Abs().GreaterThan(tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+// coveredByRule จับคู่กับกฎที่ note ต่างกันแต่เป็นหมวดหมู่ ความถี่ และยอดใกล้เคียงกัน
+func coveredByRule(latest *entity.Transaction, typical decimal.Decimal, frequency entity.RecurringFrequency, rules []*entity.RecurringTransaction) bool {
+	tolerance := typical.Mul(decimal.NewFromFloat(subscriptionAmountTolerance))
+	for _, rule := range rules {
+		if rule.Type != entity.TransactionTypeExpense || rule.CategoryID != latest.CategoryID || rule.Frequency != frequency {
+			continue
+		}
+		if rule.Amount.Sub(typical).Abs().LessThanOrEqual(tolerance) {
+			return true
+		}
+	}
+	return false
+}
+
+// priceIncrease คืนเปอร์เซ็นต์ที่เพิ่มขึ้น หรือ 0 ถ้าเพิ่มไม่ถึงเกณฑ์
+func priceIncrease(previous, current decimal.Decimal) float64 {
+	if !previous.IsPositive() || !current.GreaterThan(previous) {
+		return 0
+	}
+	ratio, _ := current.Sub(previous).Div(previous).Float64()
+	if ratio < subscriptionPriceIncreaseThreshold {
+		return 0
+	}
+	return ratio * 100
+}
+
+func medianDecimal(values []decimal.Decimal) decimal.Decimal {
+	if len(values) == 0 {
+		return decimal.Zero
+	}
+	sorted := make([]decimal.Decimal, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].LessThan(sorted[j]) })
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return sorted[mid-1].Add(sorted[mid]).Div(decimal.NewFromInt(2))
+}
+
+func advanceSubscriptionDate(date time.Time, frequency entity.RecurringFrequency) time.Time {
+	rule := entity.RecurringTransaction{NextExecutionDate: date, Frequency: frequency}
+	return rule.CalculateNextExecutionDate()
+}
@@ -0,0 +1,258 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func testDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+type testCharge struct {
+	note   string
+	amount int64
+	date   time.Time
+}
+
+func testCharges(categoryID uuid.UUID, charges ...testCharge) []*entity.Transaction {
+	transactions := make([]*entity.Transaction, 0, len(charges))
+	for _, charge := range charges {
+		note := charge.note
+		transactions = append(transactions, &entity.Transaction{
+			ID:              uuid.New(),
+			CategoryID:      categoryID,
+			Amount:          decimal.NewFromInt(charge.amount),
+			Type:            entity.TransactionTypeExpense,
+			Note:            &note,
+			TransactionDate: charge.date,
+		})
+	}
+	return transactions
+}
+
+// chargesEvery สร้างรายการยอดตาม amounts ห่างกันทีละ gaps วัน (gaps สั้นกว่า amounts หนึ่งตัว)
+func chargesEvery(note string, start time.Time, gaps []int, amounts ...int64) []testCharge {
+	charges := make([]testCharge, 0, len(amounts))
+	date := start
+	for i, amount := range amounts {
+		if i > 0 {
+			date = date.AddDate(0, 0, gaps[i-1])
+		}
+		charges = append(charges, testCharge{note: note, amount: amount, date: date})
+	}
+	return charges
+}
+
+func TestClassifyChargeInterval(t *testing.T) {
+	start := testDate(2026, time.January, 5)
+	categoryID := uuid.New()
+
+	tests := []struct {
+		name          string
+		gaps          []int
+		wantFrequency entity.RecurringFrequency
+		wantOK        bool
+	}{
+		{name: "single charge", gaps: nil},
+		{name: "weekly", gaps: []int{7, 7, 7}, wantFrequency: entity.RecurringFrequencyWeekly, wantOK: true},
+		{name: "weekly with a late charge", gaps: []int{6, 8, 7}, wantFrequency: entity.RecurringFrequencyWeekly, wantOK: true},
+		{name: "weekly needs three charges", gaps: []int{7}},
+		{name: "monthly calendar months", gaps: []int{31, 28, 31}, wantFrequency: entity.RecurringFrequencyMonthly, wantOK: true},
+		{name: "monthly at range edges", gaps: []int{26, 35}, wantFrequency: entity.RecurringFrequencyMonthly, wantOK: true},
+		{name: "monthly needs three charges", gaps: []int{30}},
+		{name: "monthly gap too short", gaps: []int{30, 25}},
+		{name: "monthly gap too long", gaps: []int{30, 36}},
+		{name: "skipped month", gaps: []int{30, 61, 30}},
+		{name: "irregular", gaps: []int{3, 17, 40}},
+		{name: "between weekly and monthly", gaps: []int{14, 14, 14}},
+		{name: "yearly", gaps: []int{365}, wantFrequency: entity.RecurringFrequencyYearly, wantOK: true},
+		{name: "yearly leap year", gaps: []int{366, 365}, wantFrequency: entity.RecurringFrequencyYearly, wantOK: true},
+		{name: "yearly gap too long", gaps: []int{400}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amounts := make([]int64, len(tt.gaps)+1)
+			for i := range amounts {
+				amounts[i] = 100
+			}
+			group := testCharges(categoryID, chargesEvery("Netflix", start, tt.gaps, amounts...)...)
+
+			frequency, ok := classifyChargeInterval(group)
+			if ok != tt.wantOK || frequency != tt.wantFrequency {
+				t.Fatalf("classifyChargeInterval() = %q, %v; want %q, %v", frequency, ok, tt.wantFrequency, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDetectSubscriptions(t *testing.T) {
+	start := testDate(2026, time.January, 10)
+	monthly := []int{31, 28, 31, 30}
+	categoryID := uuid.New()
+
+	ruleNote := "Netflix"
+	knownRule := &entity.RecurringTransaction{
+		ID:         uuid.New(),
+		CategoryID: categoryID,
+		Type:       entity.TransactionTypeExpense,
+		Note:       &ruleNote,
+		Amount:     decimal.NewFromInt(419),
+		Frequency:  entity.RecurringFrequencyMonthly,
+	}
+	utilityNote := "Electric"
+	variableRule := &entity.RecurringTransaction{
+		ID:         uuid.New(),
+		CategoryID: categoryID,
+		Type:       entity.TransactionTypeExpense,
+		Note:       &utilityNote,
+		Amount:     decimal.NewFromInt(100),
+		AmountMode: entity.RecurringAmountModeAverage,
+		Frequency:  entity.RecurringFrequencyMonthly,
+	}
+	otherNote := "Streaming"
+	similarRule := &entity.RecurringTransaction{
+		ID:         uuid.New(),
+		CategoryID: categoryID,
+		Type:       entity.TransactionTypeExpense,
+		Note:       &otherNote,
+		Amount:     decimal.NewFromInt(430),
+		Frequency:  entity.RecurringFrequencyMonthly,
+	}
+
+	tests := []struct {
+		name           string
+		charges        []testCharge
+		rules          []*entity.RecurringTransaction
+		wantCandidate  bool
+		wantFrequency  entity.RecurringFrequency
+		wantAmount     int64
+		wantIncreases  int
+		wantIncreaseOf int64
+	}{
+		{
+			name: "stable monthly charges with noisy notes",
+			charges: []testCharge{
+				{note: "NETFLIX.COM 01/2026", amount: 419, date: testDate(2026, time.January, 10)},
+				{note: "Netflix.com 02/2026", amount: 419, date: testDate(2026, time.February, 10)},
+				{note: "netflix com", amount: 419, date: testDate(2026, time.March, 10)},
+				{note: "Netflix.com #4411", amount: 419, date: testDate(2026, time.April, 10)},
+			},
+			wantCandidate: true,
+			wantFrequency: entity.RecurringFrequencyMonthly,
+			wantAmount:    419,
+		},
+		{
+			name:          "drift within tolerance",
+			charges:       chargesEvery("Electric", start, monthly, 80, 100, 120, 100, 100),
+			wantCandidate: true,
+			wantFrequency: entity.RecurringFrequencyMonthly,
+			wantAmount:    100,
+		},
+		{
+			name:    "drift beyond tolerance",
+			charges: chargesEvery("Electric", start, monthly, 70, 100, 135, 100, 100),
+		},
+		{
+			name:          "latest charge is excluded from the stability check",
+			charges:       chargesEvery("Spotify", start, monthly, 150, 150, 150, 150, 190),
+			wantCandidate: true,
+			wantFrequency: entity.RecurringFrequencyMonthly,
+			wantAmount:    150,
+			wantIncreases: 1, wantIncreaseOf: 190,
+		},
+		{
+			name:          "increase below threshold",
+			charges:       chargesEvery("Spotify", start, monthly, 150, 150, 150, 150, 155),
+			wantCandidate: true,
+			wantFrequency: entity.RecurringFrequencyMonthly,
+			wantAmount:    150,
+		},
+		{
+			name:    "irregular intervals",
+			charges: chargesEvery("Grab", start, []int{2, 9, 30, 4}, 120, 120, 120, 120, 120),
+		},
+		{
+			name:          "known rule only reports a price increase",
+			charges:       chargesEvery("Netflix", start, monthly, 419, 419, 419, 419, 459),
+			rules:         []*entity.RecurringTransaction{knownRule},
+			wantIncreases: 1, wantIncreaseOf: 459,
+		},
+		{
+			name:    "known rule at the same price",
+			charges: chargesEvery("Netflix", start, monthly, 419, 419, 419, 419, 419),
+			rules:   []*entity.RecurringTransaction{knownRule},
+		},
+		{
+			name:    "known variable-amount rule ignores a higher bill",
+			charges: chargesEvery("Electric", start, monthly, 90, 110, 100, 95, 140),
+			rules:   []*entity.RecurringTransaction{variableRule},
+		},
+		{
+			name:    "covered by a rule with a different note",
+			charges: chargesEvery("Disney Plus", start, monthly, 420, 420, 420, 420, 420),
+			rules:   []*entity.RecurringTransaction{similarRule},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates, increases := detectSubscriptions(testCharges(categoryID, tt.charges...), tt.rules)
+
+			if !tt.wantCandidate {
+				if len(candidates) != 0 {
+					t.Fatalf("detectSubscriptions() candidates = %+v, want none", candidates[0])
+				}
+			} else {
+				if len(candidates) != 1 {
+					t.Fatalf("detectSubscriptions() returned %d candidate(s), want 1", len(candidates))
+				}
+				candidate := candidates[0]
+				if candidate.Frequency != tt.wantFrequency {
+					t.Errorf("Frequency = %q, want %q", candidate.Frequency, tt.wantFrequency)
+				}
+				if !candidate.Amount.Equal(decimal.NewFromInt(tt.wantAmount)) {
+					t.Errorf("Amount = %s, want %d", candidate.Amount, tt.wantAmount)
+				}
+				if candidate.Occurrences != len(tt.charges) {
+					t.Errorf("Occurrences = %d, want %d", candidate.Occurrences, len(tt.charges))
+				}
+				last := tt.charges[len(tt.charges)-1]
+				if !candidate.LastChargedAt.Equal(last.date) || !candidate.NextExpectedDate.After(last.date) {
+					t.Errorf("LastChargedAt = %s, NextExpectedDate = %s; want last charge %s and a later next date",
+						candidate.LastChargedAt, candidate.NextExpectedDate, last.date)
+				}
+			}
+
+			if len(increases) != tt.wantIncreases {
+				t.Fatalf("detectSubscriptions() returned %d price increase(s), want %d", len(increases), tt.wantIncreases)
+			}
+			if tt.wantIncreases > 0 && !increases[0].NewAmount.Equal(decimal.NewFromInt(tt.wantIncreaseOf)) {
+				t.Errorf("NewAmount = %s, want %d", increases[0].NewAmount, tt.wantIncreaseOf)
+			}
+		})
+	}
+}
+
+func TestDetectSubscriptionsIgnoresIncomeAndMissingNotes(t *testing.T) {
+	categoryID := uuid.New()
+	transactions := testCharges(categoryID, chargesEvery("Salary", testDate(2026, time.January, 25), []int{28, 31, 30}, 50000, 50000, 50000, 50000)...)
+	for _, transaction := range transactions {
+		transaction.Type = entity.TransactionTypeIncome
+	}
+	withoutNotes := testCharges(categoryID, chargesEvery("", testDate(2026, time.January, 1), []int{31, 28, 31}, 99, 99, 99, 99)...)
+	for _, transaction := range withoutNotes {
+		transaction.Note = nil
+	}
+
+	candidates, increases := detectSubscriptions(append(transactions, withoutNotes...), nil)
+	if len(candidates) != 0 || len(increases) != 0 {
+		t.Fatalf("detectSubscriptions() = %d candidate(s), %d increase(s); want none", len(candidates), len(increases))
+	}
+}
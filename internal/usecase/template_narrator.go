@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/service"
+	"savvy-backend/internal/i18n"
+)
+
+// templateNarrator สร้างข้อความ insight จาก message catalog
+// ใช้เป็นค่าเริ่มต้นและเป็น fallback เมื่อ LLM provider ใช้งานไม่ได้
+type templateNarrator struct{}
+
+func NewTemplateNarrator() service.InsightNarrator {
+	return &templateNarrator{}
+}
+
+func (t *templateNarrator) NarrateAnomaly(ctx context.Context, audience i18n.Audience, anomaly *entity.SpendingAnomaly) (*service.Narration, error) {
+	return narrate(audience, i18n.AnomalyMessage(anomaly)), nil
+}
+
+func (t *templateNarrator) NarratePattern(ctx context.Context, audience i18n.Audience, pattern *entity.SpendingPattern) (*service.Narration, error) {
+	return narrate(audience, i18n.PatternMessage(pattern)), nil
+}
+
+func (t *templateNarrator) NarrateBudgetAlert(ctx context.Context, audience i18n.Audience, progress *entity.BudgetProgress) (*service.Narration, error) {
+	return narrate(audience, i18n.BudgetAlertMessage(progress)), nil
+}
+
+func narrate(audience i18n.Audience, message i18n.Message) *service.Narration {
+	rendered := i18n.Render(audience, message)
+	return &service.Narration{
+		Title:   rendered.Title,
+		Content: rendered.Content,
+		Message: message,
+	}
+}
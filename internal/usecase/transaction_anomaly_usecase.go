@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// เกณฑ์ของ Iglewicz & Hoaglin: |modified z| > 3.5 ถือเป็น outlier
+	anomalyZThreshold         = 3.5
+	anomalyZMedium            = 5.0
+	anomalyZHigh              = 8.0
+	anomalyHistoryDays        = 180
+	anomalyHistoryLimit       = 1000
+	minCategoryAnomalySamples = 8
+	minMerchantAnomalySamples = 5
+)
+
+// TransactionAnomalyUsecase ให้ผู้ใช้ตรวจสอบรายการที่ถูกตรวจพบว่ายอดผิดปกติ
+// (การตรวจจับเกิดตอนสร้างรายการใน TransactionUsecase)
+type TransactionAnomalyUsecase interface {
+	ListAnomalies(ctx context.Context, filter repository.SpendingAnomalyFilter) ([]*entity.TransactionAnomaly, int, error)
+	GetAnomaly(ctx context.Context, userID, anomalyID uuid.UUID) (*entity.TransactionAnomaly, error)
+	ReviewAnomaly(ctx context.Context, userID, anomalyID uuid.UUID, status entity.AnomalyReviewStatus) (*entity.TransactionAnomaly, error)
+}
+
+type transactionAnomalyUsecase struct {
+	anomalyRepo repository.SpendingAnomalyRepository
+}
+
+func NewTransactionAnomalyUsecase(anomalyRepo repository.SpendingAnomalyRepository) TransactionAnomalyUsecase {
+	return &transactionAnomalyUsecase{
+		anomalyRepo: anomalyRepo,
+	}
+}
+
+func (t *transactionAnomalyUsecase) ListAnomalies(ctx context.Context, filter repository.SpendingAnomalyFilter) ([]*entity.TransactionAnomaly, int, error) {
+	anomalies, err := t.anomalyRepo.GetByFilter(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get anomalies: %w", err)
+	}
+
+	total, err := t.anomalyRepo.CountByFilter(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count anomalies: %w", err)
+	}
+
+	return anomalies, total, nil
+}
+
+func (t *transactionAnomalyUsecase) GetAnomaly(ctx context.Context, userID, anomalyID uuid.UUID) (*entity.TransactionAnomaly, error) {
+	anomaly, err := t.anomalyRepo.GetByID(ctx, anomalyID)
+	if err != nil {
+		return nil, fmt.Errorf("anomaly not found")
+	}
+
+	if anomaly.UserID != userID {
+		return nil, fmt.Errorf("anomaly does not belong to user")
+	}
+
+	return anomaly, nil
+}
+
+func (t *transactionAnomalyUsecase) ReviewAnomaly(ctx context.Context, userID, anomalyID uuid.UUID, status entity.AnomalyReviewStatus) (*entity.TransactionAnomaly, error) {
+	if status != entity.AnomalyReviewPending && status != entity.AnomalyReviewConfirmed && status != entity.AnomalyReviewDismissed {
+		return nil, fmt.Errorf("invalid review status, expected pending, confirmed or dismissed")
+	}
+
+	anomaly, err := t.GetAnomaly(ctx, userID, anomalyID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := t.anomalyRepo.UpdateReviewStatus(ctx, anomalyID, status, now); err != nil {
+		return nil, fmt.Errorf("failed to update anomaly review: %w", err)
+	}
+
+	anomaly.ReviewStatus = status
+	anomaly.ReviewedAt = &now
+	return anomaly, nil
+}
+
+// scoreTransaction ให้คะแนนความผิดปกติของรายจ่ายใหม่ด้วย robust z-score (median/MAD)
+// เทียบกับประวัติในหมวดหมู่เดียวกัน และกับร้านค้า/โน้ตเดียวกัน แล้วบันทึกรายการที่เกินเกณฑ์
+func scoreTransaction(ctx context.Context, transactionRepo repository.TransactionRepository, anomalyRepo repository.SpendingAnomalyRepository, transaction *entity.Transaction, categoryName string) ([]*entity.TransactionAnomaly, error) {
+	if transaction.Type != entity.TransactionTypeExpense {
+		return nil, nil
+	}
+
+	expenseType := entity.TransactionTypeExpense
+	since := time.Now().AddDate(0, 0, -anomalyHistoryDays)
+	history, err := transactionRepo.GetByFilter(ctx, repository.TransactionFilter{
+		UserID:    transaction.UserID,
+		Type:      &expenseType,
+		StartDate: &since,
+		Limit:     anomalyHistoryLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction history: %w", err)
+	}
+
+	merchant := ""
+	if transaction.Note != nil {
+		merchant = normalizeMerchantNote(*transaction.Note)
+	}
+
+	var categoryAmounts, merchantAmounts []float64
+	for _, past := range history {
+		if past.ID == transaction.ID {
+			continue
+		}
+		amount, _ := past.Amount.Float64()
+		if past.CategoryID == transaction.CategoryID {
+			categoryAmounts = append(categoryAmounts, amount)
+		}
+		if merchant != "" && past.Note != nil && normalizeMerchantNote(*past.Note) == merchant {
+			merchantAmounts = append(merchantAmounts, amount)
+		}
+	}
+
+	var anomalies []*entity.TransactionAnomaly
+
+	if len(categoryAmounts) >= minCategoryAnomalySamples {
+		if anomaly := buildTransactionAnomaly(transaction, entity.TransactionAnomalyCategoryAmount, categoryAmounts); anomaly != nil {
+			description := fmt.Sprintf("ยอด %s บาท สูงกว่าปกติของหมวด %s (ปกติ %s - %s บาท)",
+				anomaly.ActualAmount.StringFixed(2), categoryName,
+				anomaly.ExpectedRangeMin.StringFixed(2), anomaly.ExpectedRangeMax.StringFixed(2))
+			anomaly.Description = &description
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+
+	if len(merchantAmounts) >= minMerchantAnomalySamples {
+		if anomaly := buildTransactionAnomaly(transaction, entity.TransactionAnomalyMerchantAmount, merchantAmounts); anomaly != nil {
+			description := fmt.Sprintf("ยอด %s บาท สูงกว่าปกติของ '%s' (ปกติ %s - %s บาท)",
+				anomaly.ActualAmount.StringFixed(2), *transaction.Note,
+				anomaly.ExpectedRangeMin.StringFixed(2), anomaly.ExpectedRangeMax.StringFixed(2))
+			anomaly.Description = &description
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+
+	for _, anomaly := range anomalies {
+		if err := anomalyRepo.Create(ctx, anomaly); err != nil {
+			return nil, fmt.Errorf("failed to save anomaly: %w", err)
+		}
+	}
+
+	return anomalies, nil
+}
+
+// buildTransactionAnomaly คืนค่า nil ถ้ายอดไม่สูงผิดปกติ (สนใจเฉพาะการใช้จ่ายที่สูงกว่าปกติ)
+func buildTransactionAnomaly(transaction *entity.Transaction, anomalyType entity.TransactionAnomalyType, samples []float64) *entity.TransactionAnomaly {
+	amount, _ := transaction.Amount.Float64()
+
+	median, mad := medianAbsoluteDeviation(samples)
+	if mad == 0 || amount <= median {
+		return nil
+	}
+
+	zScore := 0.6745 * (amount - median) / mad
+	if zScore <= anomalyZThreshold {
+		return nil
+	}
+
+	severity := "low"
+	switch {
+	case zScore >= anomalyZHigh:
+		severity = "high"
+	case zScore >= anomalyZMedium:
+		severity = "medium"
+	}
+
+	// ช่วงที่ยังถือว่าปกติ คือ |z| <= threshold
+	spread := anomalyZThreshold * mad / 0.6745
+
+	anomaly := entity.NewTransactionAnomaly(transaction, anomalyType)
+	anomaly.Severity = severity
+	anomaly.RobustZScore = math.Round(zScore*100) / 100
+	anomaly.SampleSize = len(samples)
+	anomaly.ExpectedRangeMin = decimal.NewFromFloat(math.Max(0, median-spread)).Round(2)
+	anomaly.ExpectedRangeMax = decimal.NewFromFloat(median + spread).Round(2)
+	if median > 0 {
+		anomaly.DeviationPercentage = math.Round((amount-median)/median*10000) / 100
+	}
+
+	return anomaly
+}
+
+// medianAbsoluteDeviation คืนค่า median และ MAD ของข้อมูล
+// ถ้า MAD เป็น 0 (ยอดส่วนใหญ่เท่ากัน) จะใช้ค่าเบี่ยงเบนสัมบูรณ์เฉลี่ยที่ปรับสเกลแล้วแทน
+func medianAbsoluteDeviation(samples []float64) (float64, float64) {
+	median := medianOf(samples)
+
+	deviations := make([]float64, len(samples))
+	var sumDeviation float64
+	for i, sample := range samples {
+		deviations[i] = math.Abs(sample - median)
+		sumDeviation += deviations[i]
+	}
+
+	mad := medianOf(deviations)
+	if mad == 0 {
+		// 1.2533 = sqrt(pi/2) ทำให้ mean absolute deviation เทียบเท่า MAD ของการแจกแจงปกติ
+		mad = 1.2533 * sumDeviation / float64(len(samples)) * 0.6745
+	}
+
+	return median, mad
+}
+
+func medianOf(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
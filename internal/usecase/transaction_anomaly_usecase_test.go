@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"math"
+	"testing"
+
+	"savvy-backend/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestMedianAbsoluteDeviation(t *testing.T) {
+	tests := []struct {
+		name       string
+		samples    []float64
+		wantMedian float64
+		wantMAD    float64
+	}{
+		{name: "single sample", samples: []float64{42}, wantMedian: 42, wantMAD: 0},
+		{name: "odd count with outlier", samples: []float64{4, 1, 100, 3, 2}, wantMedian: 3, wantMAD: 1},
+		{name: "even count", samples: []float64{40, 10, 30, 20}, wantMedian: 25, wantMAD: 10},
+		{name: "all equal", samples: []float64{5, 5, 5, 5}, wantMedian: 5, wantMAD: 0},
+		{
+			// MAD เป็น 0 จึงใช้ mean absolute deviation ที่ปรับสเกลแล้ว: 1.2533 * (100 / 5) * 0.6745
+			name:       "mostly equal falls back to mean deviation",
+			samples:    []float64{100, 100, 100, 100, 200},
+			wantMedian: 100,
+			wantMAD:    1.2533 * 20 * 0.6745,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			median, mad := medianAbsoluteDeviation(tt.samples)
+			if math.Abs(median-tt.wantMedian) > 1e-9 || math.Abs(mad-tt.wantMAD) > 1e-9 {
+				t.Fatalf("medianAbsoluteDeviation() = %v, %v; want %v, %v", median, mad, tt.wantMedian, tt.wantMAD)
+			}
+		})
+	}
+}
+
+func TestBuildTransactionAnomaly(t *testing.T) {
+	// median 100, MAD 2.5 ดังนั้น z = 0.6745 * (amount - 100) / 2.5 และช่วงปกติคือ 100 ± 12.97
+	samples := []float64{100, 110, 90, 105, 95, 100, 100, 100}
+
+	tests := []struct {
+		name          string
+		amount        string
+		samples       []float64
+		wantAnomaly   bool
+		wantSeverity  string
+		wantZScore    float64
+		wantDeviation float64
+	}{
+		{name: "below median", amount: "90", samples: samples},
+		{name: "at median", amount: "100", samples: samples},
+		{name: "within threshold", amount: "112", samples: samples},
+		{name: "low", amount: "115", samples: samples, wantAnomaly: true, wantSeverity: "low", wantZScore: 4.05, wantDeviation: 15},
+		{name: "medium", amount: "120", samples: samples, wantAnomaly: true, wantSeverity: "medium", wantZScore: 5.4, wantDeviation: 20},
+		{name: "high", amount: "130", samples: samples, wantAnomaly: true, wantSeverity: "high", wantZScore: 8.09, wantDeviation: 30},
+		{name: "identical history", amount: "500", samples: []float64{100, 100, 100, 100, 100, 100, 100, 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := &entity.Transaction{
+				ID:         uuid.New(),
+				UserID:     uuid.New(),
+				CategoryID: uuid.New(),
+				Amount:     decimal.RequireFromString(tt.amount),
+				Type:       entity.TransactionTypeExpense,
+			}
+
+			anomaly := buildTransactionAnomaly(transaction, entity.TransactionAnomalyCategoryAmount, tt.samples)
+			if !tt.wantAnomaly {
+				if anomaly != nil {
+					t.Fatalf("buildTransactionAnomaly() = %+v, want nil", anomaly)
+				}
+				return
+			}
+			if anomaly == nil {
+				t.Fatal("buildTransactionAnomaly() = nil, want anomaly")
+			}
+
+			if anomaly.Severity != tt.wantSeverity {
+				t.Errorf("Severity = %q, want %q", anomaly.Severity, tt.wantSeverity)
+			}
+			if anomaly.RobustZScore != tt.wantZScore {
+				t.Errorf("RobustZScore = %v, want %v", anomaly.RobustZScore, tt.wantZScore)
+			}
+			if anomaly.DeviationPercentage != tt.wantDeviation {
+				t.Errorf("DeviationPercentage = %v, want %v", anomaly.DeviationPercentage, tt.wantDeviation)
+			}
+			if anomaly.SampleSize != len(tt.samples) {
+				t.Errorf("SampleSize = %d, want %d", anomaly.SampleSize, len(tt.samples))
+			}
+			if !anomaly.ExpectedRangeMin.Equal(decimal.RequireFromString("87.03")) || !anomaly.ExpectedRangeMax.Equal(decimal.RequireFromString("112.97")) {
+				t.Errorf("expected range = %s-%s, want 87.03-112.97", anomaly.ExpectedRangeMin, anomaly.ExpectedRangeMax)
+			}
+			if anomaly.TransactionID != transaction.ID || anomaly.AnomalyType != entity.TransactionAnomalyCategoryAmount {
+				t.Errorf("anomaly = %+v, want it linked to the transaction", anomaly)
+			}
+		})
+	}
+}
+
+func TestBuildTransactionAnomalyClampsRangeAtZero(t *testing.T) {
+	// ยอดเล็กที่กระจายตัวมาก ช่วงล่างที่คำนวณได้ติดลบต้องถูกปัดเป็น 0
+	samples := []float64{1, 2, 20, 40, 60, 5, 3, 10}
+	transaction := &entity.Transaction{ID: uuid.New(), CategoryID: uuid.New(), Amount: decimal.NewFromInt(5000)}
+
+	anomaly := buildTransactionAnomaly(transaction, entity.TransactionAnomalyMerchantAmount, samples)
+	if anomaly == nil {
+		t.Fatal("buildTransactionAnomaly() = nil, want anomaly")
+	}
+	if !anomaly.ExpectedRangeMin.IsZero() {
+		t.Fatalf("ExpectedRangeMin = %s, want 0", anomaly.ExpectedRangeMin)
+	}
+}
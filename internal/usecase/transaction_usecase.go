@@ -9,17 +9,19 @@ import (
 
 	"savvy-backend/internal/domain/entity"
 	"savvy-backend/internal/domain/repository"
+	"savvy-backend/internal/domain/service"
 	"savvy-backend/pkg/utils"
 )
 
 type TransactionUsecase interface {
 	CreateTransaction(ctx context.Context, userID, categoryID, accountID uuid.UUID,
 		amount decimal.Decimal, transactionType entity.TransactionType,
-		note *string, transactionDate string) (*entity.Transaction, error)
+		note *string, transactionDate string, transactionTime *string) (*entity.Transaction, error)
 	GetTransactionsByFilter(ctx context.Context, filter repository.TransactionFilter) ([]*entity.Transaction, error)
 	GetTransactionByID(ctx context.Context, userID, transactionID uuid.UUID) (*entity.Transaction, error)
 	UpdateTransaction(ctx context.Context, userID uuid.UUID, transaction *entity.Transaction) error
 	DeleteTransaction(ctx context.Context, userID, transactionID uuid.UUID) error
+	RecategorizeTransactions(ctx context.Context, filter repository.TransactionFilter, categoryID uuid.UUID) (int64, error)
 	GetMonthlyReport(ctx context.Context, userID uuid.UUID, year, month int) (map[string]interface{}, error)
 }
 
@@ -27,23 +29,32 @@ type transactionUsecase struct {
 	transactionRepo repository.TransactionRepository
 	accountRepo     repository.AccountRepository
 	categoryRepo    repository.CategoryRepository
+	anomalyRepo     repository.SpendingAnomalyRepository
+	feedbackRepo    repository.InsightFeedbackRepository
+	responseCache   service.ResponseCache
 }
 
 func NewTransactionUsecase(
 	transactionRepo repository.TransactionRepository,
 	accountRepo repository.AccountRepository,
 	categoryRepo repository.CategoryRepository,
+	anomalyRepo repository.SpendingAnomalyRepository,
+	feedbackRepo repository.InsightFeedbackRepository,
+	responseCache service.ResponseCache,
 ) TransactionUsecase {
 	return &transactionUsecase{
 		transactionRepo: transactionRepo,
 		accountRepo:     accountRepo,
 		categoryRepo:    categoryRepo,
+		anomalyRepo:     anomalyRepo,
+		feedbackRepo:    feedbackRepo,
+		responseCache:   responseCache,
 	}
 }
 
 func (t *transactionUsecase) CreateTransaction(ctx context.Context, userID, categoryID, accountID uuid.UUID,
 	amount decimal.Decimal, transactionType entity.TransactionType,
-	note *string, transactionDate string) (*entity.Transaction, error) {
+	note *string, transactionDate string, transactionTime *string) (*entity.Transaction, error) {
 
 	// Validate account belongs to user
 	account, err := t.accountRepo.GetByID(ctx, accountID)
@@ -62,6 +73,9 @@ func (t *transactionUsecase) CreateTransaction(ctx context.Context, userID, cate
 	if category.UserID != nil && *category.UserID != userID {
 		return nil, errors.New("category does not belong to user")
 	}
+	if category.IsArchived {
+		return nil, errors.New("category is archived")
+	}
 
 	// Parse transaction date
 	parsedDate, err := utils.ParseDate(transactionDate)
@@ -72,10 +86,37 @@ func (t *transactionUsecase) CreateTransaction(ctx context.Context, userID, cate
 	// Create transaction
 	transaction := entity.NewTransaction(userID, categoryID, accountID, amount, transactionType, note, parsedDate)
 
+	// เวลาที่ใช้จ่ายจริง ถ้าไม่ระบุแต่วันที่ส่งมาพร้อมเวลา ให้ใช้เวลานั้น
+	switch {
+	case transactionTime != nil:
+		clock, err := utils.ParseClockTime(*transactionTime)
+		if err != nil {
+			return nil, errors.New("invalid transaction time format, expected HH:MM")
+		}
+		transaction.TransactionTime = &clock
+	case parsedDate.Hour() != 0 || parsedDate.Minute() != 0:
+		clock := parsedDate.Format("15:04")
+		transaction.TransactionTime = &clock
+	}
+
 	err = t.transactionRepo.Create(ctx, transaction)
 	if err != nil {
 		return nil, err
 	}
+	invalidateUserCache(ctx, t.responseCache, userID)
+
+	// Score the new expense against history unless the user muted anomalies for it;
+	// a scoring failure must not fail the transaction
+	prefs, err := loadInsightPreferences(ctx, t.feedbackRepo, t.categoryRepo, userID)
+	if err == nil && !prefs.isTypeMuted(entity.InsightTypeAnomalyDetection) && !prefs.isCategoryMuted(categoryID) {
+		// ใช้ชื่อหมวดหมู่ที่ผู้ใช้ตั้งเอง (override) ถ้าเป็นหมวดหมู่ระบบ
+		if category.UserID == nil {
+			if override, err := t.categoryRepo.GetOverride(ctx, userID, categoryID); err == nil {
+				category = override.Apply(category)
+			}
+		}
+		_, _ = scoreTransaction(ctx, t.transactionRepo, t.anomalyRepo, transaction, category.Name)
+	}
 
 	return transaction, nil
 }
@@ -103,7 +144,12 @@ func (t *transactionUsecase) UpdateTransaction(ctx context.Context, userID uuid.
 		return errors.New("transaction does not belong to user")
 	}
 
-	return t.transactionRepo.Update(ctx, transaction)
+	if err := t.transactionRepo.Update(ctx, transaction); err != nil {
+		return err
+	}
+
+	invalidateUserCache(ctx, t.responseCache, userID)
+	return nil
 }
 
 func (t *transactionUsecase) DeleteTransaction(ctx context.Context, userID, transactionID uuid.UUID) error {
@@ -116,7 +162,51 @@ func (t *transactionUsecase) DeleteTransaction(ctx context.Context, userID, tran
 		return errors.New("transaction does not belong to user")
 	}
 
-	return t.transactionRepo.Delete(ctx, transactionID)
+	if err := t.transactionRepo.Delete(ctx, transactionID); err != nil {
+		return err
+	}
+
+	invalidateUserCache(ctx, t.responseCache, userID)
+	return nil
+}
+
+// RecategorizeTransactions ย้ายทุกรายการที่ตรงกับ filter ไปหมวดหมู่ categoryID
+// ต้องระบุเงื่อนไขอย่างน้อยหนึ่งอย่างเพื่อกันการย้ายทุกรายการโดยไม่ตั้งใจ และย้ายเฉพาะรายการที่ประเภทตรงกับหมวดหมู่
+func (t *transactionUsecase) RecategorizeTransactions(ctx context.Context, filter repository.TransactionFilter, categoryID uuid.UUID) (int64, error) {
+	if filter.AccountID == nil && filter.CategoryID == nil && filter.Type == nil &&
+		filter.StartDate == nil && filter.EndDate == nil && filter.SearchQuery == nil &&
+		filter.MinAmount == nil && filter.MaxAmount == nil {
+		return 0, errors.New("at least one filter is required")
+	}
+
+	category, err := t.categoryRepo.GetByID(ctx, categoryID)
+	if err != nil {
+		return 0, errors.New("category not found")
+	}
+	if category.UserID != nil && *category.UserID != filter.UserID {
+		return 0, errors.New("category does not belong to user")
+	}
+	if category.IsArchived {
+		return 0, errors.New("category is archived")
+	}
+
+	categoryType := entity.TransactionType(category.Type)
+	if filter.Type != nil && *filter.Type != categoryType {
+		return 0, errors.New("transaction type does not match category type")
+	}
+	filter.Type = &categoryType
+	filter.Limit = 0
+	filter.Offset = 0
+
+	updated, err := t.transactionRepo.UpdateCategoryByFilter(ctx, filter, categoryID)
+	if err != nil {
+		return 0, err
+	}
+	if updated > 0 {
+		invalidateUserCache(ctx, t.responseCache, filter.UserID)
+	}
+
+	return updated, nil
 }
 
 func (t *transactionUsecase) GetMonthlyReport(ctx context.Context, userID uuid.UUID, year, month int) (map[string]interface{}, error) {
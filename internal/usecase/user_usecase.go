@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+	"savvy-backend/internal/domain/service"
+	"savvy-backend/internal/i18n"
+
+	"github.com/google/uuid"
+)
+
+// UserUsecase จัดการการตั้งค่าของผู้ใช้ เช่น ภาษาและสกุลเงินที่ใช้แสดง insight
+type UserUsecase interface {
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*entity.User, error)
+	UpdatePreferences(ctx context.Context, userID uuid.UUID, locale, currency *string) (*entity.User, error)
+}
+
+type userUsecase struct {
+	userRepo      repository.UserRepository
+	responseCache service.ResponseCache
+}
+
+func NewUserUsecase(userRepo repository.UserRepository, responseCache service.ResponseCache) UserUsecase {
+	return &userUsecase{
+		userRepo:      userRepo,
+		responseCache: responseCache,
+	}
+}
+
+func (u *userUsecase) GetPreferences(ctx context.Context, userID uuid.UUID) (*entity.User, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	return user, nil
+}
+
+func (u *userUsecase) UpdatePreferences(ctx context.Context, userID uuid.UUID, locale, currency *string) (*entity.User, error) {
+	user, err := u.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if locale != nil {
+		normalized := i18n.NormalizeLocale(*locale)
+		if normalized == "" {
+			return nil, fmt.Errorf("unsupported locale, expected one of %s", strings.Join(i18n.SupportedLocales, ", "))
+		}
+		user.Locale = normalized
+	}
+
+	if currency != nil {
+		code := strings.ToUpper(strings.TrimSpace(*currency))
+		if !isCurrencyCode(code) {
+			return nil, fmt.Errorf("currency must be a 3-letter ISO 4217 code")
+		}
+		user.CurrencyPreference = code
+	}
+
+	if err := u.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update preferences: %w", err)
+	}
+
+	// response ที่ cache ไว้ถูกแปลและจัดรูปแบบเงินตามภาษา/สกุลเงินเดิม
+	invalidateUserCache(ctx, u.responseCache, userID)
+
+	return user, nil
+}
+
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
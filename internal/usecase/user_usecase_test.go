@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"savvy-backend/internal/domain/entity"
+	"savvy-backend/internal/domain/repository"
+	"savvy-backend/internal/domain/service"
+
+	"github.com/google/uuid"
+)
+
+// preferencesUserRepo แทนที่เฉพาะเมธอดที่ UpdatePreferences ใช้
+type preferencesUserRepo struct {
+	repository.UserRepository
+	user      *entity.User
+	updateErr error
+}
+
+func (r *preferencesUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	copied := *r.user
+	return &copied, nil
+}
+
+func (r *preferencesUserRepo) Update(ctx context.Context, user *entity.User) error {
+	return r.updateErr
+}
+
+type countingCache struct {
+	service.ResponseCache
+	invalidated []uuid.UUID
+}
+
+func (c *countingCache) InvalidateUser(ctx context.Context, userID uuid.UUID) error {
+	c.invalidated = append(c.invalidated, userID)
+	return nil
+}
+
+func TestUpdatePreferencesInvalidatesResponseCache(t *testing.T) {
+	str := func(v string) *string { return &v }
+
+	tests := []struct {
+		name           string
+		locale         *string
+		currency       *string
+		updateErr      error
+		wantErr        bool
+		wantInvalidate bool
+	}{
+		{name: "locale changed", locale: str("en"), wantInvalidate: true},
+		{name: "currency changed", currency: str("usd"), wantInvalidate: true},
+		{name: "unsupported locale", locale: str("xx"), wantErr: true},
+		{name: "invalid currency", currency: str("baht"), wantErr: true},
+		{name: "update failed", locale: str("en"), updateErr: errors.New("db down"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.New()
+			repo := &preferencesUserRepo{
+				user:      &entity.User{ID: userID, Locale: "th", CurrencyPreference: "THB"},
+				updateErr: tt.updateErr,
+			}
+			cache := &countingCache{}
+
+			_, err := NewUserUsecase(repo, cache).UpdatePreferences(context.Background(), userID, tt.locale, tt.currency)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdatePreferences() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			invalidated := len(cache.invalidated) == 1 && cache.invalidated[0] == userID
+			if invalidated != tt.wantInvalidate || len(cache.invalidated) > 1 {
+				t.Fatalf("invalidated = %v, want invalidate %v for %s", cache.invalidated, tt.wantInvalidate, userID)
+			}
+		})
+	}
+}
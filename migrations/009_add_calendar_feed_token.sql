@@ -0,0 +1,11 @@
+-- Migration: Add calendar feed token to users
+-- Description: Secret per-user token for subscribing to upcoming bills via an iCalendar (.ics) feed
+
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS calendar_feed_token VARCHAR(64);
+
+-- Tokens are looked up on every feed refresh, so keep them unique and indexed
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_calendar_feed_token
+    ON users(calendar_feed_token) WHERE calendar_feed_token IS NOT NULL;
+
+COMMENT ON COLUMN users.calendar_feed_token IS 'Secret token for the public iCalendar feed of upcoming recurring transactions (NULL = feed disabled)';
@@ -0,0 +1,35 @@
+-- Migration: Variable-amount recurring transactions
+-- Description: Estimated amounts (average of last N executions or min/max range) and execution history
+
+ALTER TABLE recurring_transactions
+ADD COLUMN IF NOT EXISTS amount_mode VARCHAR(10) NOT NULL DEFAULT 'fixed'
+    CHECK (amount_mode IN ('fixed', 'average', 'range')),
+ADD COLUMN IF NOT EXISTS estimate_window INTEGER NULL CHECK (estimate_window IS NULL OR estimate_window > 0),
+ADD COLUMN IF NOT EXISTS min_amount DECIMAL(15,2) NULL CHECK (min_amount IS NULL OR min_amount > 0),
+ADD COLUMN IF NOT EXISTS max_amount DECIMAL(15,2) NULL CHECK (max_amount IS NULL OR max_amount > 0);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'check_recurring_amount_range') THEN
+        ALTER TABLE recurring_transactions
+        ADD CONSTRAINT check_recurring_amount_range
+            CHECK (min_amount IS NULL OR max_amount IS NULL OR max_amount >= min_amount);
+    END IF;
+END $$;
+
+-- ยอดจริงของแต่ละครั้งที่รัน ใช้หาค่าเฉลี่ยของ N ครั้งล่าสุด
+CREATE TABLE IF NOT EXISTS recurring_transaction_executions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recurring_transaction_id UUID NOT NULL REFERENCES recurring_transactions(id) ON DELETE CASCADE,
+    transaction_id UUID NOT NULL UNIQUE REFERENCES transactions(id) ON DELETE CASCADE,
+    estimated_amount DECIMAL(15,2) NOT NULL,
+    actual_amount DECIMAL(15,2) NOT NULL CHECK (actual_amount > 0),
+    executed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_executions_recent
+    ON recurring_transaction_executions(recurring_transaction_id, executed_at DESC);
+
+COMMENT ON COLUMN recurring_transactions.amount_mode IS 'fixed = amount as-is, average = mean of last estimate_window executions, range = between min_amount and max_amount';
+COMMENT ON COLUMN recurring_transactions.amount IS 'Fixed amount, or the initial estimate for variable-amount rules with no execution history';
+COMMENT ON TABLE recurring_transaction_executions IS 'Confirmed amounts of executed recurring transactions';
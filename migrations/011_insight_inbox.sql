@@ -0,0 +1,25 @@
+-- Migration: Insight inbox
+-- Description: Align insights table with the Insight entity and add dismiss/snooze support
+
+-- insight types are defined in code and grow with new generators, keep them as plain text
+ALTER TABLE insights ALTER COLUMN type TYPE VARCHAR(50) USING type::text;
+
+ALTER TABLE insights
+ADD COLUMN IF NOT EXISTS title VARCHAR(255) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS action_text TEXT,
+ADD COLUMN IF NOT EXISTS related_entity_id UUID,
+ADD COLUMN IF NOT EXISTS related_entity_type VARCHAR(50),
+ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+ADD COLUMN IF NOT EXISTS dismissed_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS snoozed_until TIMESTAMP WITH TIME ZONE;
+
+-- Inbox listing: newest visible insights per user
+CREATE INDEX IF NOT EXISTS idx_insights_inbox
+    ON insights(user_id, created_at DESC) WHERE dismissed_at IS NULL;
+
+DROP TRIGGER IF EXISTS update_insights_updated_at ON insights;
+CREATE TRIGGER update_insights_updated_at BEFORE UPDATE ON insights
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON COLUMN insights.dismissed_at IS 'When the user dismissed the insight (hidden from the inbox)';
+COMMENT ON COLUMN insights.snoozed_until IS 'Insight is hidden from the inbox until this time';
@@ -0,0 +1,16 @@
+-- Migration: Insight deduplication and lifecycle
+-- Description: Deterministic fingerprint per insight so regeneration updates instead of duplicating,
+--              and resolved_at for insights whose underlying condition has disappeared
+
+ALTER TABLE insights
+ADD COLUMN IF NOT EXISTS fingerprint VARCHAR(64),
+ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMP WITH TIME ZONE;
+
+-- NULL fingerprints (one-off insights) are never considered duplicates
+CREATE UNIQUE INDEX IF NOT EXISTS idx_insights_user_fingerprint ON insights(user_id, fingerprint);
+
+CREATE INDEX IF NOT EXISTS idx_insights_unresolved
+    ON insights(user_id, type) WHERE resolved_at IS NULL AND fingerprint IS NOT NULL;
+
+COMMENT ON COLUMN insights.fingerprint IS 'sha256(type + related entity + period), unique per user';
+COMMENT ON COLUMN insights.resolved_at IS 'When the condition behind the insight no longer holds';
@@ -0,0 +1,20 @@
+-- Migration: Per-transaction anomaly scoring
+-- Description: Robust z-score and review status for rows in spending_anomalies,
+--              which are now written when an expense transaction is created
+
+ALTER TABLE spending_anomalies
+ADD COLUMN IF NOT EXISTS robust_z_score DECIMAL(8,2),
+ADD COLUMN IF NOT EXISTS sample_size INTEGER,
+ADD COLUMN IF NOT EXISTS review_status VARCHAR(20) NOT NULL DEFAULT 'pending'
+    CHECK (review_status IN ('pending', 'confirmed', 'dismissed')),
+ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP WITH TIME ZONE;
+
+-- Outliers can be many times the median, DECIMAL(5,2) overflows above 999.99%
+ALTER TABLE spending_anomalies ALTER COLUMN deviation_percentage TYPE DECIMAL(12,2);
+
+CREATE INDEX IF NOT EXISTS idx_spending_anomalies_user_review
+    ON spending_anomalies(user_id, review_status, detection_date DESC);
+
+COMMENT ON COLUMN spending_anomalies.robust_z_score IS '0.6745 * (amount - median) / MAD over the comparison group';
+COMMENT ON COLUMN spending_anomalies.sample_size IS 'Number of past transactions in the comparison group';
+COMMENT ON COLUMN spending_anomalies.review_status IS 'pending, confirmed (really unusual) or dismissed (expected)';
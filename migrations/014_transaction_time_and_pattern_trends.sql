@@ -0,0 +1,24 @@
+-- Migration: Transaction time and spending pattern trends
+-- Description: Optional time-of-day on transactions (instead of guessing from created_at),
+--              plus the columns the pattern analysis job stores in spending_patterns
+
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS transaction_time TIME;
+
+-- Backfill only rows recorded on the day they happened, where created_at is a fair proxy for the spend time
+UPDATE transactions
+SET transaction_time = created_at::time
+WHERE transaction_time IS NULL
+  AND created_at::date = transaction_date;
+
+COMMENT ON COLUMN transactions.transaction_time IS 'Local time of day the transaction happened, NULL if unknown';
+
+ALTER TABLE spending_patterns
+ADD COLUMN IF NOT EXISTS day_of_week VARCHAR(10),
+ADD COLUMN IF NOT EXISTS time_of_day VARCHAR(20),
+ADD COLUMN IF NOT EXISTS frequency_count INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS change_percentage DECIMAL(8,2);
+
+CREATE INDEX IF NOT EXISTS idx_spending_patterns_user_type ON spending_patterns(user_id, pattern_type);
+
+COMMENT ON COLUMN spending_patterns.change_percentage IS 'Fitted change of weekly spend across the analysis window, in percent of the weekly mean';
@@ -0,0 +1,38 @@
+-- Migration: Insight feedback loop
+-- Description: Helpful / not helpful ratings on insights and per-user mutes for categories or insight types
+
+CREATE TABLE IF NOT EXISTS insight_feedback (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- Keep the rating when the insight itself is purged; type is copied so aggregates still work
+    insight_id UUID REFERENCES insights(id) ON DELETE SET NULL,
+    insight_type VARCHAR(50) NOT NULL,
+    rating VARCHAR(20) NOT NULL CHECK (rating IN ('helpful', 'not_helpful')),
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    UNIQUE(user_id, insight_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_insight_feedback_user_type ON insight_feedback(user_id, insight_type, created_at);
+
+CREATE TABLE IF NOT EXISTS insight_mutes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('category', 'insight_type')),
+    category_id UUID REFERENCES categories(id) ON DELETE CASCADE,
+    insight_type VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CHECK (
+        (scope = 'category' AND category_id IS NOT NULL AND insight_type IS NULL) OR
+        (scope = 'insight_type' AND insight_type IS NOT NULL AND category_id IS NULL)
+    )
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_insight_mutes_category ON insight_mutes(user_id, category_id) WHERE scope = 'category';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_insight_mutes_type ON insight_mutes(user_id, insight_type) WHERE scope = 'insight_type';
+
+COMMENT ON TABLE insight_feedback IS 'User ratings used to throttle insight types that are rarely helpful';
+COMMENT ON TABLE insight_mutes IS 'Categories or insight types a user no longer wants insights about';
@@ -0,0 +1,11 @@
+-- Migration: Localized insight messages
+-- Description: Per-user locale preference and message key / params on insights so they can be re-rendered in another language
+
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS locale VARCHAR(10) NOT NULL DEFAULT 'th';
+
+ALTER TABLE insights
+ADD COLUMN IF NOT EXISTS message_key VARCHAR(100),
+ADD COLUMN IF NOT EXISTS message_params JSONB,
+-- Locale that title / content were rendered in
+ADD COLUMN IF NOT EXISTS locale VARCHAR(10);
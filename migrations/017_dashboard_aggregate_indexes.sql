@@ -0,0 +1,11 @@
+-- Migration: Dashboard aggregate indexes
+-- Description: Covering indexes for the SQL-side dashboard / analytics aggregates so they stay index-only for users with large histories
+
+-- Category totals and time series filter by user + type + date range and read category_id / amount
+CREATE INDEX IF NOT EXISTS idx_transactions_user_type_date
+ON transactions(user_id, type, transaction_date)
+INCLUDE (category_id, account_id, amount);
+
+-- Recent transactions order by transaction_date DESC, created_at DESC
+CREATE INDEX IF NOT EXISTS idx_transactions_user_recent
+ON transactions(user_id, transaction_date DESC, created_at DESC);
@@ -0,0 +1,25 @@
+-- Migration: Daily category totals rollup
+-- Description: Per user / account / category / type / day totals maintained by the transaction repository so dashboard, budget and analytics queries do not scan transactions
+
+CREATE TABLE IF NOT EXISTS daily_category_totals (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    type transaction_type NOT NULL,
+    day DATE NOT NULL,
+    total NUMERIC NOT NULL DEFAULT 0,
+    transaction_count INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, account_id, category_id, type, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_daily_category_totals_user_type_day
+ON daily_category_totals(user_id, type, day)
+INCLUDE (category_id, account_id, total, transaction_count);
+
+-- Backfill from existing transactions (same query as `go run ./cmd/rebuild-daily-totals`)
+INSERT INTO daily_category_totals (user_id, account_id, category_id, type, day, total, transaction_count)
+SELECT user_id, account_id, category_id, type, transaction_date, SUM(amount), COUNT(*)
+FROM transactions
+GROUP BY user_id, account_id, category_id, type, transaction_date
+ON CONFLICT (user_id, account_id, category_id, type, day) DO NOTHING;
@@ -0,0 +1,17 @@
+-- Migration: Net worth snapshots
+-- Description: Loan account type and per-user net worth snapshots (assets minus credit / loan liabilities) recorded by a background job
+
+ALTER TYPE account_type ADD VALUE IF NOT EXISTS 'loan';
+
+CREATE TABLE IF NOT EXISTS net_worth_snapshots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    snapshot_date DATE NOT NULL,
+    total_assets NUMERIC NOT NULL,
+    total_liabilities NUMERIC NOT NULL,
+    net_worth NUMERIC NOT NULL,
+    -- Balance per account type: [{"type": "bank", "is_liability": false, "balance": "1000.00", "account_count": 2}, ...]
+    breakdown JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, snapshot_date)
+);
@@ -0,0 +1,58 @@
+-- Migration: Category hierarchy
+-- Description: Optional parent category (e.g. Food -> Coffee, Groceries, Restaurants) so spending and budgets can roll up
+-- from sub-categories, plus default sub-categories under the system categories
+
+ALTER TABLE categories
+ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES categories(id) ON DELETE SET NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'check_category_parent_not_self') THEN
+        ALTER TABLE categories
+        ADD CONSTRAINT check_category_parent_not_self
+            CHECK (parent_id IS NULL OR parent_id <> id);
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories(parent_id) WHERE parent_id IS NOT NULL;
+
+-- Default sub-categories, attached to the system category with the same name and type
+INSERT INTO categories (id, user_id, parent_id, name, type, icon_name, color_hex, created_at, updated_at)
+SELECT gen_random_uuid(), NULL, parent.id, child.name, parent.type, child.icon_name, parent.color_hex, NOW(), NOW()
+FROM (VALUES
+    ('อาหาร', 'expense', 'กาแฟ/เครื่องดื่ม', '☕'),
+    ('อาหาร', 'expense', 'ซื้อของเข้าบ้าน', '🛒'),
+    ('อาหาร', 'expense', 'ร้านอาหาร', '🍜'),
+    ('เดินทาง', 'expense', 'น้ำมัน', '⛽'),
+    ('เดินทาง', 'expense', 'ขนส่งสาธารณะ', '🚇'),
+    ('เดินทาง', 'expense', 'แท็กซี่/เรียกรถ', '🚕'),
+    ('ช้อปปิ้ง', 'expense', 'เสื้อผ้า', '👕'),
+    ('ช้อปปิ้ง', 'expense', 'อุปกรณ์อิเล็กทรอนิกส์', '💻'),
+    ('ช้อปปิ้ง', 'expense', 'ของใช้ในบ้าน', '🏠'),
+    ('บันเทิง', 'expense', 'ภาพยนตร์/คอนเสิร์ต', '🎟️'),
+    ('บันเทิง', 'expense', 'สตรีมมิ่ง', '📺'),
+    ('บันเทิง', 'expense', 'ท่องเที่ยว', '✈️'),
+    ('สุขภาพ', 'expense', 'ค่ารักษาพยาบาล', '🩺'),
+    ('สุขภาพ', 'expense', 'ยา', '💊'),
+    ('สุขภาพ', 'expense', 'ฟิตเนส', '🏋️'),
+    ('การศึกษา', 'expense', 'ค่าเล่าเรียน', '🎓'),
+    ('การศึกษา', 'expense', 'หนังสือ', '📖'),
+    ('การศึกษา', 'expense', 'คอร์สออนไลน์', '🧑‍💻'),
+    ('บิล/ค่าใช้จ่าย', 'expense', 'ค่าไฟ', '🔌'),
+    ('บิล/ค่าใช้จ่าย', 'expense', 'ค่าน้ำ', '🚰'),
+    ('บิล/ค่าใช้จ่าย', 'expense', 'อินเทอร์เน็ต/โทรศัพท์', '📶'),
+    ('บิล/ค่าใช้จ่าย', 'expense', 'ค่าเช่า', '🏘️'),
+    ('ลงทุน', 'income', 'เงินปันผล', '💹'),
+    ('ลงทุน', 'income', 'ดอกเบี้ย', '🏦')
+) AS child(parent_name, parent_type, name, icon_name)
+INNER JOIN categories parent
+    ON parent.user_id IS NULL
+    AND parent.parent_id IS NULL
+    AND parent.name = child.parent_name
+    AND parent.type::text = child.parent_type
+WHERE NOT EXISTS (
+    SELECT 1 FROM categories existing
+    WHERE existing.user_id IS NULL
+    AND existing.parent_id = parent.id
+    AND existing.name = child.name
+);
@@ -0,0 +1,17 @@
+-- Migration: Per-user category overrides
+-- Description: Lets a user rename, recolor, change the icon of or hide a system category without duplicating it
+
+CREATE TABLE IF NOT EXISTS category_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    -- NULL keeps the system category's value
+    name VARCHAR,
+    icon_name VARCHAR,
+    color_hex VARCHAR,
+    is_hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, category_id),
+    CONSTRAINT check_category_override_name_not_blank CHECK (name IS NULL OR btrim(name) <> '')
+);
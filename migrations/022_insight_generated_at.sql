@@ -0,0 +1,18 @@
+-- Migration: Insight generated_at
+-- Description: Track when a generator last raised an insight. Upserts keep created_at of the original row
+-- and updated_at also changes on read/dismiss/resolve, so neither can drive the low-rated cooldown
+
+ALTER TABLE insights
+ADD COLUMN IF NOT EXISTS generated_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE insights SET generated_at = created_at WHERE generated_at IS NULL;
+
+ALTER TABLE insights
+ALTER COLUMN generated_at SET DEFAULT NOW(),
+ALTER COLUMN generated_at SET NOT NULL;
+
+-- Cooldown lookup: latest generation per user and type
+CREATE INDEX IF NOT EXISTS idx_insights_user_type_generated
+    ON insights(user_id, type, generated_at DESC);
+
+COMMENT ON COLUMN insights.generated_at IS 'Last time a generator created or re-raised this insight (bumped on every upsert)';
@@ -29,6 +29,17 @@ func ParseDateTime(datetimeStr string) (time.Time, error) {
 	return time.Parse(time.RFC3339, datetimeStr)
 }
 
+// ParseClockTime parses a time of day ("HH:MM" or "HH:MM:SS") and normalizes it to "HH:MM"
+func ParseClockTime(clockStr string) (string, error) {
+	for _, format := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(format, clockStr); err == nil {
+			return t.Format("15:04"), nil
+		}
+	}
+
+	return "", errors.New("invalid time format")
+}
+
 // FormatDate formats time to date string
 func FormatDate(t time.Time) string {
 	return t.Format("2006-01-02")
@@ -49,6 +60,13 @@ func EndOfMonth(t time.Time) time.Time {
 	return StartOfMonth(t).AddDate(0, 1, 0).Add(-time.Second)
 }
 
+// DaysBetween returns the number of calendar days from from to to, ignoring time of day and time zone offset
+func DaysBetween(from, to time.Time) int {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDate.Sub(fromDate).Hours() / 24)
+}
+
 // GetCurrentMonth returns start and end of current month
 func GetCurrentMonth() (time.Time, time.Time) {
 	now := time.Now()
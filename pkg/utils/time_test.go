@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDaysBetween(t *testing.T) {
+	bangkok := time.FixedZone("ICT", 7*60*60)
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		from time.Time
+		to   time.Time
+		want int
+	}{
+		{name: "same day", from: time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC), to: time.Date(2026, time.March, 1, 23, 59, 0, 0, time.UTC), want: 0},
+		{name: "next day less than 24 hours later", from: time.Date(2026, time.March, 1, 22, 0, 0, 0, time.UTC), to: time.Date(2026, time.March, 2, 1, 0, 0, 0, time.UTC), want: 1},
+		{name: "backwards", from: time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC), to: time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC), want: -3},
+		{name: "across leap day", from: time.Date(2028, time.February, 28, 0, 0, 0, 0, time.UTC), to: time.Date(2028, time.March, 1, 0, 0, 0, 0, time.UTC), want: 2},
+		// เที่ยงคืนเวลาไทยคือ 17:00 UTC ของวันก่อนหน้า แต่ต้องนับตามวันที่ในปฏิทิน
+		{name: "local midnight to utc date", from: time.Date(2026, time.October, 18, 0, 0, 0, 0, bangkok), to: time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC), want: 2},
+		{name: "utc date behind local offset", from: time.Date(2026, time.October, 18, 0, 0, 0, 0, newYork), to: time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC), want: 2},
+		// วันที่เปลี่ยนเป็นเวลาออมแสงมีแค่ 23 ชั่วโมง
+		{name: "across daylight saving change", from: time.Date(2026, time.March, 7, 0, 0, 0, 0, newYork), to: time.Date(2026, time.March, 9, 0, 0, 0, 0, newYork), want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DaysBetween(tt.from, tt.to); got != tt.want {
+				t.Fatalf("DaysBetween(%v, %v) = %d, want %d", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}